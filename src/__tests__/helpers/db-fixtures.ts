@@ -0,0 +1,139 @@
+import { PrimordynDB } from '../../database/index.js';
+import { mkdirSync, rmSync, existsSync } from 'fs';
+import { join } from 'path';
+
+// Rows for tests that need an index without running the extractors. Defaults
+// are the minimum the schema requires; pass only what the test looks at.
+
+export interface FileFixture {
+  content?: string;
+  language?: string | null;
+  hash?: string;
+  metadata?: Record<string, unknown>;
+  lastModified?: Date;
+}
+
+export interface SymbolFixture {
+  type?: string;
+  lines?: [number, number];
+  signature?: string | null;
+  documentation?: string | null;
+}
+
+export interface CallFixture {
+  callerSymbolId?: number | null;
+  callerFileId: number;
+  calleeName: string;
+  calleeSymbolId?: number | null;
+  calleeFileId?: number | null;
+  callType?: string;
+  line?: number;
+}
+
+export interface RelationFixture {
+  fileId: number;
+  symbolId?: number | null;
+  typeName: string;
+  relatedName: string;
+  kind: string;
+  line: number;
+  source?: 'declared' | 'structural';
+}
+
+export interface ReferenceFixture {
+  fileId: number;
+  symbolId?: number | null;
+  name: string;
+  kind: string;
+  line: number;
+}
+
+export interface OccurrenceFixture {
+  fileId: number;
+  symbolId?: number | null;
+  name: string;
+  role: string;
+  line: number;
+  column: number;
+}
+
+/** A fresh index in `dir`, removing what an earlier run left behind. */
+export function createTestDb(dir: string): PrimordynDB {
+  removeTestDir(dir);
+  mkdirSync(dir, { recursive: true });
+  return new PrimordynDB(dir);
+}
+
+export function removeTestDir(dir: string): void {
+  if (existsSync(dir)) {
+    rmSync(dir, { recursive: true, force: true });
+  }
+}
+
+export function addFile(db: PrimordynDB, relativePath: string, file: FileFixture = {}): number {
+  const content = file.content ?? '';
+  return db.getDatabase().prepare(`
+    INSERT INTO files (path, relative_path, content, hash, size, language, last_modified, metadata)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+  `).run(
+    join(db.getProjectRoot(), relativePath),
+    relativePath,
+    content,
+    file.hash ?? `hash-${relativePath}`,
+    content.length,
+    file.language === undefined ? 'typescript' : file.language,
+    (file.lastModified ?? new Date()).toISOString(),
+    file.metadata ? JSON.stringify(file.metadata) : null
+  ).lastInsertRowid as number;
+}
+
+export function addSymbol(db: PrimordynDB, fileId: number, name: string, symbol: SymbolFixture = {}): number {
+  const [lineStart, lineEnd] = symbol.lines ?? [1, 1];
+  return db.getDatabase().prepare(`
+    INSERT INTO symbols (file_id, name, type, line_start, line_end, signature, documentation) VALUES (?, ?, ?, ?, ?, ?, ?)
+  `).run(fileId, name, symbol.type ?? 'function', lineStart, lineEnd, symbol.signature ?? null, symbol.documentation ?? null)
+    .lastInsertRowid as number;
+}
+
+export function addCall(db: PrimordynDB, call: CallFixture): number {
+  return db.getDatabase().prepare(`
+    INSERT INTO call_graph (caller_symbol_id, caller_file_id, callee_name, callee_symbol_id, callee_file_id, call_type, line_number)
+    VALUES (?, ?, ?, ?, ?, ?, ?)
+  `).run(
+    call.callerSymbolId ?? null,
+    call.callerFileId,
+    call.calleeName,
+    call.calleeSymbolId ?? null,
+    call.calleeFileId ?? null,
+    call.callType ?? 'function',
+    call.line ?? 1
+  ).lastInsertRowid as number;
+}
+
+export function addRelation(db: PrimordynDB, relation: RelationFixture): number {
+  return db.getDatabase().prepare(`
+    INSERT INTO type_relations (file_id, symbol_id, type_name, related_name, kind, line_number, source) VALUES (?, ?, ?, ?, ?, ?, ?)
+  `).run(
+    relation.fileId,
+    relation.symbolId ?? null,
+    relation.typeName,
+    relation.relatedName,
+    relation.kind,
+    relation.line,
+    relation.source ?? 'declared'
+  ).lastInsertRowid as number;
+}
+
+export function addOccurrence(db: PrimordynDB, occurrence: OccurrenceFixture): number {
+  return db.getDatabase().prepare(`
+    INSERT INTO occurrences (file_id, symbol_id, name, role, line_number, column_number) VALUES (?, ?, ?, ?, ?, ?)
+  `).run(occurrence.fileId, occurrence.symbolId ?? null, occurrence.name, occurrence.role, occurrence.line, occurrence.column)
+    .lastInsertRowid as number;
+}
+
+export function addReference(db: PrimordynDB, reference: ReferenceFixture): number {
+  return db.getDatabase().prepare(`
+    INSERT INTO symbol_references (file_id, symbol_id, name, kind, line_number) VALUES (?, ?, ?, ?, ?)
+  `).run(reference.fileId, reference.symbolId ?? null, reference.name, reference.kind, reference.line)
+    .lastInsertRowid as number;
+}
\ No newline at end of file
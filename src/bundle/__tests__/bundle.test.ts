@@ -0,0 +1,59 @@
+import { PrimordynDB } from '../../database/index.js';
+import { IndexBundle } from '../index.js';
+import { removeTestDir, addFile, addSymbol } from '../../__tests__/helpers/db-fixtures.js';
+import { mkdirSync, writeFileSync } from 'fs';
+import { join } from 'path';
+
+describe('IndexBundle', () => {
+  const sourceDir = join(process.cwd(), '.test-bundle-source');
+  const targetDir = join(process.cwd(), '.test-bundle-target');
+  const bundlePath = join(process.cwd(), '.test-bundle.gz');
+
+  const insertFile = (db: PrimordynDB, relativePath: string) => {
+    const fileId = addFile(db, relativePath, { content: 'export function main() {}', hash: 'abc' });
+    addSymbol(db, fileId, 'main');
+  };
+
+  beforeEach(() => {
+    for (const dir of [sourceDir, targetDir]) {
+      removeTestDir(dir);
+      mkdirSync(dir, { recursive: true });
+    }
+  });
+
+  afterEach(() => {
+    for (const path of [sourceDir, targetDir, bundlePath]) {
+      removeTestDir(path);
+    }
+  });
+
+  test('should re-root files and prune those missing from the checkout', () => {
+    const source = new PrimordynDB(sourceDir);
+    insertFile(source, 'src/main.ts');
+    insertFile(source, 'src/gone.ts');
+    const manifest = new IndexBundle(source).export(bundlePath);
+    source.close();
+
+    expect(manifest.fileCount).toBe(2);
+    expect(IndexBundle.readManifest(bundlePath).schemaVersion).toBe(manifest.schemaVersion);
+
+    mkdirSync(join(targetDir, 'src'), { recursive: true });
+    writeFileSync(join(targetDir, 'src/main.ts'), 'export function main() {}');
+
+    const target = new PrimordynDB(targetDir);
+    const result = new IndexBundle(target).import(bundlePath);
+
+    expect(result.filesImported).toBe(1);
+    expect(result.filesPruned).toBe(1);
+
+    const files = target.getDatabase().prepare('SELECT path FROM files').all() as { path: string }[];
+    expect(files.map(f => f.path)).toEqual([join(targetDir, 'src/main.ts')]);
+    expect((target.getDatabase().prepare('SELECT COUNT(*) as count FROM symbols').get() as { count: number }).count).toBe(1);
+    target.close();
+  });
+
+  test('should reject files that are not bundles', () => {
+    writeFileSync(bundlePath, 'not a bundle');
+    expect(() => IndexBundle.readManifest(bundlePath)).toThrow('is not a primordyn bundle');
+  });
+});
\ No newline at end of file
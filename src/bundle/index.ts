@@ -0,0 +1,223 @@
+import Database from 'better-sqlite3';
+import { join } from 'path';
+import { tmpdir } from 'os';
+import { existsSync, mkdtempSync, readFileSync, writeFileSync, rmSync } from 'fs';
+import { gzipSync, gunzipSync } from 'zlib';
+import { PrimordynDB, SCHEMA_VERSION } from '../database/index.js';
+import { GitAnalyzer } from '../git/analyzer.js';
+import { SemanticIndex } from '../search/semantic-index.js';
+import { VERSION } from '../version.js';
+import type { BundleManifest, BundleImportResult } from '../types/index.js';
+
+const BUNDLE_MAGIC = Buffer.from('PRIMORDYN-BUNDLE\n');
+const BUNDLE_FORMAT_VERSION = 1;
+// Files carry their content, so copy them in pages rather than all at once
+const IMPORT_PAGE_SIZE = 500;
+
+// Tables carried in a bundle, in foreign key order. The result cache and
+// bookkeeping counters are machine-local and never exported.
+const BUNDLE_TABLES = ['files', 'symbols', 'call_graph', 'type_relations', 'symbol_references', 'occurrences', 'semantic_postings', 'embedding_cache', 'embedding_chunks'];
+
+export class BundleError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'BundleError';
+  }
+}
+
+/**
+ * Portable index bundles: a gzipped header + manifest + SQLite snapshot whose
+ * file paths are stored relative to the project root, so an index built in CI
+ * can be dropped into any checkout of the same repository.
+ */
+export class IndexBundle {
+  private db: PrimordynDB;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+  }
+
+  public export(outputPath: string): BundleManifest {
+    const tempDir = mkdtempSync(join(tmpdir(), 'primordyn-export-'));
+    const snapshotPath = join(tempDir, 'context.db');
+
+    try {
+      // VACUUM INTO reads a consistent snapshot, so exporting never blocks a running index
+      this.db.getDatabase().prepare('VACUUM INTO ?').run(snapshotPath);
+
+      const snapshot = new Database(snapshotPath);
+      let counts: { files: number; symbols: number };
+      try {
+        snapshot.exec(`
+          UPDATE files SET path = relative_path;
+          DELETE FROM context_cache;
+          DELETE FROM index_meta WHERE key != 'storage_mode';
+        `);
+        counts = snapshot.prepare(`
+          SELECT (SELECT COUNT(*) FROM files) as files, (SELECT COUNT(*) FROM symbols) as symbols
+        `).get() as { files: number; symbols: number };
+        snapshot.exec('VACUUM');
+      } finally {
+        snapshot.close();
+      }
+
+      const manifest: BundleManifest = {
+        formatVersion: BUNDLE_FORMAT_VERSION,
+        toolVersion: VERSION,
+        schemaVersion: this.db.getSchemaVersion(),
+        gitHead: new GitAnalyzer(this.db.getProjectRoot()).getHeadCommit(),
+        storageMode: this.db.getStorageMode(),
+        createdAt: new Date().toISOString(),
+        fileCount: counts.files,
+        symbolCount: counts.symbols
+      };
+
+      const header = Buffer.from(JSON.stringify(manifest));
+      const headerLength = Buffer.alloc(4);
+      headerLength.writeUInt32BE(header.length);
+
+      writeFileSync(outputPath, gzipSync(Buffer.concat([BUNDLE_MAGIC, headerLength, header, readFileSync(snapshotPath)])));
+      return manifest;
+    } finally {
+      rmSync(tempDir, { recursive: true, force: true });
+    }
+  }
+
+  public static readManifest(bundlePath: string): BundleManifest {
+    return IndexBundle.unpack(bundlePath).manifest;
+  }
+
+  /**
+   * Replace the local index with the bundle's contents, re-rooted at this
+   * project. Files missing from the local checkout are pruned; callers should
+   * follow up with an incremental index run to pick up files that differ.
+   */
+  public import(bundlePath: string): BundleImportResult {
+    const { manifest, snapshot } = IndexBundle.unpack(bundlePath);
+
+    if (manifest.schemaVersion !== SCHEMA_VERSION) {
+      throw new BundleError(
+        `Bundle schema version ${manifest.schemaVersion} does not match this version of primordyn (${SCHEMA_VERSION}); ` +
+        `rebuild the bundle with primordyn ${VERSION} or run 'primordyn index'`
+      );
+    }
+
+    const tempDir = mkdtempSync(join(tmpdir(), 'primordyn-import-'));
+    const snapshotPath = join(tempDir, 'context.db');
+    const database = this.db.getDatabase();
+    const projectRoot = this.db.getProjectRoot();
+
+    const lock = this.db.createIndexLock();
+    lock.acquire('import');
+
+    try {
+      writeFileSync(snapshotPath, snapshot);
+      database.prepare('ATTACH DATABASE ? AS bundle').run(snapshotPath);
+
+      try {
+        return database.transaction(() => {
+          database.prepare('DELETE FROM call_graph').run();
+          database.prepare('DELETE FROM type_relations').run();
+          database.prepare('DELETE FROM symbol_references').run();
+          database.prepare('DELETE FROM occurrences').run();
+          database.prepare('DELETE FROM symbols').run();
+          database.prepare('DELETE FROM files').run();
+          database.prepare('DELETE FROM embedding_cache').run();
+          this.db.setStorageMode(manifest.storageMode);
+
+          for (const table of BUNDLE_TABLES) {
+            const columns = this.getSharedColumns(table);
+            if (table === 'files') {
+              // Re-root paths at this checkout; everything else is copied verbatim
+              const insert = database.prepare(`INSERT INTO main.files (${columns.join(', ')}) VALUES (${columns.map(() => '?').join(', ')})`);
+              const selectPage = database.prepare(`SELECT ${columns.join(', ')} FROM bundle.files WHERE id > ? ORDER BY id LIMIT ${IMPORT_PAGE_SIZE}`);
+              let lastId = 0;
+              let page: Record<string, unknown>[];
+              do {
+                page = selectPage.all(lastId) as Record<string, unknown>[];
+                for (const row of page) {
+                  row.path = join(projectRoot, row.relative_path as string);
+                  insert.run(...columns.map(column => row[column]));
+                  lastId = row.id as number;
+                }
+              } while (page.length === IMPORT_PAGE_SIZE);
+            } else {
+              database.exec(`INSERT INTO main.${table} (${columns.join(', ')}) SELECT ${columns.join(', ')} FROM bundle.${table}`);
+            }
+          }
+
+          // Deleting a file cascades to its symbols and call edges
+          const filesImported = (database.prepare('SELECT COUNT(*) as count FROM files').get() as { count: number }).count;
+          const deleteFile = database.prepare('DELETE FROM files WHERE id = ?');
+          let filesPruned = 0;
+          for (const file of database.prepare('SELECT id, path FROM files').all() as { id: number; path: string }[]) {
+            if (!existsSync(file.path)) {
+              deleteFile.run(file.id);
+              filesPruned++;
+            }
+          }
+
+          if (manifest.storageMode !== 'full') {
+            // Contentless search data can't be copied; rebuild it from the
+            // stored or on-disk text (files that changed get it on re-index)
+            database.exec(`
+              INSERT INTO files_fts (rowid, relative_path, content, language)
+              SELECT id, relative_path, content, language FROM files_with_content
+            `);
+          }
+
+          // IDF and norms depend on which files survived pruning
+          new SemanticIndex(this.db).refresh();
+
+          this.db.bumpIndexGeneration();
+          return { manifest, filesImported: filesImported - filesPruned, filesPruned };
+        })();
+      } finally {
+        database.exec('DETACH DATABASE bundle');
+      }
+    } finally {
+      lock.release();
+      rmSync(tempDir, { recursive: true, force: true });
+    }
+  }
+
+  private getSharedColumns(table: string): string[] {
+    const database = this.db.getDatabase();
+    const local = new Set((database.prepare(`SELECT name FROM pragma_table_info('${table}', 'main')`).all() as { name: string }[]).map(c => c.name));
+    return (database.prepare(`SELECT name FROM pragma_table_info('${table}', 'bundle')`).all() as { name: string }[])
+      .map(c => c.name)
+      .filter(name => local.has(name));
+  }
+
+  private static unpack(bundlePath: string): { manifest: BundleManifest; snapshot: Buffer } {
+    if (!existsSync(bundlePath)) {
+      throw new BundleError(`Bundle not found: ${bundlePath}`);
+    }
+
+    let data: Buffer;
+    try {
+      data = gunzipSync(readFileSync(bundlePath));
+    } catch {
+      throw new BundleError(`${bundlePath} is not a primordyn bundle (not gzip compressed)`);
+    }
+
+    if (data.length < BUNDLE_MAGIC.length + 4 || !data.subarray(0, BUNDLE_MAGIC.length).equals(BUNDLE_MAGIC)) {
+      throw new BundleError(`${bundlePath} is not a primordyn bundle`);
+    }
+
+    const headerStart = BUNDLE_MAGIC.length + 4;
+    const headerEnd = headerStart + data.readUInt32BE(BUNDLE_MAGIC.length);
+    let manifest: BundleManifest;
+    try {
+      manifest = JSON.parse(data.subarray(headerStart, headerEnd).toString('utf-8')) as BundleManifest;
+    } catch {
+      throw new BundleError(`${bundlePath} has a corrupt manifest`);
+    }
+
+    if (manifest.formatVersion !== BUNDLE_FORMAT_VERSION) {
+      throw new BundleError(`Unsupported bundle format version ${manifest.formatVersion}`);
+    }
+
+    return { manifest, snapshot: data.subarray(headerEnd) };
+  }
+}
\ No newline at end of file
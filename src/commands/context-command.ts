@@ -0,0 +1,121 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { ContextRetriever } from '../retriever/index.js';
+import { TaskContextBuilder } from '../context/index.js';
+import { validateTokenLimit, validateFormat, validateLanguages, validateSearchTerm, ValidationError } from '../utils/validation.js';
+import type { ContextCommandOptions, ContextItem, TaskContext } from '../types/index.js';
+import chalk from 'chalk';
+
+export const contextCommand = new Command('context')
+  .description('Gather the code relevant to a task description, within a token budget')
+  .argument('<task>', 'What you are about to do, e.g. "add rate limiting to the login endpoint"')
+  .option('--tokens <max>', 'Maximum tokens in response (default: 8000)', '8000')
+  .option('--format <type>', 'Output format: ai, json, human (default: ai)', 'ai')
+  .option('--languages <langs>', 'Filter by languages: ts,js,py,go,etc')
+  .action(async (task: string, options: ContextCommandOptions) => {
+    try {
+      const validatedTask = validateSearchTerm(task);
+      const maxTokens = validateTokenLimit(options.tokens);
+      const format = validateFormat(options.format);
+      const fileTypes = options.languages ? validateLanguages(options.languages) : undefined;
+
+      const db = new PrimordynDB();
+      const retriever = new ContextRetriever(db);
+      const context = await new TaskContextBuilder(db, retriever).build(validatedTask, { maxTokens, fileTypes });
+      db.close();
+
+      switch (format) {
+        case 'json':
+          console.log(JSON.stringify(context, null, 2));
+          break;
+        case 'ai':
+          outputAIFormat(context);
+          break;
+        default:
+          outputHumanFormat(context);
+      }
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Context failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+function describe(item: ContextItem): string {
+  const shortened = item.representation ? ` [${item.representation} only]` : '';
+  if (item.kind === 'file') {
+    return item.filePath + shortened;
+  }
+  return `${item.name} (${item.type}) - ${item.filePath}:${item.lineStart}-${item.lineEnd}${shortened}`;
+}
+
+function outputAIFormat(context: TaskContext) {
+  console.log(`# Context for task: ${context.task}\n`);
+
+  const found = [
+    context.identifiers.length ? `Identifiers: ${context.identifiers.map(i => `\`${i}\``).join(', ')}` : '',
+    context.paths.length ? `Paths: ${context.paths.join(', ')}` : '',
+    context.keywords.length ? `Keywords: ${context.keywords.join(', ')}` : ''
+  ].filter(Boolean);
+  if (found.length > 0) {
+    console.log(found.join(' · ') + '\n');
+  }
+
+  if (context.items.length === 0) {
+    console.log('No relevant code found. Try naming a symbol or file, or run `primordyn index` first.');
+    return;
+  }
+
+  context.items.forEach((item, index) => {
+    console.log(`## ${index + 1}. ${describe(item)}`);
+    console.log(`Why: ${item.reasons.join('; ')}`);
+    if (item.content) {
+      console.log(`\`\`\`${item.language || ''}`);
+      console.log(item.content);
+      console.log('```');
+    }
+    console.log();
+  });
+
+  console.log(`---\n${context.items.length} items, ${context.totalTokens}/${context.maxTokens} tokens` +
+    (context.omitted > 0 ? `; ${context.omitted} more candidates didn't fit` : ''));
+  const shortened = context.degraded.filter(item => item.to !== 'omitted');
+  if (shortened.length > 0) {
+    console.log(`Shortened to fit: ${shortened.map(item => `${item.name} (${item.from} → ${item.to})`).join(', ')}`);
+  }
+}
+
+function outputHumanFormat(context: TaskContext) {
+  console.log(chalk.bold(`\n🎯 Context for: ${context.task}\n`));
+
+  if (context.identifiers.length > 0) {
+    console.log(chalk.gray(`Identifiers: ${context.identifiers.join(', ')}`));
+  }
+  if (context.paths.length > 0) {
+    console.log(chalk.gray(`Paths:       ${context.paths.join(', ')}`));
+  }
+  if (context.keywords.length > 0) {
+    console.log(chalk.gray(`Keywords:    ${context.keywords.join(', ')}`));
+  }
+  console.log();
+
+  if (context.items.length === 0) {
+    console.log(chalk.yellow('No relevant code found.'));
+    return;
+  }
+
+  for (const item of context.items) {
+    console.log(`${chalk.green(item.score.toFixed(2))}  ${chalk.cyan(describe(item))} ${chalk.gray(`(${item.tokens} tokens)`)}`);
+    for (const reason of item.reasons) {
+      console.log(chalk.gray(`      ${reason}`));
+    }
+  }
+
+  console.log(chalk.gray(`\n${context.totalTokens}/${context.maxTokens} tokens used` +
+    (context.omitted > 0 ? `, ${context.omitted} candidates omitted` : '')));
+  console.log(chalk.gray('Score = relevance to the task, call-graph centrality and recency of changes'));
+}
\ No newline at end of file
@@ -0,0 +1,142 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { DocCoverageAnalyzer, meetsMinimum } from '../docs/coverage.js';
+import { validateLanguages, validatePercentage, validatePositiveInteger, ValidationError } from '../utils/validation.js';
+import type { CoverageBucket, DocCoverageOptions, DocCoverageReport } from '../types/index.js';
+import chalk from 'chalk';
+
+const FORMATS = ['human', 'json', 'markdown'];
+const WORST_FILES = 10;
+
+export const docCoverageCommand = new Command('doc-coverage')
+  .description('Report how much of the public API has doc comments, by file, directory and language')
+  .option('--format <type>', 'Output format: human, json, markdown (default: human)', 'human')
+  .option('--min <percent>', 'Exit with code 1 when overall coverage is below this percentage')
+  .option('--languages <langs>', 'Filter by languages: ts,js,py,go,etc')
+  .option('--limit <n>', 'Undocumented symbols to list (default: 20)', '20')
+  .action(async (options: DocCoverageOptions) => {
+    try {
+      if (!FORMATS.includes(options.format)) {
+        throw new ValidationError(`Invalid format: "${options.format}". Must be one of: ${FORMATS.join(', ')}`);
+      }
+      const min = options.min !== undefined ? validatePercentage(options.min, '--min') : undefined;
+      const limit = validatePositiveInteger(options.limit, '--limit');
+      const fileTypes = options.languages ? validateLanguages(options.languages) : undefined;
+
+      const db = new PrimordynDB();
+      const report = new DocCoverageAnalyzer(db).analyze({ fileTypes, limit });
+      db.close();
+
+      const passed = min === undefined || meetsMinimum(report, min);
+      switch (options.format) {
+        case 'json':
+          console.log(JSON.stringify(min === undefined ? report : { ...report, min, passed }, null, 2));
+          break;
+        case 'markdown':
+          console.log(formatMarkdown(report, min));
+          break;
+        default:
+          outputHumanFormat(report, min);
+      }
+
+      if (!passed) {
+        process.exit(1);
+      }
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Doc coverage failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+function leastDocumented(report: DocCoverageReport): CoverageBucket[] {
+  return report.files
+    .filter(file => file.coverage < 100)
+    .sort((a, b) => a.coverage - b.coverage || b.total - a.total)
+    .slice(0, WORST_FILES);
+}
+
+function ratio(bucket: CoverageBucket): string {
+  return `${bucket.coverage.toFixed(1)}% (${bucket.documented}/${bucket.total})`;
+}
+
+function outputHumanFormat(report: DocCoverageReport, min?: number) {
+  if (report.total === 0) {
+    console.log(chalk.yellow('No public symbols found. Run `primordyn index` first, or check --languages.'));
+    return;
+  }
+
+  const color = report.coverage >= 80 ? chalk.green : report.coverage >= 50 ? chalk.yellow : chalk.red;
+  console.log(chalk.bold('\n📚 Documentation coverage: ') + color(`${report.coverage.toFixed(1)}%`) +
+    chalk.gray(` (${report.documented} of ${report.total} public symbols documented)\n`));
+
+  console.log(chalk.blue('By language:'));
+  for (const language of report.languages) {
+    console.log(`  ${language.name.padEnd(14)} ${ratio(language)}`);
+  }
+
+  if (report.directories.length > 0) {
+    console.log(chalk.blue('\nBy directory:'));
+    for (const directory of report.directories) {
+      const depth = directory.name.split('/').length - 1;
+      console.log(`  ${`${'  '.repeat(depth)}${directory.name.split('/').pop()}/`.padEnd(30)} ${ratio(directory)}`);
+    }
+  }
+
+  const worst = leastDocumented(report);
+  if (worst.length > 0) {
+    console.log(chalk.blue('\nLeast documented files:'));
+    for (const file of worst) {
+      console.log(`  ${chalk.cyan(file.name)} ${chalk.gray(ratio(file))}`);
+    }
+  }
+
+  if (report.undocumented.length > 0) {
+    console.log(chalk.blue('\nLargest undocumented public symbols:'));
+    for (const symbol of report.undocumented) {
+      console.log(`  ${symbol.name} ${chalk.gray(`(${symbol.type}, ${symbol.lines} lines)`)} ${chalk.cyan(`${symbol.filePath}:${symbol.line}`)}`);
+    }
+  }
+
+  if (min !== undefined) {
+    console.log(report.coverage >= min
+      ? chalk.green(`\n✅ Coverage meets the ${min}% minimum`)
+      : chalk.red(`\n❌ Coverage is below the ${min}% minimum`));
+  }
+}
+
+function formatMarkdown(report: DocCoverageReport, min?: number): string {
+  const table = (heading: string, buckets: CoverageBucket[]) => [
+    `| ${heading} | Coverage | Documented | Public |`,
+    '| --- | ---: | ---: | ---: |',
+    ...buckets.map(bucket => `| ${bucket.name} | ${bucket.coverage.toFixed(1)}% | ${bucket.documented} | ${bucket.total} |`)
+  ].join('\n');
+
+  const sections = [
+    '# Documentation coverage',
+    `**${report.coverage.toFixed(1)}%** of public symbols are documented (${report.documented} of ${report.total}).` +
+      (min !== undefined ? ` Minimum: ${min}% ${report.coverage >= min ? '✅' : '❌'}` : '')
+  ];
+  if (report.languages.length > 0) {
+    sections.push('## By language', table('Language', report.languages));
+  }
+  if (report.directories.length > 0) {
+    sections.push('## By directory', table('Directory', report.directories));
+  }
+  const worst = leastDocumented(report);
+  if (worst.length > 0) {
+    sections.push('## Least documented files', table('File', worst));
+  }
+  if (report.undocumented.length > 0) {
+    sections.push('## Largest undocumented public symbols', [
+      '| Symbol | Kind | Location | Lines |',
+      '| --- | --- | --- | ---: |',
+      ...report.undocumented.map(symbol => `| \`${symbol.name}\` | ${symbol.type} | ${symbol.filePath}:${symbol.line} | ${symbol.lines} |`)
+    ].join('\n'));
+  }
+  return sections.join('\n\n');
+}
\ No newline at end of file
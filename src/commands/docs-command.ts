@@ -0,0 +1,108 @@
+import { Command } from 'commander';
+import { existsSync, readFileSync, writeFileSync } from 'fs';
+import { resolve } from 'path';
+import { PrimordynDB } from '../database/index.js';
+import { ProjectContextGenerator, DEFAULT_CONTEXT_FILE } from '../docs/project-context.js';
+import { ApiReferenceGenerator } from '../docs/api-reference.js';
+import { validateLanguages, ValidationError } from '../utils/validation.js';
+import type { DocsApiOptions, DocsGenerateOptions } from '../types/index.js';
+import chalk from 'chalk';
+
+const generateCommand = new Command('generate')
+  .description('Write or refresh a project context file (AGENTS.md, CLAUDE.md) from the index')
+  .option('--output <file>', `File to write (default: ${DEFAULT_CONTEXT_FILE})`, DEFAULT_CONTEXT_FILE)
+  .option('--check', 'Exit non-zero if the file is missing or out of date instead of writing it')
+  .action(async (options: DocsGenerateOptions) => {
+    try {
+      const db = new PrimordynDB();
+      const dbInfo = await db.getDatabaseInfo();
+      if (dbInfo.fileCount === 0) {
+        console.error(chalk.red('❌ Nothing to document: the index is empty.'));
+        console.log(chalk.gray('Run "primordyn index" first.'));
+        db.close();
+        process.exit(1);
+      }
+
+      const path = resolve(options.output);
+      const existing = existsSync(path) ? readFileSync(path, 'utf8') : null;
+      const updated = new ProjectContextGenerator(db, process.cwd()).render(existing);
+      db.close();
+
+      if (options.check) {
+        if (updated !== existing) {
+          console.error(chalk.red(`❌ ${options.output} is ${existing === null ? 'missing' : 'out of date'}`));
+          console.log(chalk.gray(`Run "primordyn docs generate${options.output === DEFAULT_CONTEXT_FILE ? '' : ` --output ${options.output}`}" to refresh it.`));
+          process.exit(1);
+        }
+        console.log(chalk.green(`✅ ${options.output} is up to date`));
+        return;
+      }
+
+      if (updated === existing) {
+        console.log(chalk.gray(`${options.output} is already up to date`));
+        return;
+      }
+      writeFileSync(path, updated);
+      console.log(chalk.green(`✅ ${existing === null ? 'Created' : 'Updated'} ${options.output}`));
+      console.log(chalk.gray('Text outside the primordyn markers is yours; it is kept on every refresh.'));
+
+    } catch (error) {
+      console.error(chalk.red('❌ Docs generation failed:'), error instanceof Error ? error.message : error);
+      process.exit(1);
+    }
+  });
+
+const apiCommand = new Command('api')
+  .description('Write per-module API reference pages with call-graph cross-links')
+  .option('--out <dir>', 'Output directory (default: docs/api)', 'docs/api')
+  .option('--format <type>', 'Page format: md, html (default: md)', 'md')
+  .option('--source-url <url>', 'Base URL for source links, e.g. https://github.com/org/repo/blob/main')
+  .option('--languages <langs>', 'Only document these languages: ts,js,py,go,etc')
+  .option('--json', 'Output generation stats as JSON')
+  .action(async (options: DocsApiOptions) => {
+    try {
+      if (options.format !== 'md' && options.format !== 'html') {
+        throw new ValidationError(`Invalid format: "${options.format}". Must be one of: md, html`);
+      }
+      const fileTypes = options.languages ? validateLanguages(options.languages) : undefined;
+
+      const db = new PrimordynDB();
+      const stats = new ApiReferenceGenerator(db, {
+        outDir: resolve(options.out),
+        format: options.format,
+        sourceUrl: options.sourceUrl,
+        fileTypes
+      }).generate();
+      db.close();
+
+      if (options.json) {
+        console.log(JSON.stringify({ ...stats, outDir: options.out }, null, 2));
+        return;
+      }
+
+      if (stats.pages === 0) {
+        console.log(chalk.yellow('No modules with symbols in the index. Run "primordyn index" first.'));
+        return;
+      }
+      console.log(chalk.green(`✅ API reference in ${options.out}`));
+      console.log(`  • Pages written: ${chalk.yellow(stats.written)}`);
+      console.log(`  • Unchanged: ${chalk.gray(stats.unchanged)}`);
+      if (stats.removed > 0) {
+        console.log(`  • Removed: ${chalk.yellow(stats.removed)}`);
+      }
+      console.log(chalk.gray(`Start at ${options.out}/index.${options.format}`));
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ API docs failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+export const docsCommand = new Command('docs')
+  .description('Generate documentation from the index')
+  .addCommand(generateCommand)
+  .addCommand(apiCommand);
\ No newline at end of file
@@ -0,0 +1,42 @@
+import { Command } from 'commander';
+import { statSync } from 'fs';
+import { PrimordynDB } from '../database/index.js';
+import { IndexBundle } from '../bundle/index.js';
+import chalk from 'chalk';
+
+export const exportCommand = new Command('export')
+  .description('Export the index as a portable bundle (e.g. built once in CI)')
+  .argument('<file>', 'Bundle file to write (e.g. primordyn-index.gz)')
+  .option('--json', 'Output the bundle manifest as JSON')
+  .action(async (file: string, options) => {
+    try {
+      const db = new PrimordynDB();
+      const dbInfo = await db.getDatabaseInfo();
+
+      if (dbInfo.fileCount === 0) {
+        console.error(chalk.red('❌ Nothing to export: the index is empty.'));
+        console.log(chalk.gray('Run "primordyn index" first.'));
+        db.close();
+        process.exit(1);
+      }
+
+      const manifest = new IndexBundle(db).export(file);
+      db.close();
+
+      if (options.json) {
+        console.log(JSON.stringify({ file, size: statSync(file).size, ...manifest }, null, 2));
+        return;
+      }
+
+      console.log(chalk.green('✅ Index exported:'), chalk.cyan(file));
+      console.log(`  • Files: ${chalk.yellow(manifest.fileCount.toLocaleString())}`);
+      console.log(`  • Symbols: ${chalk.yellow(manifest.symbolCount.toLocaleString())}`);
+      console.log(`  • Git HEAD: ${manifest.gitHead ? chalk.cyan(manifest.gitHead.substring(0, 12)) : chalk.gray('not a git repository')}`);
+      console.log(`  • Bundle size: ${chalk.yellow((statSync(file).size / 1024).toFixed(1))} KB`);
+      console.log('\n' + chalk.green('💡 Next:'), chalk.cyan(`primordyn import ${file}`), chalk.gray('in another checkout'));
+
+    } catch (error) {
+      console.error(chalk.red('❌ Export failed:'), error instanceof Error ? error.message : error);
+      process.exit(1);
+    }
+  });
\ No newline at end of file
@@ -0,0 +1,57 @@
+import { Command } from 'commander';
+import { writeFileSync } from 'fs';
+import { join } from 'path';
+import { PrimordynDB } from '../database/index.js';
+import { TagExporter } from '../tags/index.js';
+import { encodeScip } from '../scip/encode.js';
+import { ValidationError } from '../utils/validation.js';
+import type { ExportTagsCommandOptions } from '../types/index.js';
+import chalk from 'chalk';
+
+const FORMATS = ['ctags', 'scip'];
+
+export const exportTagsCommand = new Command('export-tags')
+  .description('Write a ctags file or SCIP index from the index for editors and code navigation tools')
+  .option('--format <type>', 'Output format: ctags, scip (default: ctags)', 'ctags')
+  .option('--output <file>', 'File to write (default: tags or index.scip in the project root)')
+  .action(async (options: ExportTagsCommandOptions) => {
+    try {
+      if (!FORMATS.includes(options.format)) {
+        throw new ValidationError(`Invalid format: "${options.format}". Must be one of: ${FORMATS.join(', ')}`);
+      }
+
+      const db = new PrimordynDB();
+      const dbInfo = await db.getDatabaseInfo();
+      if (dbInfo.fileCount === 0) {
+        console.error(chalk.red('❌ Nothing to export: the index is empty.'));
+        console.log(chalk.gray('Run "primordyn index" first.'));
+        db.close();
+        process.exit(1);
+      }
+
+      const output = options.output ?? join(db.getProjectRoot(), options.format === 'scip' ? 'index.scip' : 'tags');
+      const exporter = new TagExporter(db);
+      let summary: string;
+      if (options.format === 'scip') {
+        const index = exporter.toScip();
+        writeFileSync(output, encodeScip(index));
+        const occurrences = index.documents.reduce((sum, document) => sum + document.occurrences.length, 0);
+        summary = `${index.documents.length.toLocaleString()} documents, ${occurrences.toLocaleString()} occurrences`;
+      } else {
+        const { content, count } = exporter.toCtags();
+        writeFileSync(output, content);
+        summary = `${count.toLocaleString()} tags`;
+      }
+      db.close();
+
+      console.log(chalk.green('✅ Tags exported:'), chalk.cyan(output), chalk.gray(`(${summary})`));
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Tag export failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
\ No newline at end of file
@@ -0,0 +1,119 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { TypeHierarchyBuilder } from '../hierarchy/index.js';
+import { validateFormat, validatePositiveInteger, validateSearchTerm, ValidationError } from '../utils/validation.js';
+import type { HierarchyCommandOptions, HierarchyNode, Implementor, TypeHierarchy } from '../types/index.js';
+import chalk from 'chalk';
+
+export const hierarchyCommand = new Command('hierarchy')
+  .description('Show the supertypes, subtypes and implementors of a class, interface, struct or trait')
+  .argument('<type>', 'Type name, e.g. Repository or io.Reader')
+  .option('--format <type>', 'Output format: ai, json, human (default: ai)', 'ai')
+  .option('--depth <n>', 'Levels to follow up and down (default: 10)', '10')
+  .action(async (typeName: string, options: HierarchyCommandOptions) => {
+    try {
+      const validatedName = validateSearchTerm(typeName);
+      const format = validateFormat(options.format);
+      const maxDepth = validatePositiveInteger(options.depth, '--depth');
+
+      const db = new PrimordynDB();
+      const hierarchy = new TypeHierarchyBuilder(db).build(validatedName, { maxDepth });
+      db.close();
+
+      if (!hierarchy) {
+        console.error(chalk.yellow(`No type named "${validatedName}" in the index`));
+        process.exit(1);
+      }
+
+      switch (format) {
+        case 'json':
+          console.log(JSON.stringify(hierarchy, null, 2));
+          break;
+        case 'ai':
+          outputAIFormat(hierarchy);
+          break;
+        default:
+          outputHumanFormat(hierarchy);
+      }
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Hierarchy failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+function location(node: { filePath?: string; line?: number }): string {
+  return node.filePath ? `${node.filePath}:${node.line}` : '(not indexed)';
+}
+
+function treeLines(nodes: HierarchyNode[], depth = 0): string[] {
+  return nodes.flatMap(node => [
+    `${'  '.repeat(depth)}- ${node.name} (${node.kind}) ${location(node)}${node.repeated ? ' (see above)' : ''}`,
+    ...treeLines(node.children, depth + 1)
+  ]);
+}
+
+function implementorLine(implementor: Implementor): string {
+  const via = implementor.via.length > 0 ? ` via ${implementor.via.join(' → ')}` : '';
+  return `- ${implementor.name} ${location(implementor)}${via}`;
+}
+
+function outputAIFormat(hierarchy: TypeHierarchy) {
+  console.log(`# Type hierarchy: ${hierarchy.type}\n`);
+  for (const definition of hierarchy.definitions) {
+    console.log(`Defined in ${definition.filePath}:${definition.line} (${definition.type})`);
+  }
+
+  const sections: [string, string[]][] = [
+    ['Supertypes', treeLines(hierarchy.supertypes)],
+    ['Subtypes', treeLines(hierarchy.subtypes)],
+    ['Implementors', hierarchy.implementors.map(implementorLine)]
+  ];
+  for (const [title, lines] of sections) {
+    console.log(`\n## ${title}`);
+    console.log(lines.length > 0 ? lines.join('\n') : 'None found.');
+  }
+}
+
+function outputHumanFormat(hierarchy: TypeHierarchy) {
+  console.log(chalk.bold(`\n🧬 ${hierarchy.type}`));
+  for (const definition of hierarchy.definitions) {
+    console.log(chalk.gray(`   ${definition.type} at ${definition.filePath}:${definition.line}`));
+  }
+
+  const printTree = (nodes: HierarchyNode[], prefix = '   ') => {
+    nodes.forEach((node, index) => {
+      const last = index === nodes.length - 1;
+      console.log(`${prefix}${last ? '└─' : '├─'} ${chalk.blue(node.name)} ${chalk.gray(`${node.kind} · ${location(node)}`)}${node.repeated ? chalk.gray(' ↺') : ''}`);
+      printTree(node.children, `${prefix}${last ? '   ' : '│  '}`);
+    });
+  };
+
+  console.log(chalk.green('\n⬆️  Supertypes:'));
+  if (hierarchy.supertypes.length > 0) {
+    printTree(hierarchy.supertypes);
+  } else {
+    console.log(chalk.gray('   none'));
+  }
+
+  console.log(chalk.green('\n⬇️  Subtypes:'));
+  if (hierarchy.subtypes.length > 0) {
+    printTree(hierarchy.subtypes);
+  } else {
+    console.log(chalk.gray('   none'));
+  }
+
+  console.log(chalk.green('\n🔌 Implementors:'));
+  if (hierarchy.implementors.length > 0) {
+    for (const implementor of hierarchy.implementors) {
+      const via = implementor.via.length > 0 ? chalk.gray(` via ${implementor.via.join(' → ')}`) : '';
+      console.log(`   ${chalk.blue(implementor.name)} ${chalk.gray(location(implementor))}${via}`);
+    }
+  } else {
+    console.log(chalk.gray('   none'));
+  }
+}
\ No newline at end of file
@@ -0,0 +1,103 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { ImplementationIndex } from '../hierarchy/implementations.js';
+import { validateFormat, validatePositiveInteger, validateSearchTerm, ValidationError } from '../utils/validation.js';
+import type { Implementation, ImplementationsCommandOptions, ImplementationsReport } from '../types/index.js';
+import chalk from 'chalk';
+
+export const implementationsCommand = new Command('implementations')
+  .description('List the concrete types implementing an interface or trait, including Go types that satisfy it structurally')
+  .argument('<interface>', 'Interface, trait or protocol name, e.g. Store or io.Writer')
+  .option('--format <type>', 'Output format: ai, json, human (default: ai)', 'ai')
+  .option('--depth <n>', 'Levels of sub-interfaces and subclasses to follow (default: 10)', '10')
+  .action(async (interfaceName: string, options: ImplementationsCommandOptions) => {
+    try {
+      const validatedName = validateSearchTerm(interfaceName);
+      const format = validateFormat(options.format);
+      const maxDepth = validatePositiveInteger(options.depth, '--depth');
+
+      const db = new PrimordynDB();
+      const report = new ImplementationIndex(db).find(validatedName, { maxDepth });
+      db.close();
+
+      if (!report) {
+        console.error(chalk.yellow(`No interface named "${validatedName}" in the index`));
+        process.exit(1);
+      }
+
+      switch (format) {
+        case 'json':
+          console.log(JSON.stringify(report, null, 2));
+          break;
+        case 'ai':
+          outputAIFormat(report);
+          break;
+        default:
+          outputHumanFormat(report);
+      }
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Implementations lookup failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+function describe(implementation: Implementation): string {
+  const notes = [
+    implementation.structural ? 'structural' : 'declared',
+    ...(implementation.via.length > 0 ? [`via ${implementation.via.join(' → ')}`] : [])
+  ];
+  return notes.join(', ');
+}
+
+function location(node: { filePath?: string; line?: number }): string {
+  return node.filePath ? `${node.filePath}:${node.line}` : '(not indexed)';
+}
+
+function outputAIFormat(report: ImplementationsReport) {
+  console.log(`# Implementations of ${report.interface}\n`);
+  for (const definition of report.definitions) {
+    console.log(`Defined in ${definition.filePath}:${definition.line} (${definition.type})`);
+  }
+  if (report.methods.length > 0) {
+    console.log(`Methods: ${report.methods.join(', ')}`);
+  }
+
+  if (report.implementations.length === 0) {
+    console.log('\nNo implementations found.');
+    return;
+  }
+  for (const implementation of report.implementations) {
+    console.log(`\n## ${implementation.name} (${describe(implementation)})`);
+    console.log(location(implementation));
+    for (const method of implementation.methods) {
+      console.log(`- ${method.name} ${method.filePath}:${method.line}`);
+    }
+  }
+}
+
+function outputHumanFormat(report: ImplementationsReport) {
+  console.log(chalk.bold(`\n🔌 ${report.interface}`));
+  for (const definition of report.definitions) {
+    console.log(chalk.gray(`   ${definition.type} at ${definition.filePath}:${definition.line}`));
+  }
+  if (report.methods.length > 0) {
+    console.log(chalk.gray(`   methods: ${report.methods.join(', ')}`));
+  }
+
+  if (report.implementations.length === 0) {
+    console.log(chalk.yellow('\nNo implementations found.'));
+    return;
+  }
+  console.log(chalk.green(`\n📦 Implementations (${report.implementations.length}):`));
+  for (const implementation of report.implementations) {
+    console.log(`   ${chalk.blue(implementation.name)} ${chalk.cyan(location(implementation))} ${chalk.gray(describe(implementation))}`);
+    for (const method of implementation.methods) {
+      console.log(chalk.gray(`      ${method.name} → ${method.filePath}:${method.line}`));
+    }
+  }
+}
\ No newline at end of file
@@ -0,0 +1,65 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { Indexer } from '../indexer/index.js';
+import { IndexBundle } from '../bundle/index.js';
+import { GitAnalyzer } from '../git/analyzer.js';
+import { VERSION } from '../version.js';
+import chalk from 'chalk';
+
+export const importCommand = new Command('import')
+  .description('Import an index bundle and re-index files that differ locally')
+  .argument('<file>', 'Bundle file produced by "primordyn export"')
+  .option('--no-update', 'Skip re-indexing files that changed since the bundle was built')
+  .option('--quiet', 'Minimal output')
+  .action(async (file: string, options) => {
+    try {
+      const db = new PrimordynDB();
+      const manifest = IndexBundle.readManifest(file);
+
+      if (!options.quiet) {
+        console.log(chalk.blue('📦 Importing index bundle:'), chalk.cyan(file));
+        if (manifest.toolVersion !== VERSION) {
+          console.log(chalk.yellow(`⚠️  Bundle was built with primordyn ${manifest.toolVersion} (this is ${VERSION})`));
+        }
+
+        const localHead = new GitAnalyzer(db.getProjectRoot()).getHeadCommit();
+        if (manifest.gitHead && localHead && manifest.gitHead !== localHead) {
+          console.log(chalk.yellow(`⚠️  Bundle was built at ${manifest.gitHead.substring(0, 12)}, checkout is at ${localHead.substring(0, 12)}`));
+        }
+      }
+
+      const result = new IndexBundle(db).import(file);
+
+      // Hashes that differ from the local checkout (and files the bundle lacks) are picked up incrementally
+      const stats = options.update !== false
+        ? await new Indexer(db).index({ projectRoot: db.getProjectRoot(), storage: manifest.storageMode, verbose: !options.quiet })
+        : null;
+
+      db.close();
+
+      if (options.quiet) {
+        console.log(JSON.stringify({
+          imported: result.filesImported,
+          pruned: result.filesPruned,
+          reindexed: stats ? stats.filesIndexed : 0,
+          git_head: manifest.gitHead,
+          schema_version: manifest.schemaVersion
+        }));
+        return;
+      }
+
+      console.log('\n' + chalk.green('✅ Import complete!'));
+      console.log(chalk.blue('📊 Summary:'));
+      console.log(`  • Files imported: ${chalk.yellow(result.filesImported)}`);
+      if (result.filesPruned > 0) {
+        console.log(`  • Files not in this checkout: ${chalk.yellow(result.filesPruned)}`);
+      }
+      if (stats) {
+        console.log(`  • Files re-indexed locally: ${chalk.yellow(stats.filesIndexed)}`);
+      }
+
+    } catch (error) {
+      console.error(chalk.red('❌ Import failed:'), error instanceof Error ? error.message : error);
+      process.exit(1);
+    }
+  });
\ No newline at end of file
@@ -0,0 +1,46 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { ScipImporter } from '../scip/index.js';
+import type { ImportScipCommandOptions } from '../types/index.js';
+import chalk from 'chalk';
+
+export const importScipCommand = new Command('import-scip')
+  .description('Merge a SCIP index or LSIF dump from a compiler-backed indexer into the local index')
+  .argument('<file>', 'SCIP index (e.g. index.scip from scip-typescript or scip-go) or LSIF dump')
+  .option('--prefix <dir>', 'Directory the indexer ran in, relative to the project root (default: project root)')
+  .option('--quiet', 'Minimal output')
+  .action(async (file: string, options: ImportScipCommandOptions) => {
+    try {
+      const index = ScipImporter.read(file);
+      const db = new PrimordynDB();
+      const result = new ScipImporter(db).import(index, { prefix: options.prefix });
+      db.close();
+
+      if (options.quiet) {
+        console.log(JSON.stringify({
+          tool: result.toolName || null,
+          documents: result.documentsImported,
+          skipped: result.documentsSkipped,
+          symbols_updated: result.symbolsUpdated,
+          symbols_added: result.symbolsAdded,
+          occurrences: result.occurrences,
+          calls_resolved: result.callsResolved
+        }));
+        return;
+      }
+
+      console.log(chalk.green('✅ Import complete!'), result.toolName ? chalk.gray(`(${result.toolName})`) : '');
+      console.log(chalk.blue('📊 Summary:'));
+      console.log(`  • Files merged: ${chalk.yellow(result.documentsImported)}`);
+      console.log(`  • Symbols updated: ${chalk.yellow(result.symbolsUpdated)}, added: ${chalk.yellow(result.symbolsAdded)}`);
+      console.log(`  • Occurrences: ${chalk.yellow(result.occurrences)}`);
+      console.log(`  • Calls resolved: ${chalk.yellow(result.callsResolved)}`);
+      if (result.documentsSkipped > 0) {
+        console.log(chalk.yellow(`⚠️  ${result.documentsSkipped} files in the index aren't in the local index; run 'primordyn index' first or check --prefix`));
+      }
+
+    } catch (error) {
+      console.error(chalk.red('❌ SCIP import failed:'), error instanceof Error ? error.message : error);
+      process.exit(1);
+    }
+  });
\ No newline at end of file
@@ -1,6 +1,7 @@
 import { Command } from 'commander';
 import { PrimordynDB } from '../database/index.js';
 import { Indexer } from '../indexer/index.js';
+import { STORAGE_MODES } from '../database/content.js';
 import chalk from 'chalk';
 
 export const indexCommand = new Command('index')
@@ -10,6 +11,7 @@ export const indexCommand = new Command('index')
   .option('--languages <langs>', 'Languages to index: ts,js,py,go,rs,java,rb,php,etc')
   .option('--max-size <kb>', 'Maximum file size in KB (default: 1024)', '1024')
   .option('--update', 'Update only changed files (incremental)')
+  .option('--storage <mode>', 'How to store file contents: full, compressed, none (default: from config, else the mode the index was built with)')
   .option('--quiet', 'Minimal output')
   .action(async (path: string, options) => {
     try {
@@ -42,12 +44,19 @@ export const indexCommand = new Command('index')
       
       const languages = options.languages ? options.languages.split(',').map((l: string) => l.trim()) : undefined;
       const maxFileSize = parseInt(options.maxSize) * 1024; // Convert KB to bytes
+
+      if (options.storage && !STORAGE_MODES.includes(options.storage)) {
+        console.error(chalk.red(`❌ Invalid storage mode: ${options.storage}`));
+        console.log(chalk.gray(`Valid modes: ${STORAGE_MODES.join(', ')}`));
+        process.exit(1);
+      }
       
       const stats = await indexer.index({
         projectRoot: projectPath,
         languages,
         maxFileSize,
         updateExisting: options.update,
+        storage: options.storage,
         verbose: !options.quiet
       });
       
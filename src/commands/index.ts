@@ -3,6 +3,20 @@ import { indexCommand } from './index-command.js';
 import { queryCommand } from './query-command.js';
 import { statsCommand } from './stats-command.js';
 import { clearCommand } from './clear-command.js';
+import { exportCommand } from './export-command.js';
+import { importCommand } from './import-command.js';
+import { scanSecretsCommand } from './scan-secrets-command.js';
+import { contextCommand } from './context-command.js';
+import { outlineCommand } from './outline-command.js';
+import { mapCommand } from './map-command.js';
+import { docsCommand } from './docs-command.js';
+import { docCoverageCommand } from './doc-coverage-command.js';
+import { hierarchyCommand } from './hierarchy-command.js';
+import { implementationsCommand } from './implementations-command.js';
+import { importScipCommand } from './import-scip-command.js';
+import { exportTagsCommand } from './export-tags-command.js';
+import { lspCommand } from './lsp-command.js';
+import { VERSION } from '../version.js';
 import chalk from 'chalk';
 
 export function createCLI(): Command {
@@ -11,7 +25,7 @@ export function createCLI(): Command {
   program
     .name('primordyn')
     .description('Local context index for AI-assisted development')
-    .version('0.1.0')
+    .version(VERSION)
     .configureHelp({
       sortSubcommands: true,
       subcommandTerm: (cmd) => cmd.name() + ' ' + cmd.usage(),
@@ -22,6 +36,19 @@ export function createCLI(): Command {
   program.addCommand(queryCommand);
   program.addCommand(statsCommand);
   program.addCommand(clearCommand);
+  program.addCommand(exportCommand);
+  program.addCommand(importCommand);
+  program.addCommand(scanSecretsCommand);
+  program.addCommand(contextCommand);
+  program.addCommand(outlineCommand);
+  program.addCommand(mapCommand);
+  program.addCommand(docsCommand);
+  program.addCommand(docCoverageCommand);
+  program.addCommand(hierarchyCommand);
+  program.addCommand(implementationsCommand);
+  program.addCommand(importScipCommand);
+  program.addCommand(exportTagsCommand);
+  program.addCommand(lspCommand);
 
   // Global error handler
   program.exitOverride((err) => {
@@ -0,0 +1,31 @@
+import { Command } from 'commander';
+import { LanguageServer, MessageReader, encodeMessage } from '../lsp/index.js';
+import chalk from 'chalk';
+
+export const lspCommand = new Command('lsp')
+  .description('Run a language server on stdio that answers symbol, definition, reference, hover and call hierarchy requests from the index')
+  .option('--stdio', 'Talk LSP over stdin/stdout (the default and only transport)')
+  .action(async () => {
+    try {
+      // stdout carries the protocol, so anything logged goes to stderr
+      console.log = console.error;
+
+      const server = new LanguageServer(message => process.stdout.write(encodeMessage(message)), {
+        onExit: code => process.exit(code)
+      });
+      const reader = new MessageReader();
+      process.stdin.on('data', (chunk: Buffer) => {
+        for (const message of reader.push(chunk)) {
+          void server.handle(message);
+        }
+      });
+      process.stdin.on('end', () => {
+        server.close();
+        process.exit(0);
+      });
+
+    } catch (error) {
+      console.error(chalk.red('❌ Language server failed:'), error instanceof Error ? error.message : error);
+      process.exit(1);
+    }
+  });
\ No newline at end of file
@@ -0,0 +1,91 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { ContextRetriever } from '../retriever/index.js';
+import { RepoMapBuilder, formatRepoMap, formatDirectory } from '../map/index.js';
+import { validateTokenLimit, validateFormat, validateDepth, ValidationError } from '../utils/validation.js';
+import type { MapCommandOptions, RepoMap } from '../types/index.js';
+import chalk from 'chalk';
+
+export const mapCommand = new Command('map')
+  .description('Overview of the repository for a first look: layout, entry points, most-called symbols and public APIs')
+  .option('--tokens <max>', 'Maximum tokens in response (default: 4000)', '4000')
+  .option('--format <type>', 'Output format: ai, json, human (default: ai)', 'ai')
+  .option('--depth <n>', 'Directory levels to show (default: 3)', '3')
+  .action(async (options: MapCommandOptions) => {
+    try {
+      const maxTokens = validateTokenLimit(options.tokens);
+      const format = validateFormat(options.format);
+      const maxDepth = validateDepth(options.depth);
+
+      const db = new PrimordynDB();
+      const map = new RepoMapBuilder(db).build({ maxTokens, maxDepth });
+      const summary = format === 'human' ? await new ContextRetriever(db).getContextSummary() : '';
+      db.close();
+
+      if (map.files === 0) {
+        console.error(chalk.yellow('No files indexed yet. Run `primordyn index` first.'));
+        process.exit(1);
+      }
+
+      switch (format) {
+        case 'json':
+          console.log(JSON.stringify(map, null, 2));
+          break;
+        case 'ai':
+          console.log(formatRepoMap(map));
+          break;
+        default:
+          outputHumanFormat(map, summary);
+      }
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Map failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+function outputHumanFormat(map: RepoMap, summary: string) {
+  console.log(chalk.blue(summary));
+
+  if (map.entryPoints.length > 0) {
+    console.log(chalk.green('🚪 Entry points:'));
+    for (const entry of map.entryPoints) {
+      console.log(`  ${chalk.cyan(entry.filePath)} ${chalk.gray(entry.reason)}`);
+    }
+    console.log();
+  }
+
+  if (map.directories.length > 0) {
+    console.log(chalk.green('🌳 Layout:'));
+    for (const directory of map.directories) {
+      console.log(`  ${formatDirectory(directory)}`);
+    }
+    console.log();
+  }
+
+  if (map.centralSymbols.length > 0) {
+    console.log(chalk.green('⭐ Most-called symbols:'));
+    for (const symbol of map.centralSymbols) {
+      console.log(`  ${chalk.cyan(symbol.name)} ${chalk.gray(`(${symbol.type}) ${symbol.filePath}:${symbol.line}`)} ` +
+        `${chalk.yellow(symbol.callers)} calls from ${chalk.yellow(symbol.callerFiles)} files`);
+    }
+    console.log();
+  }
+
+  if (map.modules.length > 0) {
+    console.log(chalk.green('📦 Public API by module:'));
+    for (const module of map.modules) {
+      console.log(`  ${chalk.cyan(module.filePath)}`);
+      console.log(chalk.gray(`    ${module.exports.join(', ')}`));
+    }
+    console.log();
+  }
+
+  if (map.truncated) {
+    console.log(chalk.yellow(`⚠️ Trimmed to ${map.maxTokens} tokens; raise --tokens for more`));
+  }
+}
\ No newline at end of file
@@ -0,0 +1,76 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { ContextRetriever } from '../retriever/index.js';
+import { validateTokenLimit, validateFormat, validateLanguages, validatePath, ValidationError } from '../utils/validation.js';
+import type { OutlineCommandOptions, OutlineResult } from '../types/index.js';
+import chalk from 'chalk';
+
+export const outlineCommand = new Command('outline')
+  .description('Show the skeleton of a file or directory: signatures and doc comments, bodies elided')
+  .argument('<path>', 'File or directory, relative to the project root ("." for everything)')
+  .option('--tokens <max>', 'Maximum tokens in response (default: 8000)', '8000')
+  .option('--format <type>', 'Output format: ai, json, human (default: ai)', 'ai')
+  .option('--languages <langs>', 'Filter by languages: ts,js,py,go,etc')
+  .action(async (target: string, options: OutlineCommandOptions) => {
+    try {
+      const validatedTarget = validatePath(target);
+      const maxTokens = validateTokenLimit(options.tokens);
+      const format = validateFormat(options.format);
+      const fileTypes = options.languages ? validateLanguages(options.languages) : undefined;
+
+      const db = new PrimordynDB();
+      const retriever = new ContextRetriever(db);
+      const outline = retriever.getOutlines(validatedTarget, { maxTokens, fileTypes });
+      db.close();
+
+      if (outline.files.length === 0 && outline.omitted === 0) {
+        console.error(chalk.yellow(`No indexed files under ${validatedTarget}`));
+        process.exit(1);
+      }
+
+      switch (format) {
+        case 'json':
+          console.log(JSON.stringify(outline, null, 2));
+          break;
+        case 'ai':
+          outputAIFormat(outline);
+          break;
+        default:
+          outputHumanFormat(outline);
+      }
+
+    } catch (error) {
+      if (error instanceof ValidationError) {
+        console.error(chalk.red('❌ Validation error:'), error.message);
+      } else {
+        console.error(chalk.red('❌ Outline failed:'), error instanceof Error ? error.message : error);
+      }
+      process.exit(1);
+    }
+  });
+
+function outputAIFormat(outline: OutlineResult) {
+  console.log(`# Outline of ${outline.target}\n`);
+  for (const file of outline.files) {
+    console.log(`## ${file.relativePath}`);
+    console.log(`\`\`\`${file.language || ''}`);
+    console.log(file.skeleton);
+    console.log('```\n');
+  }
+  console.log(`---\n${outline.files.length} files, ${outline.totalTokens} tokens` +
+    (outline.truncated ? `; ${outline.omitted} more files didn't fit (narrow the path or raise --tokens)` : ''));
+}
+
+function outputHumanFormat(outline: OutlineResult) {
+  console.log(chalk.bold(`\n🦴 Outline of ${outline.target}\n`));
+  for (const file of outline.files) {
+    console.log(chalk.cyan(file.relativePath) + chalk.gray(` (${file.tokens} of ${file.fullTokens} tokens)`));
+    for (const line of file.skeleton.split('\n')) {
+      console.log(chalk.gray('  │ ') + line);
+    }
+    console.log();
+  }
+  if (outline.truncated) {
+    console.log(chalk.yellow(`⚠️ ${outline.omitted} more files didn't fit the token budget`));
+  }
+}
\ No newline at end of file
@@ -1,13 +1,16 @@
 import { Command } from 'commander';
 import { PrimordynDB } from '../database/index.js';
+import { IndexLock } from '../database/lock.js';
 import { ContextRetriever } from '../retriever/index.js';
-import { QueryCommandOptions, QueryCommandResult, FileResult, DependencyGraph, ImpactAnalysis, GitHistory, RecentFileChanges } from '../types/index.js';
-import { validateTokenLimit, validateFormat, validateLanguages, validateDays, validateDepth, validateSearchTerm, ValidationError } from '../utils/validation.js';
+import { isStructuredQuery, QuerySyntaxError } from '../query/parser.js';
+import { QueryCommandOptions, QueryCommandResult, QueryResult, SymbolResult, FileResult, DependencyGraph, ImpactAnalysis, GitHistory, RecentFileChanges } from '../types/index.js';
+import { validateTokenLimit, validateFormat, validateLanguages, validateDays, validateDepth, validateSearchTerm, validateContextLines, ValidationError } from '../utils/validation.js';
 import chalk from 'chalk';
+import { join } from 'path';
 
 export const queryCommand = new Command('query')
   .description('Smart context retrieval for AI agents')
-  .argument('<search-term>', 'Symbol, function, class, or search query')
+  .argument('<search-term>', 'Symbol, function, class, search query, or filters like "type:function lang:go calls:Save"')
   .option('--tokens <max>', 'Maximum tokens in response (default: 8000)', '8000')
   .option('--format <type>', 'Output format: ai, json, human (default: ai)', 'ai')
   .option('--depth <n>', 'Depth of context expansion (default: 1)', '1')
@@ -18,6 +21,9 @@ export const queryCommand = new Command('query')
   .option('--recent <days>', 'Show commits from last N days (default: 7)')
   .option('--blame', 'Show git blame (who last modified each line)')
   .option('--languages <langs>', 'Filter by languages: ts,js,py,go,etc')
+  .option('--no-cache', 'Bypass the graph/impact result cache')
+  .option('--semantic', 'Natural-language search ranked by local vector similarity and BM25')
+  .option('--context-lines <n>', 'Lines of context around each usage with --include-callers (default: 3)', '3')
   .action(async (searchTerm: string, options: QueryCommandOptions) => {
     try {
       // Validate inputs
@@ -27,46 +33,73 @@ export const queryCommand = new Command('query')
       const depth = validateDepth(options.depth);
       const fileTypes = options.languages ? validateLanguages(options.languages) : undefined;
       const days = options.recent ? validateDays(options.recent) : undefined;
+      const contextLines = validateContextLines(options.contextLines);
       
       const db = new PrimordynDB();
-      const retriever = new ContextRetriever(db);
+      const retriever = new ContextRetriever(db, options.cache === false ? { cache: { enabled: false } } : {});
+
+      // A concurrent index run doesn't block reads; results come from the last committed index
+      const activeLock = db.getActiveIndexLock();
+      if (activeLock && format !== 'json') {
+        console.error(chalk.yellow(`⏳ ${capitalize(IndexLock.describe(activeLock))}; showing results from the previous index`));
+      }
       // const depth = parseInt(options.depth); // For future context expansion
       
-      // First, try to find as a symbol
-      const symbols = await retriever.findSymbol(validatedSearchTerm, { fileTypes });
-      
-      // Then get broader context
-      const searchResult = await retriever.query(validatedSearchTerm, {
+      const searchOptions = {
         maxTokens,
         includeContent: true,
         includeSymbols: true,
         includeImports: true,
         fileTypes,
-        sortBy: 'relevance'
-      });
+        sortBy: 'relevance' as const
+      };
+
+      let symbols: SymbolResult[];
+      let searchResult: QueryResult;
+      const structured = isStructuredQuery(validatedSearchTerm);
+      const ranked = structured || Boolean(options.semantic);
+
+      if (options.semantic) {
+        // e.g. "where do we retry failed HTTP requests"
+        searchResult = await retriever.querySemantic(validatedSearchTerm, searchOptions);
+        symbols = searchResult.symbols;
+      } else if (structured) {
+        // Filter syntax, e.g. type:function lang:go calls:Save -path:*_test.go
+        searchResult = await retriever.queryStructured(validatedSearchTerm, searchOptions);
+        symbols = searchResult.symbols;
+      } else {
+        // First, try to find as a symbol
+        symbols = await retriever.findSymbol(validatedSearchTerm, { fileTypes });
+        
+        // Then get broader context
+        searchResult = await retriever.query(validatedSearchTerm, searchOptions);
+      }
+
+      // Graph, impact and history are about one symbol; for filter and semantic queries that's the first match
+      const subject = ranked && symbols.length > 0 ? symbols[0].name : validatedSearchTerm;
       
       // Find usages if requested
       let usages: FileResult[] = [];
       if (options.includeCallers && symbols.length > 0) {
-        usages = await retriever.findUsages(validatedSearchTerm, { fileTypes, maxTokens: 2000 });
+        usages = await retriever.findUsages(subject, { fileTypes, maxTokens: 2000, contextLines });
       }
       
       // Get dependency graph if requested (using depth for call graph traversal)
       let dependencyGraph: DependencyGraph | null = null;
       if (options.showGraph) {
-        dependencyGraph = await retriever.getDependencyGraphWithDepth(validatedSearchTerm, depth);
+        dependencyGraph = await retriever.getDependencyGraphWithDepth(subject, depth);
       }
       
       // Get impact analysis if requested
       let impactAnalysis: ImpactAnalysis | null = null;
       if (options.impact) {
-        impactAnalysis = await retriever.getImpactAnalysis(validatedSearchTerm);
+        impactAnalysis = await retriever.getImpactAnalysis(subject);
       }
       
       // Get git history if requested
       let gitHistory: GitHistory | null = null;
       if (options.recent || options.blame) {
-        gitHistory = await retriever.getGitHistory(validatedSearchTerm);
+        gitHistory = await retriever.getGitHistory(subject);
       }
       
       // Get recent changes if requested
@@ -76,7 +109,7 @@ export const queryCommand = new Command('query')
       }
       
       // Combine results intelligently
-      const result: QueryCommandResult = {
+      const combined: QueryCommandResult = {
         primarySymbol: symbols.length > 0 ? symbols[0] : null,
         allSymbols: symbols,
         files: searchResult.files,
@@ -86,8 +119,19 @@ export const queryCommand = new Command('query')
         gitHistory,
         recentChanges,
         totalTokens: searchResult.totalTokens,
-        truncated: searchResult.truncated
+        truncated: searchResult.truncated,
+        degraded: searchResult.degraded
       };
+
+      // Catch anything not already masked by the retriever (e.g. git blame lines) for every format
+      const result = retriever.getRedactor().redactDeep(combined);
+
+      if (activeLock) {
+        result.indexStatus = {
+          rebuilding: true,
+          progress: IndexLock.progress(activeLock)
+        };
+      }
       
       // Handle different output formats
       switch (format) {
@@ -108,6 +152,13 @@ export const queryCommand = new Command('query')
     } catch (error) {
       if (error instanceof ValidationError) {
         console.error(chalk.red('❌ Validation error:'), error.message);
+      } else if (error instanceof QuerySyntaxError) {
+        console.error(chalk.red('❌ Invalid query:'), error.message);
+        console.error(chalk.gray(error.excerpt()));
+      } else if (isBusyError(error)) {
+        const activeLock = IndexLock.read(join(process.cwd(), '.primordyn'));
+        const status = activeLock ? IndexLock.describe(activeLock) : 'index is being rebuilt';
+        console.error(chalk.yellow(`⏳ ${capitalize(status)}; try again in a moment`));
       } else {
         console.error(chalk.red('❌ Query failed:'), error instanceof Error ? error.message : error);
       }
@@ -130,6 +181,15 @@ function outputAIFormat(searchTerm: string, result: QueryCommandResult, options:
       console.log(sym.signature);
       console.log(`\`\`\`\n`);
     }
+
+    if (sym.docTags?.deprecated !== undefined) {
+      console.log(`⚠️ Deprecated${sym.docTags.deprecated ? `: ${sym.docTags.deprecated}` : ''}\n`);
+    }
+
+    if (sym.documentation) {
+      console.log(`### Documentation`);
+      console.log(`${sym.documentation}\n`);
+    }
     
     if (sym.content) {
       console.log(`### Implementation`);
@@ -143,7 +203,9 @@ function outputAIFormat(searchTerm: string, result: QueryCommandResult, options:
   if (result.allSymbols.length > 1) {
     console.log(`### Related Symbols`);
     result.allSymbols.slice(1, 6).forEach((sym) => {
-      console.log(`- **${sym.name}** (${sym.type}) - ${sym.filePath}:${sym.lineStart}`);
+      const score = sym.score !== undefined ? ` [${sym.score.toFixed(2)}]` : '';
+      const summary = docSummary(sym);
+      console.log(`- **${sym.name}** (${sym.type}) - ${sym.filePath}:${sym.lineStart}${score}${summary ? ` — ${summary}` : ''}`);
     });
     console.log();
   }
@@ -152,7 +214,8 @@ function outputAIFormat(searchTerm: string, result: QueryCommandResult, options:
   if (result.files.length > 0) {
     console.log(`### Found in Files`);
     result.files.slice(0, 5).forEach((file) => {
-      console.log(`- **${file.relativePath}** (${file.tokens} tokens)`);
+      const shortened = file.representation ? `, ${file.representation} only` : '';
+      console.log(`- **${file.relativePath}** (${file.tokens} tokens${shortened})`);
       
       // Show imports/exports if relevant
       if (file.imports && file.imports.length > 0) {
@@ -225,6 +288,10 @@ function outputAIFormat(searchTerm: string, result: QueryCommandResult, options:
     console.log(`- **Files affected:** ${impact.filesAffected}`);
     console.log(`- **Symbols affected:** ${impact.symbolsAffected}`);
     console.log(`- **Tests affected:** ${impact.testsAffected}`);
+    const kinds = Object.entries(impact.referenceKinds ?? {});
+    if (kinds.length > 0) {
+      console.log(`- **Type references:** ${kinds.map(([kind, count]) => `${kind} ${count}`).join(', ')}`);
+    }
     console.log();
     
     if (impact.riskFactors.length > 0) {
@@ -319,16 +386,15 @@ function outputAIFormat(searchTerm: string, result: QueryCommandResult, options:
   if (result.usages && result.usages.length > 0) {
     console.log(`### Text References`);
     result.usages.slice(0, 10).forEach((file) => {
-      console.log(`- **${file.relativePath}**`);
-      if (file.metadata && typeof file.metadata === 'object' && 'usageLines' in file.metadata) {
-        const usageLines = (file.metadata as { usageLines?: number[] }).usageLines;
-        if (Array.isArray(usageLines)) {
-          const lines = usageLines.slice(0, 3);
-          console.log(`  Lines: ${lines.join(', ')}${usageLines.length > 3 ? '...' : ''}`);
-        }
-      }
+      (file.snippets || []).forEach((snippet) => {
+        const enclosing = snippet.enclosingSymbol ? ` (in ${snippet.enclosingSymbol})` : '';
+        const kinds = snippet.referenceKinds ? ` as ${snippet.referenceKinds.join(', ')}` : '';
+        console.log(`#### ${file.relativePath}:${snippet.usageLines[0]}${enclosing}${kinds}`);
+        console.log(`\`\`\`${file.language || ''}`);
+        console.log(snippet.content);
+        console.log(`\`\`\`\n`);
+      });
     });
-    console.log();
   }
   
   // Test files if requested
@@ -349,6 +415,12 @@ function outputAIFormat(searchTerm: string, result: QueryCommandResult, options:
   if (result.truncated) {
     console.log(`- ⚠️ Results truncated (increase --tokens for more context)`);
   }
+  if (result.degraded?.length) {
+    console.log(`- Shortened to fit the budget:`);
+    result.degraded.forEach((item) => {
+      console.log(`  - ${item.name}: ${item.from} → ${item.to}`);
+    });
+  }
 }
 
 function outputHumanFormat(searchTerm: string, result: QueryCommandResult, _options: QueryCommandOptions) {
@@ -373,6 +445,15 @@ function outputHumanFormat(searchTerm: string, result: QueryCommandResult, _opti
     if (sym.signature) {
       console.log(chalk.gray(`   Signature: ${sym.signature.substring(0, 100)}${sym.signature.length > 100 ? '...' : ''}`));
     }
+
+    if (sym.docTags?.deprecated !== undefined) {
+      console.log(chalk.yellow(`   ⚠️ Deprecated${sym.docTags.deprecated ? `: ${sym.docTags.deprecated}` : ''}`));
+    }
+
+    if (sym.documentation) {
+      console.log(chalk.gray('   Docs:'));
+      sym.documentation.split('\n').slice(0, 6).forEach(line => console.log(chalk.gray(`     ${line}`)));
+    }
     
     if (sym.content) {
       console.log(chalk.gray('\n   Implementation:'));
@@ -398,7 +479,20 @@ function outputHumanFormat(searchTerm: string, result: QueryCommandResult, _opti
     console.log(chalk.green('\n📁 Found in Files:'));
     result.files.slice(0, 5).forEach((file, index: number) => {
       console.log(chalk.blue(`   ${index + 1}. ${file.relativePath}`));
-      console.log(chalk.gray(`      ${file.language || 'unknown'} | ${file.tokens} tokens`));
+      const shortened = file.representation ? ` | ${file.representation} only` : '';
+      console.log(chalk.gray(`      ${file.language || 'unknown'} | ${file.tokens} tokens${shortened}`));
+    });
+  }
+  
+  // Usages
+  if (result.usages.length > 0) {
+    console.log(chalk.green('\n📍 Used at:'));
+    result.usages.slice(0, 5).forEach((file) => {
+      (file.snippets || []).forEach((snippet) => {
+        const enclosing = snippet.enclosingSymbol ? chalk.gray(` in ${snippet.enclosingSymbol}`) : '';
+        const kinds = snippet.referenceKinds ? chalk.gray(` as ${snippet.referenceKinds.join(', ')}`) : '';
+        console.log(chalk.blue(`   ${file.relativePath}:${snippet.usageLines.join(',')}`) + enclosing + kinds);
+      });
     });
   }
   
@@ -412,9 +506,28 @@ function outputHumanFormat(searchTerm: string, result: QueryCommandResult, _opti
   if (result.truncated) {
     console.log(chalk.yellow('\n  ⚠️ Results truncated due to token limit'));
   }
+  if (result.degraded?.length) {
+    const omitted = result.degraded.filter((item) => item.to === 'omitted').length;
+    console.log(chalk.gray(`  ${result.degraded.length - omitted} items shortened, ${omitted} left out (see --format json)`));
+  }
   
   console.log(chalk.gray('\n💡 Tips:'));
   console.log(`  • Use ${chalk.cyan('--format ai')} for AI-optimized markdown output`);
   console.log(`  • Use ${chalk.cyan('--include-tests')} to include test files`);
   console.log(`  • Use ${chalk.cyan('--include-callers')} to find usage locations`);
+}
+
+function isBusyError(error: unknown): boolean {
+  return error !== null && typeof error === 'object' && 'code' in error &&
+    (error.code === 'SQLITE_BUSY' || error.code === 'SQLITE_LOCKED');
+}
+
+function capitalize(text: string): string {
+  return text.charAt(0).toUpperCase() + text.slice(1);
+}
+
+// First line of a symbol's doc comment, for one-line listings
+function docSummary(sym: SymbolResult): string {
+  const first = sym.documentation?.split('\n')[0].trim() || '';
+  return first.length > 100 ? `${first.substring(0, 97)}...` : first;
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+import { Command } from 'commander';
+import { PrimordynDB } from '../database/index.js';
+import { loadConfig } from '../config/index.js';
+import { SecretRedactor } from '../security/redactor.js';
+import type { SecretFinding } from '../security/redactor.js';
+import chalk from 'chalk';
+
+export const scanSecretsCommand = new Command('scan-secrets')
+  .description('List secrets and PII found in indexed files')
+  .option('--json', 'Output JSON for AI agents and CI')
+  .option('--fail', 'Exit with code 1 when anything is found')
+  .action(async (options) => {
+    try {
+      const db = new PrimordynDB();
+      const config = loadConfig(db.getProjectRoot());
+      // Scanning reports findings even when output redaction is turned off
+      const redactor = new SecretRedactor({ ...config.redaction, enabled: true });
+
+      const files = db.getDatabase().prepare(`
+        SELECT relative_path as path, content
+        FROM files_with_content
+        ORDER BY relative_path
+      `).all() as { path: string; content: string }[];
+
+      const results: { path: string; findings: SecretFinding[] }[] = [];
+      for (const file of files) {
+        const findings = redactor.scan(file.content);
+        if (findings.length > 0) {
+          results.push({ path: file.path, findings });
+        }
+      }
+      db.close();
+
+      const total = results.reduce((sum, r) => sum + r.findings.length, 0);
+
+      if (options.json) {
+        console.log(JSON.stringify({
+          files_scanned: files.length,
+          findings: results.flatMap(r => r.findings.map(f => ({ path: r.path, ...f })))
+        }, null, 2));
+      } else if (total === 0) {
+        console.log(chalk.green(`✅ No secrets found in ${files.length.toLocaleString()} indexed files`));
+      } else {
+        console.log(chalk.yellow(`⚠️  Found ${total} potential secret${total === 1 ? '' : 's'} in ${results.length} file${results.length === 1 ? '' : 's'}:`));
+        for (const result of results) {
+          console.log('\n' + chalk.cyan(result.path));
+          for (const finding of result.findings) {
+            console.log(`  ${chalk.gray(`${finding.line}:${finding.column}`.padEnd(9))} ${chalk.red(finding.rule.padEnd(20))} ${finding.preview}`);
+          }
+        }
+        console.log('\n' + chalk.gray('These values are masked in query output while redaction is enabled.'));
+        console.log(chalk.gray('Add false positives to "redaction.allowlist" in primordyn.config.json.'));
+      }
+
+      if (options.fail && total > 0) {
+        process.exit(1);
+      }
+
+    } catch (error) {
+      console.error(chalk.red('❌ Secret scan failed:'), error instanceof Error ? error.message : error);
+      process.exit(1);
+    }
+  });
\ No newline at end of file
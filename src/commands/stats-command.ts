@@ -1,6 +1,8 @@
 import { Command } from 'commander';
 import { PrimordynDB } from '../database/index.js';
 import { Indexer } from '../indexer/index.js';
+import { IndexLock } from '../database/lock.js';
+import { loadConfig, CONFIG_FILE_NAME } from '../config/index.js';
 import chalk from 'chalk';
 
 export const statsCommand = new Command('stats')
@@ -14,16 +16,20 @@ export const statsCommand = new Command('stats')
       
       const dbInfo = await db.getDatabaseInfo();
       const indexStats = await indexer.getIndexStats();
+      const activeLock = db.getActiveIndexLock();
       
       if (options.json) {
         console.log(JSON.stringify({
-          status: dbInfo.fileCount > 0 ? 'indexed' : 'empty',
+          status: activeLock ? 'rebuilding' : dbInfo.fileCount > 0 ? 'indexed' : 'empty',
+          rebuild_progress: activeLock ? IndexLock.progress(activeLock) : null,
           files: dbInfo.fileCount,
           symbols: dbInfo.symbolCount,
           tokens: indexStats.totalTokens,
           last_indexed: dbInfo.lastIndexed?.toISOString() || null,
+          storage_mode: db.getStorageMode(),
           languages: indexStats.languages,
-          largest_files: indexStats.largestFiles.slice(0, 5)
+          largest_files: indexStats.largestFiles.slice(0, 5),
+          cache: db.getCacheStats()
         }, null, 2));
         db.close();
         return;
@@ -32,6 +38,10 @@ export const statsCommand = new Command('stats')
       // Text output
       console.log(chalk.blue('📊 Primordyn Index Statistics'));
       console.log(chalk.gray('━'.repeat(50)));
+
+      if (activeLock) {
+        console.log(chalk.yellow(`⏳ The ${IndexLock.describe(activeLock)}; figures below are from the previous index`));
+      }
       
       if (dbInfo.fileCount === 0) {
         console.log(chalk.yellow('No files indexed yet.'));
@@ -48,6 +58,9 @@ export const statsCommand = new Command('stats')
       console.log(`  • Total symbols: ${chalk.yellow(dbInfo.symbolCount.toLocaleString())}`);
       console.log(`  • Total size: ${chalk.yellow(formatBytes(dbInfo.totalSize))}`);
       console.log(`  • Total tokens: ${chalk.yellow(indexStats.totalTokens.toLocaleString())}`);
+      if (db.getStorageMode() !== 'full') {
+        console.log(`  • Content storage: ${chalk.cyan(db.getStorageMode())}`);
+      }
       
       if (dbInfo.lastIndexed) {
         const timeAgo = formatTimeAgo(dbInfo.lastIndexed);
@@ -96,6 +109,17 @@ export const statsCommand = new Command('stats')
         } catch {
           // Ignore if can't read file stats
         }
+
+        const cache = db.getCacheStats();
+        const config = loadConfig(db.getProjectRoot());
+        console.log(chalk.green('\n🧠 Result Cache:'));
+        if (!config.cache.enabled) {
+          console.log(`  • Status: ${chalk.yellow('disabled')} ${chalk.gray(`(${CONFIG_FILE_NAME})`)}`);
+        }
+        console.log(`  • Entries: ${chalk.yellow(cache.entries.toLocaleString())} ${chalk.gray(`/ ${config.cache.maxEntries.toLocaleString()} max`)}`);
+        console.log(`  • Size: ${chalk.yellow(formatBytes(cache.sizeBytes))}`);
+        console.log(`  • Hits: ${chalk.yellow(cache.hits.toLocaleString())} ${chalk.gray('| Misses:')} ${chalk.yellow(cache.misses.toLocaleString())} ${chalk.gray(`(${(cache.hitRate * 100).toFixed(1)}% hit rate)`)}`);
+        console.log(`  • Index generation: ${chalk.cyan(cache.generation)}`);
       }
       
       // Quick tips
@@ -0,0 +1,220 @@
+import { join } from 'path';
+import { homedir } from 'os';
+import { existsSync, readFileSync } from 'fs';
+import { STORAGE_MODES } from '../database/content.js';
+import type { StorageMode } from '../types/index.js';
+
+export const CONFIG_FILE_NAME = 'primordyn.config.json';
+
+export interface CacheConfig {
+  enabled: boolean;
+  maxEntries: number;
+}
+
+export interface StorageConfig {
+  // Unset keeps the mode the index was built with (full for a new index)
+  mode?: StorageMode;
+}
+
+export interface RedactionPattern {
+  name: string;
+  pattern: string;
+}
+
+export interface RedactionConfig {
+  enabled: boolean;
+  entropy: boolean;
+  patterns: RedactionPattern[];
+  allowlist: string[];
+}
+
+export const EMBEDDING_PROVIDERS = ['none', 'openai', 'command', 'stub'] as const;
+
+export type EmbeddingProviderName = typeof EMBEDDING_PROVIDERS[number];
+
+export interface EmbeddingsConfig {
+  provider: EmbeddingProviderName;
+  // OpenAI-compatible server, e.g. http://localhost:11434/v1
+  endpoint: string;
+  model: string;
+  // Environment variable holding the API key, if the server needs one
+  apiKeyEnv: string;
+  // Shell command for the "command" provider (JSON lines in, vectors out)
+  command: string;
+  batchSize: number;
+  maxRetries: number;
+}
+
+export interface PrimordynConfig {
+  cache: CacheConfig;
+  storage: StorageConfig;
+  redaction: RedactionConfig;
+  embeddings: EmbeddingsConfig;
+}
+
+export const DEFAULT_CONFIG: PrimordynConfig = {
+  cache: {
+    enabled: true,
+    maxEntries: 500
+  },
+  storage: {},
+  redaction: {
+    enabled: true,
+    entropy: true,
+    patterns: [],
+    allowlist: []
+  },
+  embeddings: {
+    provider: 'none',
+    endpoint: 'http://localhost:11434/v1',
+    model: '',
+    apiKeyEnv: 'OPENAI_API_KEY',
+    command: '',
+    batchSize: 32,
+    maxRetries: 3
+  }
+};
+
+export class ConfigError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'ConfigError';
+  }
+}
+
+type DeepPartial<T> = {
+  [K in keyof T]?: T[K] extends Array<infer U> ? U[] : T[K] extends object ? DeepPartial<T[K]> : T[K];
+};
+
+export type PrimordynConfigOverrides = DeepPartial<PrimordynConfig>;
+
+/** Settings of the person running primordyn, applied before the project's. */
+export function userConfigPath(): string {
+  return join(homedir(), '.primordyn', 'config.json');
+}
+
+/**
+ * Load the user's config and primordyn.config.json from the project root,
+ * falling back to defaults for anything neither sets.
+ */
+export function loadConfig(projectRoot: string = process.cwd(), overrides: PrimordynConfigOverrides = {}): PrimordynConfig {
+  const userConfig = readConfigFile(userConfigPath());
+  const projectConfig = readConfigFile(join(projectRoot, CONFIG_FILE_NAME));
+
+  const config = mergeConfig(mergeConfig(mergeConfig(structuredClone(DEFAULT_CONFIG), userConfig), projectConfig), overrides);
+  checkProjectEmbeddings(projectConfig, config);
+  validateConfig(config);
+  return config;
+}
+
+function readConfigFile(configPath: string): PrimordynConfigOverrides {
+  if (!existsSync(configPath)) {
+    return {};
+  }
+
+  let fileConfig: PrimordynConfigOverrides;
+  try {
+    fileConfig = JSON.parse(readFileSync(configPath, 'utf-8'));
+  } catch (error) {
+    throw new ConfigError(`Invalid ${configPath}: ${error instanceof Error ? error.message : error}`);
+  }
+
+  if (!fileConfig || typeof fileConfig !== 'object' || Array.isArray(fileConfig)) {
+    throw new ConfigError(`Invalid ${configPath}: expected a JSON object`);
+  }
+  return fileConfig;
+}
+
+// A checked-out repository's config is read by index and lsp without asking,
+// so it may not pick a shell command or an environment variable to send as a
+// key; those come from the user's config. It may move the endpoint only while
+// the key is the default one
+function checkProjectEmbeddings(projectConfig: PrimordynConfigOverrides, config: PrimordynConfig): void {
+  const embeddings = projectConfig.embeddings ?? {};
+  for (const key of ['command', 'apiKeyEnv'] as const) {
+    if (embeddings[key] !== undefined && embeddings[key] !== DEFAULT_CONFIG.embeddings[key]) {
+      throw new ConfigError(`embeddings.${key} is only read from ${userConfigPath()}, not from ${CONFIG_FILE_NAME}`);
+    }
+  }
+  if (embeddings.endpoint !== undefined && embeddings.endpoint !== DEFAULT_CONFIG.embeddings.endpoint &&
+      config.embeddings.apiKeyEnv !== DEFAULT_CONFIG.embeddings.apiKeyEnv) {
+    throw new ConfigError(
+      `embeddings.endpoint in ${CONFIG_FILE_NAME} can't be used with a custom embeddings.apiKeyEnv; set the endpoint in ${userConfigPath()}`
+    );
+  }
+}
+
+function mergeConfig<T>(base: T, override: unknown): T {
+  if (!override || typeof override !== 'object') {
+    return base;
+  }
+
+  const result = base as Record<string, unknown>;
+  for (const [key, value] of Object.entries(override as Record<string, unknown>)) {
+    if (value === undefined) {
+      continue;
+    }
+    const current = result[key];
+    if (current && typeof current === 'object' && !Array.isArray(current) && value && typeof value === 'object' && !Array.isArray(value)) {
+      result[key] = mergeConfig(current, value);
+    } else {
+      result[key] = value;
+    }
+  }
+  return base;
+}
+
+function validateConfig(config: PrimordynConfig): void {
+  if (typeof config.cache.enabled !== 'boolean') {
+    throw new ConfigError('cache.enabled must be true or false');
+  }
+  if (!Number.isInteger(config.cache.maxEntries) || config.cache.maxEntries < 0) {
+    throw new ConfigError('cache.maxEntries must be a non-negative integer');
+  }
+  if (config.storage.mode !== undefined && !STORAGE_MODES.includes(config.storage.mode)) {
+    throw new ConfigError(`storage.mode must be one of: ${STORAGE_MODES.join(', ')}`);
+  }
+  if (typeof config.redaction.enabled !== 'boolean' || typeof config.redaction.entropy !== 'boolean') {
+    throw new ConfigError('redaction.enabled and redaction.entropy must be true or false');
+  }
+  if (!Array.isArray(config.redaction.patterns) || !Array.isArray(config.redaction.allowlist)) {
+    throw new ConfigError('redaction.patterns and redaction.allowlist must be arrays');
+  }
+  for (const entry of config.redaction.patterns) {
+    if (!entry || typeof entry.name !== 'string' || typeof entry.pattern !== 'string') {
+      throw new ConfigError('redaction.patterns entries need a "name" and a "pattern"');
+    }
+    validateRegex(`redaction.patterns (${entry.name})`, entry.pattern);
+  }
+  for (const entry of config.redaction.allowlist) {
+    validateRegex('redaction.allowlist', entry);
+  }
+
+  const embeddings = config.embeddings;
+  if (!EMBEDDING_PROVIDERS.includes(embeddings.provider)) {
+    throw new ConfigError(`embeddings.provider must be one of: ${EMBEDDING_PROVIDERS.join(', ')}`);
+  }
+  if (embeddings.provider === 'openai' && (!embeddings.endpoint || !embeddings.model)) {
+    throw new ConfigError('embeddings.endpoint and embeddings.model are required for the openai provider');
+  }
+  if (embeddings.provider === 'command' && !embeddings.command) {
+    throw new ConfigError('embeddings.command is required for the command provider');
+  }
+  if (!Number.isInteger(embeddings.batchSize) || embeddings.batchSize <= 0) {
+    throw new ConfigError('embeddings.batchSize must be a positive integer');
+  }
+  if (!Number.isInteger(embeddings.maxRetries) || embeddings.maxRetries < 0) {
+    throw new ConfigError('embeddings.maxRetries must be a non-negative integer');
+  }
+}
+
+function validateRegex(field: string, pattern: unknown): void {
+  if (typeof pattern !== 'string') {
+    throw new ConfigError(`${field} must contain regular expression strings`);
+  }
+  try {
+    new RegExp(pattern);
+  } catch (error) {
+    throw new ConfigError(`${field}: invalid regular expression ${JSON.stringify(pattern)} (${error instanceof Error ? error.message : error})`);
+  }
+}
\ No newline at end of file
@@ -0,0 +1,80 @@
+import { ContextPacker, buildExcerpt, mergeWindows } from '../packer.js';
+import type { PackCandidate } from '../packer.js';
+import type { Representation } from '../../types/index.js';
+
+const candidate = (key: string, score: number, sizes: Partial<Record<Representation, number>>): PackCandidate<string> => ({
+  key,
+  label: key,
+  kind: 'file',
+  score,
+  options: (Object.entries(sizes) as [Representation, number][]).map(([representation, tokens]) => ({
+    representation,
+    tokens,
+    value: `${key}:${representation}`
+  }))
+});
+
+describe('ContextPacker', () => {
+  test('keeps everything in full when it fits', () => {
+    const result = new ContextPacker().pack([
+      candidate('a', 1, { full: 100, path: 5 }),
+      candidate('b', 0.5, { full: 100, path: 5 })
+    ], 500);
+
+    expect(result.items.map(item => item.value)).toEqual(['a:full', 'b:full']);
+    expect(result.totalTokens).toBe(200);
+    expect(result.degraded).toEqual([]);
+  });
+
+  test('degrades instead of dropping a large item', () => {
+    const result = new ContextPacker().pack([
+      candidate('big', 1, { full: 900, excerpt: 200, signature: 50, path: 5 }),
+      candidate('small', 0.8, { full: 150, path: 5 })
+    ], 400);
+
+    expect(result.items.map(item => item.value)).toEqual(['big:excerpt', 'small:full']);
+    expect(result.degraded).toEqual([{ kind: 'file', name: 'big', from: 'full', to: 'excerpt' }]);
+  });
+
+  test('prefers several small items over one that crowds them out', () => {
+    const result = new ContextPacker().pack([
+      candidate('first', 1, { full: 300, path: 5 }),
+      candidate('second', 0.9, { full: 150, path: 5 }),
+      candidate('third', 0.9, { full: 150, path: 5 })
+    ], 305);
+
+    expect(result.items.map(item => item.value)).toEqual(['first:path', 'second:full', 'third:full']);
+    expect(result.totalTokens).toBeLessThan(306);
+  });
+
+  test('reports items that did not fit at all', () => {
+    const result = new ContextPacker().pack([candidate('huge', 1, { full: 1000 })], 100);
+    expect(result.items).toEqual([]);
+    expect(result.degraded).toEqual([{ kind: 'file', name: 'huge', from: 'full', to: 'omitted' }]);
+  });
+});
+
+describe('buildExcerpt', () => {
+  const content = Array.from({ length: 30 }, (_, i) => `line ${i + 1}`).join('\n');
+
+  test('merges nearby ranges and marks gaps', () => {
+    const excerpt = buildExcerpt(content, [{ start: 10, end: 10 }, { start: 13, end: 13 }, { start: 25, end: 25 }], 1);
+    expect(excerpt.split('\n')).toEqual([
+      '...', 'line 9', 'line 10', 'line 11', 'line 12', 'line 13', 'line 14',
+      '...', 'line 24', 'line 25', 'line 26', '...'
+    ]);
+  });
+
+  test('falls back to the top of the file without ranges', () => {
+    expect(buildExcerpt(content, [], 3, 2)).toBe('line 1\nline 2\n...');
+  });
+});
+
+describe('mergeWindows', () => {
+  test('widens ranges and joins those that touch', () => {
+    expect(mergeWindows([{ start: 20, end: 20 }, { start: 2, end: 2 }, { start: 6, end: 6 }], 2, 21)).toEqual([
+      { start: 1, end: 8 },
+      { start: 18, end: 21 }
+    ]);
+  });
+});
\ No newline at end of file
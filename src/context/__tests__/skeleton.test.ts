@@ -0,0 +1,93 @@
+import { SkeletonRenderer } from '../skeleton.js';
+
+describe('SkeletonRenderer', () => {
+  const renderer = new SkeletonRenderer();
+
+  test('elides function bodies and keeps doc comments and nesting', () => {
+    const source = [
+      "import { db } from './db';",
+      '',
+      '/** Loads users. */',
+      'export class UserService {',
+      '  private cache = new Map();',
+      '',
+      '  /** Find a user by id. */',
+      '  async getUser(id: string): Promise<User> {',
+      '    const cached = this.cache.get(id);',
+      '    return cached ?? db.find(id);',
+      '  }',
+      '}',
+      '',
+      'export interface User {',
+      '  id: string;',
+      '}'
+    ].join('\n');
+
+    const skeleton = renderer.render(source, [
+      { name: 'UserService', type: 'class', lineStart: 4, lineEnd: 12 },
+      { name: 'UserService.getUser', type: 'method', lineStart: 8, lineEnd: 11 },
+      { name: 'User', type: 'interface', lineStart: 14, lineEnd: 16 }
+    ]);
+
+    expect(skeleton).toBe([
+      "import { db } from './db';",
+      '',
+      '/** Loads users. */',
+      'export class UserService {',
+      '  private cache = new Map();',
+      '',
+      '  /** Find a user by id. */',
+      '  async getUser(id: string): Promise<User> {',
+      '    ...',
+      '  }',
+      '}',
+      '',
+      'export interface User {',
+      '  id: string;',
+      '}'
+    ].join('\n'));
+  });
+
+  test('keeps Python docstrings and elides the rest of the body', () => {
+    const source = [
+      'class Cart(Base):',
+      '    def total(self, tax):',
+      '        """Sum of the items plus tax."""',
+      '        subtotal = sum(self.items)',
+      '        return subtotal * (1 + tax)',
+      '',
+      'def main():',
+      '    Cart().total(0.2)'
+    ].join('\n');
+
+    const skeleton = renderer.render(source, [
+      { name: 'Cart', type: 'class', lineStart: 1, lineEnd: 5 },
+      { name: 'total', type: 'method', lineStart: 2, lineEnd: 6 },
+      { name: 'main', type: 'function', lineStart: 7, lineEnd: 8 }
+    ]);
+
+    expect(skeleton).toBe([
+      'class Cart(Base):',
+      '    def total(self, tax):',
+      '        """Sum of the items plus tax."""',
+      '        ...',
+      '',
+      'def main():',
+      '    ...'
+    ].join('\n'));
+  });
+
+  test('renders a single symbol range', () => {
+    const source = 'func Start() {\n\tlisten()\n}\n\nfunc Stop() {\n\tclose()\n}';
+    const skeleton = renderer.render(source, [
+      { name: 'Start', type: 'function', lineStart: 1, lineEnd: 3 },
+      { name: 'Stop', type: 'function', lineStart: 5, lineEnd: 7 }
+    ], { start: 5, end: 7 });
+    expect(skeleton).toBe('func Stop() {\n\t...\n}');
+  });
+
+  test('leaves one-line symbols alone', () => {
+    const source = 'def one(self): return 1';
+    expect(renderer.render(source, [{ name: 'one', type: 'function', lineStart: 1, lineEnd: 1 }])).toBe(source);
+  });
+});
\ No newline at end of file
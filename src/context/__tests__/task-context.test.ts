@@ -0,0 +1,146 @@
+import { PrimordynDB } from '../../database/index.js';
+import { ContextRetriever } from '../../retriever/index.js';
+import { extractTaskTerms, TaskContextBuilder } from '../index.js';
+import { createTestDb, removeTestDir, addFile, addSymbol, addCall } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('extractTaskTerms', () => {
+  test('separates identifiers, paths and keywords', () => {
+    const terms = extractTaskTerms('Add rate limiting to UserService.login in src/auth/service.ts and call `throttle()`');
+
+    expect(terms.identifiers).toContain('UserService.login');
+    expect(terms.identifiers).toContain('throttle');
+    expect(terms.paths).toEqual(['src/auth/service.ts']);
+    expect(terms.keywords).toContain('rate');
+    expect(terms.keywords).toContain('limiting');
+  });
+
+  test('recognizes naming conventions as identifiers', () => {
+    const terms = extractTaskTerms('parse_config crashes when getUserById returns HTTPClient errors');
+    expect(terms.identifiers).toEqual(['parse_config', 'getUserById', 'HTTPClient']);
+    expect(terms.keywords).toContain('crashes');
+  });
+
+  test('drops filler words', () => {
+    const terms = extractTaskTerms('where is the code that handles this');
+    expect(terms.keywords).toEqual(['handles']);
+  });
+});
+
+describe('TaskContextBuilder', () => {
+  const testDir = join(process.cwd(), '.test-task-context');
+  let db: PrimordynDB;
+
+  const daysAgo = (days: number) => new Date(Date.now() - days * 86_400_000);
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+
+    const token = addFile(db, 'src/auth/token.ts', {
+      content: [
+        'export function validateToken(token: string) {',
+        '  const digest = hash(token);',
+        '  return digest.length > 0;',
+        '}'
+      ].join('\n'),
+      lastModified: daysAgo(0)
+    });
+    const session = addFile(db, 'src/auth/session.ts', {
+      content: [
+        "import { validateToken } from './token.js';",
+        'export function login(token: string) {',
+        '  return validateToken(token);',
+        '}'
+      ].join('\n'),
+      metadata: { imports: ['./token.js'] },
+      lastModified: daysAgo(0)
+    });
+    const crypto = addFile(db, 'src/util/crypto.ts', {
+      content: [
+        'export function hash(value: string) {',
+        '  return value;',
+        '}'
+      ].join('\n'),
+      lastModified: daysAgo(90)
+    });
+    const audit = addFile(db, 'src/audit.ts', {
+      content: [
+        'export function record(value: string) {',
+        '  return hash(value);',
+        '}'
+      ].join('\n'),
+      lastModified: daysAgo(0)
+    });
+
+    const validate = addSymbol(db, token, 'validateToken', { lines: [1, 4], signature: 'export function validateToken(token: string)' });
+    const login = addSymbol(db, session, 'login', { lines: [2, 4], signature: 'export function login(token: string)' });
+    const hash = addSymbol(db, crypto, 'hash', { lines: [1, 3], signature: 'export function hash(value: string)' });
+    const record = addSymbol(db, audit, 'record', { lines: [1, 3], signature: 'export function record(value: string)' });
+    addCall(db, { callerSymbolId: login, callerFileId: session, calleeName: 'validateToken', calleeSymbolId: validate, calleeFileId: token, line: 3 });
+    addCall(db, { callerSymbolId: validate, callerFileId: token, calleeName: 'hash', calleeSymbolId: hash, calleeFileId: crypto, line: 2 });
+    addCall(db, { callerSymbolId: record, callerFileId: audit, calleeName: 'hash', calleeSymbolId: hash, calleeFileId: crypto, line: 2 });
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  const build = (task: string, maxTokens?: number) => new TaskContextBuilder(db, new ContextRetriever(db)).build(task, { maxTokens });
+
+  test('seeds from identifiers named in the task and expands one hop through calls', async () => {
+    const context = await build('Fix `validateToken`');
+    const symbol = (name: string) => context.items.find(item => item.name === name)!;
+
+    expect(context.identifiers).toEqual(['validateToken']);
+    // validateToken's own file is small enough to include whole, which absorbs the symbol
+    const token = context.items.find(item => item.filePath === 'src/auth/token.ts')!;
+    expect(token.kind).toBe('file');
+    expect(token.reasons).toContain('validateToken: matches `validateToken` from the task');
+
+    expect(symbol('hash').relevance).toBe(0.5);
+    expect(symbol('hash').reasons).toEqual(['called by validateToken']);
+    expect(symbol('login').relevance).toBe(0.5);
+    expect(symbol('login').reasons).toContain('calls validateToken');
+    // record only calls hash, two hops from the seed
+    expect(context.items.some(item => item.filePath === 'src/audit.ts')).toBe(false);
+  });
+
+  test('follows imports from files named in the task', async () => {
+    const context = await build('Clean up src/auth/session.ts');
+
+    expect(context.items.map(item => [item.filePath, item.relevance, item.reasons])).toEqual([
+      ['src/auth/session.ts', 1, ['src/auth/session.ts is mentioned in the task']],
+      ['src/auth/token.ts', 0.4, ['imported by src/auth/session.ts']]
+    ]);
+  });
+
+  test('ranks by relevance, call-graph centrality and recency', async () => {
+    const context = await build('Fix `validateToken`');
+
+    // hash and login are equally relevant; hash has the most callers but is 90 days old
+    expect(context.items.map(item => item.name ?? item.filePath)).toEqual(['hash', 'login', 'src/auth/token.ts']);
+    const hash = context.items[0];
+    expect(hash.centrality).toBe(1);
+    expect(hash.recency).toBe(0.125);
+    expect(context.items[1].centrality).toBe(0);
+    expect(context.items[1].recency).toBe(1);
+    for (const item of context.items) {
+      expect(item.score).toBeCloseTo(0.6 * item.relevance + 0.25 * item.centrality + 0.15 * item.recency, 2);
+    }
+  });
+
+  test('keeps the best-scored items within the token budget', async () => {
+    const context = await build('Fix `validateToken`', 30);
+
+    expect(context.totalTokens).toBeLessThanOrEqual(30);
+    expect(context.items.map(item => item.name)).toEqual(['validateToken']);
+    expect(context.items[0].relevance).toBe(1);
+    expect(context.omitted).toBe(3);
+    expect(context.degraded.map(item => `${item.name} ${item.to}`)).toEqual([
+      'hash (src/util/crypto.ts) omitted',
+      'login (src/auth/session.ts) omitted',
+      'src/auth/token.ts omitted'
+    ]);
+  });
+});
\ No newline at end of file
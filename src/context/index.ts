@@ -0,0 +1,429 @@
+import { dirname, join, normalize } from 'path';
+import { encodingForModel, Tiktoken } from 'js-tiktoken';
+import { PrimordynDB } from '../database/index.js';
+import { ContextRetriever } from '../retriever/index.js';
+import { SymbolSearch } from '../search/symbol-search.js';
+import { semanticTerms } from '../search/semantic-terms.js';
+import { ftsTerm } from '../search/identifiers.js';
+import { ContextPacker, buildExcerpt } from './packer.js';
+import { SkeletonRenderer } from './skeleton.js';
+import type { SkeletonSymbol } from './skeleton.js';
+import type { PackCandidate, PackOption } from './packer.js';
+import type { ContextItem, DegradedItem, Representation, TaskContext } from '../types/index.js';
+
+export interface TaskContextOptions {
+  maxTokens?: number;
+  fileTypes?: string[];
+}
+
+export interface TaskTerms {
+  identifiers: string[];
+  paths: string[];
+  keywords: string[];
+}
+
+interface Candidate {
+  kind: 'symbol' | 'file';
+  id: number;
+  fileId: number;
+  relevance: number;
+  reasons: string[];
+}
+
+interface SymbolInfo {
+  id: number;
+  name: string;
+  type: string;
+  fileId: number;
+  lineStart: number;
+  lineEnd: number;
+}
+
+// Weights of the final score; relevance to the task dominates
+const RELEVANCE_WEIGHT = 0.6;
+const CENTRALITY_WEIGHT = 0.25;
+const RECENCY_WEIGHT = 0.15;
+// Files modified this many days ago count half as recent as ones modified today
+const RECENCY_HALF_LIFE_DAYS = 30;
+// How much of a seed's relevance passes to what it calls, calls it, or imports
+const CALL_DECAY = 0.5;
+const IMPORT_DECAY = 0.4;
+
+const SOURCE_EXTENSIONS = 'ts|tsx|js|jsx|mjs|cjs|py|go|rs|java|kt|rb|php|cs|cpp|cc|c|h|hpp|swift|scala|md|json|ya?ml|toml';
+
+/**
+ * Pull likely identifiers (camelCase, snake_case, dotted or `quoted` names),
+ * file paths and plain keywords out of a task description.
+ */
+export function extractTaskTerms(task: string): TaskTerms {
+  const identifiers = new Set<string>();
+  const paths = new Set<string>();
+  const keywords = new Set<string>();
+
+  let text = task;
+  for (const match of task.matchAll(/`([^`]+)`/g)) {
+    const value = match[1].trim().replace(/\(\)$/, '');
+    if (new RegExp(`[/\\\\]|\\.(${SOURCE_EXTENSIONS})$`).test(value)) {
+      paths.add(value);
+    } else if (/^[A-Za-z_$][\w$.:#]*$/.test(value)) {
+      identifiers.add(value);
+    }
+    text = text.replace(match[0], ' ');
+  }
+
+  const pathPattern = new RegExp(`(?:[\\w.-]+/)+[\\w.-]+|\\b[\\w-]+\\.(?:${SOURCE_EXTENSIONS})\\b`, 'g');
+  for (const match of text.matchAll(pathPattern)) {
+    paths.add(match[0].replace(/^\.\//, ''));
+  }
+  text = text.replace(pathPattern, ' ');
+
+  for (const word of text.match(/[A-Za-z_$][\w$]*(?:(?:\.|::)[A-Za-z_$][\w$]*)*(?:\(\))?/g) || []) {
+    const name = word.replace(/\(\)$/, '');
+    const looksLikeCode = word.endsWith('()') ||
+      /[a-z][A-Z]/.test(name) ||
+      /^[A-Z][a-z0-9]+[A-Z]|^[A-Z]{2,}[a-z]/.test(name) ||
+      /\w_\w/.test(name) ||
+      /\w(?:\.|::)\w/.test(name);
+    if (looksLikeCode) {
+      identifiers.add(name);
+    } else if (name.length >= 3 && semanticTerms(name).length > 0) {
+      keywords.add(name.toLowerCase());
+    }
+  }
+
+  return { identifiers: [...identifiers], paths: [...paths], keywords: [...keywords] };
+}
+
+/**
+ * Turns a task description into a ranked, token-budgeted set of symbols and
+ * files: identifiers and paths named in the task, semantic and full-text
+ * matches, and what those call, are called by, or import. Every item carries
+ * the reasons it was included.
+ */
+export class TaskContextBuilder {
+  private db: PrimordynDB;
+  private retriever: ContextRetriever;
+  private symbolSearch: SymbolSearch;
+  private tokenEncoder: Tiktoken;
+  private skeletonRenderer: SkeletonRenderer;
+
+  constructor(db: PrimordynDB, retriever: ContextRetriever) {
+    this.db = db;
+    this.retriever = retriever;
+    this.symbolSearch = new SymbolSearch(db);
+    this.tokenEncoder = encodingForModel('gpt-4');
+    this.skeletonRenderer = new SkeletonRenderer();
+  }
+
+  public async build(task: string, options: TaskContextOptions = {}): Promise<TaskContext> {
+    const maxTokens = options.maxTokens || 8000;
+    const terms = extractTaskTerms(task);
+    const candidates = new Map<string, Candidate>();
+
+    const add = (kind: Candidate['kind'], id: number, fileId: number, relevance: number, reason: string) => {
+      const key = `${kind}:${id}`;
+      const existing = candidates.get(key);
+      if (existing) {
+        existing.relevance = Math.max(existing.relevance, relevance);
+        if (!existing.reasons.includes(reason)) {
+          existing.reasons.push(reason);
+        }
+      } else {
+        candidates.set(key, { kind, id, fileId, relevance, reasons: [reason] });
+      }
+    };
+
+    this.seedFromIdentifiers(terms.identifiers, options, add);
+    this.seedFromPaths(terms.paths, add);
+    await this.seedFromSemantic(task, options, add);
+    this.seedFromKeywords(terms.keywords, options, add);
+    this.expand([...candidates.values()], add);
+
+    const items = this.score([...candidates.values()]);
+    const packed = this.pack(items, maxTokens);
+
+    return {
+      task,
+      ...terms,
+      items: packed.items,
+      totalTokens: packed.totalTokens,
+      maxTokens,
+      omitted: packed.degraded.filter(item => item.to === 'omitted').length,
+      degraded: packed.degraded
+    };
+  }
+
+  private seedFromIdentifiers(identifiers: string[], options: TaskContextOptions, add: AddCandidate): void {
+    for (const identifier of identifiers) {
+      const last = identifier.split(/[.:#]+/).filter(Boolean).pop() || identifier;
+      for (const symbol of this.symbolSearch.search(identifier, { fileTypes: options.fileTypes, limit: 5 })) {
+        const exact = symbol.name === identifier || symbol.name === last;
+        add('symbol', symbol.id, this.symbolInfo(symbol.id)?.fileId ?? 0, exact ? 1 : 0.7, `matches \`${identifier}\` from the task`);
+      }
+    }
+  }
+
+  private seedFromPaths(paths: string[], add: AddCandidate): void {
+    const findFile = this.db.getDatabase().prepare(`
+      SELECT id FROM files
+      WHERE relative_path = ? OR relative_path LIKE ? ESCAPE '\\'
+      ORDER BY length(relative_path)
+      LIMIT 3
+    `);
+    for (const path of paths) {
+      const suffix = `%/${path.replace(/[\\%_]/g, '\\$&')}`;
+      for (const file of findFile.all(path, suffix) as { id: number }[]) {
+        add('file', file.id, file.id, 1, `${path} is mentioned in the task`);
+      }
+    }
+  }
+
+  private async seedFromSemantic(task: string, options: TaskContextOptions, add: AddCandidate): Promise<void> {
+    const { symbols } = await this.retriever.searchSemantic(task, { fileTypes: options.fileTypes, limit: 15 });
+    const best = symbols[0]?.score || 0;
+    for (const symbol of symbols) {
+      if (best > 0 && symbol.score > 0) {
+        add('symbol', symbol.id, symbol.fileId, 0.8 * (symbol.score / best), `related to the task (semantic score ${symbol.score.toFixed(2)})`);
+      }
+    }
+  }
+
+  private seedFromKeywords(keywords: string[], options: TaskContextOptions, add: AddCandidate): void {
+    if (keywords.length === 0) {
+      return;
+    }
+
+    const params: unknown[] = [keywords.map(keyword => ftsTerm(keyword, true)).join(' OR ')];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+    const rows = this.db.getDatabase().prepare(`
+      SELECT f.id, -bm25(files_fts) as score
+      FROM files_fts fts
+      JOIN files f ON f.id = fts.rowid
+      WHERE files_fts MATCH ?
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY bm25(files_fts)
+      LIMIT 8
+    `).all(...params) as { id: number; score: number }[];
+
+    const best = rows[0]?.score || 0;
+    for (const row of rows) {
+      const relevance = best > 0 ? 0.6 * Math.max(0, row.score) / best : 0.3;
+      add('file', row.id, row.id, relevance, `full-text match for ${keywords.slice(0, 5).join(', ')}`);
+    }
+  }
+
+  /** One hop through the call graph from seed symbols and through imports from seed files. */
+  private expand(seeds: Candidate[], add: AddCandidate): void {
+    const database = this.db.getDatabase();
+    const callees = database.prepare(`
+      SELECT DISTINCT s.id, s.name, s.file_id as fileId
+      FROM call_graph cg JOIN symbols s ON s.id = cg.callee_symbol_id
+      WHERE cg.caller_symbol_id = ?
+      LIMIT 10
+    `);
+    const callers = database.prepare(`
+      SELECT DISTINCT s.id, s.name, s.file_id as fileId
+      FROM call_graph cg JOIN symbols s ON s.id = cg.caller_symbol_id
+      WHERE cg.callee_symbol_id = ?
+      LIMIT 10
+    `);
+
+    const expandedFiles = new Set<number>();
+    for (const seed of seeds) {
+      if (seed.kind === 'symbol') {
+        const name = this.symbolInfo(seed.id)?.name || 'this symbol';
+        for (const callee of callees.all(seed.id) as { id: number; fileId: number }[]) {
+          add('symbol', callee.id, callee.fileId, seed.relevance * CALL_DECAY, `called by ${name}`);
+        }
+        for (const caller of callers.all(seed.id) as { id: number; fileId: number }[]) {
+          add('symbol', caller.id, caller.fileId, seed.relevance * CALL_DECAY, `calls ${name}`);
+        }
+      }
+
+      if (!expandedFiles.has(seed.fileId)) {
+        expandedFiles.add(seed.fileId);
+        const file = database.prepare('SELECT relative_path as relativePath, metadata FROM files WHERE id = ?')
+          .get(seed.fileId) as { relativePath: string; metadata: string | null } | undefined;
+        for (const imported of this.resolveImports(file)) {
+          add('file', imported, imported, seed.relevance * IMPORT_DECAY, `imported by ${file!.relativePath}`);
+        }
+      }
+    }
+  }
+
+  private resolveImports(file: { relativePath: string; metadata: string | null } | undefined): number[] {
+    if (!file?.metadata) {
+      return [];
+    }
+    const imports: string[] = JSON.parse(file.metadata).imports || [];
+    const database = this.db.getDatabase();
+    const exact = database.prepare(`
+      SELECT id FROM files
+      WHERE relative_path = ? OR relative_path LIKE ? ESCAPE '\\' OR relative_path LIKE ? ESCAPE '\\'
+      LIMIT 1
+    `);
+
+    const ids = new Set<number>();
+    for (const specifier of imports) {
+      // ./utils.js -> utils, pkg.module -> pkg/module; anything not in the index is external
+      const relative = specifier.startsWith('.');
+      const base = (relative ? normalize(join(dirname(file.relativePath), specifier)) : specifier.replace(/\./g, '/'))
+        .replace(/\\/g, '/')
+        .replace(/\.(js|mjs|cjs|jsx|ts|tsx)$/, '');
+      const escaped = base.replace(/[\\%_]/g, '\\$&');
+      const row = relative
+        ? exact.get(base, `${escaped}.%`, `${escaped}/index.%`)
+        : exact.get(base, `%${escaped}.%`, `%${escaped}/__init__.py`);
+      if (row) {
+        ids.add((row as { id: number }).id);
+      }
+    }
+    return [...ids];
+  }
+
+  private score(candidates: Candidate[]): ContextItem[] {
+    const database = this.db.getDatabase();
+    const symbolInDegree = database.prepare('SELECT COUNT(*) as count FROM call_graph WHERE callee_symbol_id = ?');
+    const fileInDegree = database.prepare('SELECT COUNT(*) as count FROM call_graph WHERE callee_file_id = ? AND caller_file_id != ?');
+    const fileInfo = database.prepare('SELECT relative_path as relativePath, language, last_modified as lastModified FROM files WHERE id = ?');
+
+    const rows = candidates.map(candidate => {
+      const file = fileInfo.get(candidate.fileId) as { relativePath: string; language: string | null; lastModified: string } | undefined;
+      const inDegree = candidate.kind === 'symbol'
+        ? (symbolInDegree.get(candidate.id) as { count: number }).count
+        : (fileInDegree.get(candidate.id, candidate.id) as { count: number }).count;
+      const ageDays = file ? Math.max(0, (Date.now() - new Date(file.lastModified).getTime()) / 86_400_000) : Infinity;
+      return { candidate, file, inDegree, recency: Math.pow(0.5, ageDays / RECENCY_HALF_LIFE_DAYS) };
+    });
+
+    const maxInDegree = Math.max(0, ...rows.map(row => row.inDegree));
+    return rows
+      .filter(row => row.file)
+      .map(({ candidate, file, inDegree, recency }) => {
+        const centrality = maxInDegree > 0 ? Math.log1p(inDegree) / Math.log1p(maxInDegree) : 0;
+        const symbol = candidate.kind === 'symbol' ? this.symbolInfo(candidate.id) : undefined;
+        const item: ContextItem = {
+          kind: candidate.kind,
+          id: candidate.id,
+          filePath: file!.relativePath,
+          language: file!.language,
+          score: round(RELEVANCE_WEIGHT * candidate.relevance + CENTRALITY_WEIGHT * centrality + RECENCY_WEIGHT * (Number.isFinite(recency) ? recency : 0)),
+          relevance: round(candidate.relevance),
+          centrality: round(centrality),
+          recency: round(Number.isFinite(recency) ? recency : 0),
+          reasons: candidate.reasons,
+          tokens: 0
+        };
+        if (symbol) {
+          item.name = symbol.name;
+          item.type = symbol.type;
+          item.lineStart = symbol.lineStart;
+          item.lineEnd = symbol.lineEnd;
+        }
+        return item;
+      })
+      .sort((a, b) => b.score - a.score);
+  }
+
+  /**
+   * The best-value mix for the budget: items that don't fit in full are cut
+   * down to an excerpt, their skeleton or just their location. A file
+   * included whole absorbs its symbols.
+   */
+  private pack(items: ContextItem[], maxTokens: number): { items: ContextItem[]; totalTokens: number; degraded: DegradedItem[] } {
+    const database = this.db.getDatabase();
+    const selectFile = database.prepare('SELECT id, content FROM files_with_content WHERE relative_path = ?');
+    const selectSymbols = database.prepare(`
+      SELECT s.name, s.type, s.line_start as lineStart, s.line_end as lineEnd
+      FROM symbols s JOIN files f ON s.file_id = f.id
+      WHERE f.relative_path = ?
+      ORDER BY s.line_start
+    `);
+    const redactor = this.retriever.getRedactor();
+    const fileText = new Map<string, string>();
+    const textOf = (path: string) => {
+      if (!fileText.has(path)) {
+        const row = selectFile.get(path) as { content: string } | undefined;
+        fileText.set(path, redactor.redact(row?.content || ''));
+      }
+      return fileText.get(path)!;
+    };
+    const option = (representation: Representation, content: string) => ({ representation, content, tokens: this.countTokens(content) });
+
+    const candidates: PackCandidate<ContextItem>[] = items.map(item => {
+      const text = textOf(item.filePath);
+      const symbols = selectSymbols.all(item.filePath) as SkeletonSymbol[];
+      const options: ReturnType<typeof option>[] = [];
+      if (item.kind === 'symbol') {
+        const range = { start: item.lineStart || 1, end: item.lineEnd || item.lineStart || 1 };
+        options.push(option('full', text.split('\n').slice(range.start - 1, range.end).join('\n')));
+        options.push(option('signature', this.skeletonRenderer.render(text, symbols, range)));
+      } else {
+        // Excerpts keep the parts of the file other candidates point at
+        const ranges = items
+          .filter(other => other.kind === 'symbol' && other.filePath === item.filePath && other.lineStart)
+          .map(other => ({ start: other.lineStart!, end: other.lineEnd || other.lineStart! }));
+        options.push(option('full', text));
+        options.push(option('excerpt', buildExcerpt(text, ranges)));
+        if (symbols.length > 0) {
+          options.push(option('signature', this.skeletonRenderer.render(text, symbols)));
+        }
+      }
+      // Only the location is shown, which still costs a line
+      options.push({ ...option('path', ''), tokens: this.countTokens(item.filePath) });
+
+      return {
+        key: `${item.kind}:${item.id}`,
+        label: item.kind === 'symbol' ? `${item.name} (${item.filePath})` : item.filePath,
+        kind: item.kind,
+        score: item.score,
+        options: options
+          .filter((candidate, index) => index === 0 || candidate.tokens < options[0].tokens)
+          .map(({ representation, content, tokens }): PackOption<ContextItem> => ({ representation, tokens, value: { ...item, content, tokens } }))
+      };
+    });
+
+    const result = new ContextPacker().pack(candidates, maxTokens);
+    const packed = result.items.map(packedItem => ({
+      ...packedItem.value,
+      ...(packedItem.representation === 'full' ? {} : { representation: packedItem.representation })
+    }));
+
+    // Symbols already in a file included whole become part of it
+    for (const file of packed.filter(item => item.kind === 'file' && !item.representation)) {
+      for (const other of packed.filter(item => item.kind === 'symbol' && item.filePath === file.filePath)) {
+        packed.splice(packed.indexOf(other), 1);
+        file.reasons = [...file.reasons, ...other.reasons.filter(reason => !file.reasons.includes(reason)).map(reason => `${other.name}: ${reason}`)];
+      }
+    }
+
+    return {
+      items: packed,
+      totalTokens: packed.reduce((sum, item) => sum + item.tokens, 0),
+      degraded: result.degraded
+    };
+  }
+
+  private symbolInfo(symbolId: number): SymbolInfo | undefined {
+    return this.db.getDatabase().prepare(`
+      SELECT id, name, type, file_id as fileId, line_start as lineStart, line_end as lineEnd
+      FROM symbols WHERE id = ?
+    `).get(symbolId) as SymbolInfo | undefined;
+  }
+
+  private countTokens(text: string): number {
+    try {
+      return this.tokenEncoder.encode(text).length;
+    } catch {
+      return Math.ceil(text.length / 4);
+    }
+  }
+}
+
+type AddCandidate = (kind: Candidate['kind'], id: number, fileId: number, relevance: number, reason: string) => void;
+
+function round(value: number): number {
+  return Math.round(value * 1000) / 1000;
+}
\ No newline at end of file
@@ -0,0 +1,154 @@
+import type { DegradedItem, Representation } from '../types/index.js';
+
+export interface PackOption<T> {
+  representation: Representation;
+  tokens: number;
+  value: T;
+}
+
+export interface PackCandidate<T> {
+  key: string;
+  // Shown in the degradation report
+  label: string;
+  kind: 'file' | 'symbol';
+  score: number;
+  // Richest first, e.g. full -> excerpt -> signature -> path
+  options: PackOption<T>[];
+}
+
+export interface PackedItem<T> {
+  key: string;
+  score: number;
+  representation: Representation;
+  tokens: number;
+  value: T;
+}
+
+export interface PackResult<T> {
+  // Chosen items, best score first
+  items: PackedItem<T>[];
+  totalTokens: number;
+  degraded: DegradedItem[];
+}
+
+// How much of an item's worth survives in each representation
+const FIDELITY: Record<Representation, number> = {
+  full: 1,
+  excerpt: 0.6,
+  signature: 0.35,
+  path: 0.1
+};
+
+// Budgets are bucketed so the table stays small for large token limits
+const MAX_BUCKETS = 2000;
+
+/**
+ * Chooses which candidates to include, and in which representation, to get
+ * the most value out of a token budget (a multiple-choice knapsack). Items
+ * are degraded to cheaper representations before being dropped.
+ */
+export class ContextPacker {
+  public pack<T>(candidates: PackCandidate<T>[], maxTokens: number): PackResult<T> {
+    const ordered = [...candidates].sort((a, b) => b.score - a.score);
+    const unit = Math.max(1, Math.ceil(maxTokens / MAX_BUCKETS));
+    const capacity = Math.floor(maxTokens / unit);
+
+    // best[w]: most value using at most w buckets; choice[i][w]: option taken for item i (-1 = none)
+    let best = new Float64Array(capacity + 1);
+    const choice = ordered.map(() => new Int16Array(capacity + 1).fill(-1));
+
+    ordered.forEach((candidate, i) => {
+      const next = Float64Array.from(best);
+      candidate.options.forEach((option, o) => {
+        const cost = Math.ceil(option.tokens / unit);
+        const value = candidate.score * FIDELITY[option.representation];
+        for (let w = capacity; w >= cost; w--) {
+          if (best[w - cost] + value > next[w]) {
+            next[w] = best[w - cost] + value;
+            choice[i][w] = o;
+          }
+        }
+      });
+      best = next;
+    });
+
+    const items: PackedItem<T>[] = [];
+    const degraded: DegradedItem[] = [];
+    let w = capacity;
+    for (let i = ordered.length - 1; i >= 0; i--) {
+      const candidate = ordered[i];
+      const o = choice[i][w];
+      const richest = candidate.options[0]?.representation || 'full';
+      if (o === -1) {
+        if (candidate.options.length > 0) {
+          degraded.push({ kind: candidate.kind, name: candidate.label, from: richest, to: 'omitted' });
+        }
+        continue;
+      }
+
+      const option = candidate.options[o];
+      w -= Math.ceil(option.tokens / unit);
+      items.push({ key: candidate.key, score: candidate.score, ...option });
+      if (option.representation !== richest) {
+        degraded.push({ kind: candidate.kind, name: candidate.label, from: richest, to: option.representation });
+      }
+    }
+
+    items.reverse();
+    degraded.reverse();
+    return { items, totalTokens: items.reduce((sum, item) => sum + item.tokens, 0), degraded };
+  }
+}
+
+/**
+ * The given line ranges (1-based, inclusive) with `contextLines` around them,
+ * merged where they overlap and separated by `...`, capped at `maxLines`.
+ */
+export function buildExcerpt(content: string, ranges: { start: number; end: number }[], contextLines: number = 3, maxLines: number = 60): string {
+  const lines = content.split('\n');
+  const merged = mergeWindows(ranges, contextLines, lines.length);
+  if (merged.length === 0) {
+    merged.push({ start: 1, end: Math.min(lines.length, maxLines) });
+  }
+
+  const parts: string[] = [];
+  let remaining = maxLines;
+  let lastEnd = 0;
+  for (const window of merged) {
+    if (remaining <= 0) {
+      break;
+    }
+    const end = Math.min(window.end, window.start + remaining - 1);
+    if (window.start > lastEnd + 1) {
+      parts.push('...');
+    }
+    parts.push(lines.slice(window.start - 1, end).join('\n'));
+    remaining -= end - window.start + 1;
+    lastEnd = end;
+  }
+  if (lastEnd < lines.length) {
+    parts.push('...');
+  }
+  return parts.join('\n');
+}
+
+/** Line ranges widened by `contextLines` and merged where they overlap or touch, in line order. */
+export function mergeWindows(ranges: { start: number; end: number }[], contextLines: number, lineCount: number): { start: number; end: number }[] {
+  const windows = ranges
+    .map(range => ({
+      start: Math.max(1, range.start - contextLines),
+      end: Math.min(lineCount, range.end + contextLines)
+    }))
+    .sort((a, b) => a.start - b.start);
+
+  const merged: { start: number; end: number }[] = [];
+  for (const window of windows) {
+    const last = merged[merged.length - 1];
+    if (last && window.start <= last.end + 1) {
+      last.end = Math.max(last.end, window.end);
+    } else {
+      merged.push({ ...window });
+    }
+  }
+  return merged;
+}
\ No newline at end of file
@@ -0,0 +1,139 @@
+import { CONTAINER_TYPES } from '../utils/symbols.js';
+
+export interface SkeletonSymbol {
+  name: string;
+  type: string;
+  lineStart: number;
+  lineEnd: number;
+}
+
+// Declarations whose body is itself the shape, kept whole unless they hold other symbols
+const DECLARATION_TYPES = new Set(['interface', 'type', 'enum', 'struct']);
+// Lines that close a block and read better kept than elided
+const BLOCK_CLOSE = /^(?:[}\])]+[;,)]*|end\b.*)$/;
+// Signatures longer than this are treated as having no recognizable body opener
+const MAX_HEADER_LINES = 10;
+const DOCSTRING_OPEN = /^[rRbBuU]?("""|''')/;
+
+/**
+ * Renders a file as its outline: declarations and doc comments stay, function
+ * bodies become `...`. Works from stored symbol line ranges, so any extractor
+ * that records where a symbol ends gets a skeleton.
+ */
+export class SkeletonRenderer {
+  /** Skeleton of the whole file, or of the given line range (1-based, inclusive). */
+  public render(content: string, symbols: SkeletonSymbol[], range?: { start: number; end: number }): string {
+    const lines = content.split('\n');
+    const start = Math.max(1, range?.start || 1);
+    const end = Math.min(lines.length, range?.end || lines.length);
+
+    const ordered = symbols
+      .map(symbol => ({ ...symbol, lineEnd: Math.min(Math.max(symbol.lineEnd, symbol.lineStart), lines.length) }))
+      .filter(symbol => symbol.lineStart >= start && symbol.lineStart <= end)
+      .sort((a, b) => a.lineStart - b.lineStart || b.lineEnd - a.lineEnd);
+
+    // elided[line] holds the marker's indentation on the first elided line of a run
+    const elided = new Map<number, string>();
+    const hidden = new Set<number>();
+    for (const symbol of ordered) {
+      if (hidden.has(symbol.lineStart)) {
+        continue;
+      }
+      // Extractors disagree on where blocks end, so starting inside the range is enough
+      const nested = ordered.some(other => other !== symbol &&
+        other.lineStart > symbol.lineStart && other.lineStart <= symbol.lineEnd);
+      // Containers' members are rendered instead of being elided
+      if (nested && CONTAINER_TYPES.has(symbol.type)) {
+        continue;
+      }
+      if (!nested && DECLARATION_TYPES.has(symbol.type)) {
+        continue;
+      }
+
+      const body = this.bodyRange(lines, symbol);
+      if (!body) {
+        continue;
+      }
+      const indent = this.bodyIndent(lines, body.start, body.end);
+      for (let line = body.start; line <= body.end; line++) {
+        hidden.add(line);
+      }
+      elided.set(body.start, indent);
+    }
+
+    const output: string[] = [];
+    for (let line = start; line <= end; line++) {
+      if (elided.has(line)) {
+        output.push(`${elided.get(line)}...`);
+      }
+      if (hidden.has(line)) {
+        continue;
+      }
+      // Runs of blank lines collapse to one
+      if (lines[line - 1].trim() === '' && output.length > 0 && output[output.length - 1].trim() === '') {
+        continue;
+      }
+      output.push(lines[line - 1]);
+    }
+    return output.join('\n').trim();
+  }
+
+  /** Lines strictly inside a symbol's body, after its header, a leading docstring and before a closing line. */
+  private bodyRange(lines: string[], symbol: SkeletonSymbol): { start: number; end: number } | null {
+    // The header ends where the body opens; bodies without braces (Ruby, Lua) start on the next line
+    let headerEnd = symbol.lineStart;
+    for (let line = symbol.lineStart; line <= Math.min(symbol.lineEnd, symbol.lineStart + MAX_HEADER_LINES); line++) {
+      const code = lines[line - 1].replace(/\/\/.*$|#.*$/, '').trimEnd();
+      if (code.includes('{') || code.endsWith(':') || code.endsWith('=>')) {
+        headerEnd = line;
+        break;
+      }
+    }
+
+    let start = headerEnd + 1;
+    let end = symbol.lineEnd;
+    // Trailing blank lines, and comments some extractors count as part of the block, belong to what follows
+    const headerIndent = indentOf(lines[symbol.lineStart - 1]);
+    while (end > headerEnd && (lines[end - 1].trim() === '' ||
+      (indentOf(lines[end - 1]).length <= headerIndent.length && !BLOCK_CLOSE.test(lines[end - 1].trim())))) {
+      end--;
+    }
+    if (end > headerEnd && BLOCK_CLOSE.test(lines[end - 1].trim())) {
+      end--;
+    }
+
+    // Python docstrings are the function's documentation; keep them
+    const docstring = lines[start - 1]?.trim().match(DOCSTRING_OPEN);
+    if (docstring && start <= end) {
+      const quote = docstring[1];
+      let close = start;
+      const first = lines[start - 1].trim().replace(DOCSTRING_OPEN, '');
+      if (!first.includes(quote)) {
+        while (close < end && !lines[close].includes(quote)) {
+          close++;
+        }
+        close++;
+      }
+      start = close + 1;
+    }
+
+    // Nothing left worth hiding
+    if (end - start < 0 || lines.slice(start - 1, end).every(line => line.trim() === '')) {
+      return null;
+    }
+    return { start, end };
+  }
+
+  private bodyIndent(lines: string[], start: number, end: number): string {
+    for (let line = start; line <= end; line++) {
+      if (lines[line - 1].trim() !== '') {
+        return indentOf(lines[line - 1]);
+      }
+    }
+    return '  ';
+  }
+}
+
+function indentOf(line: string): string {
+  return line.match(/^\s*/)![0];
+}
\ No newline at end of file
@@ -1,4 +1,6 @@
-import { PrimordynDB } from '../index.js';
+import Database from 'better-sqlite3';
+import { PrimordynDB, SCHEMA_VERSION } from '../index.js';
+import { addFile, addSymbol } from '../../__tests__/helpers/db-fixtures.js';
 import { mkdirSync, rmSync, existsSync } from 'fs';
 import { join } from 'path';
 
@@ -43,6 +45,13 @@ describe('PrimordynDB', () => {
     expect(tableNames).toContain('call_graph');
   });
 
+  test('should use WAL journaling with a busy timeout', () => {
+    const database = db.getDatabase();
+
+    expect(database.pragma('journal_mode', { simple: true })).toBe('wal');
+    expect(database.pragma('busy_timeout', { simple: true })).toBeGreaterThan(0);
+  });
+
   test('should return database info', async () => {
     const info = await db.getDatabaseInfo();
     
@@ -117,4 +126,116 @@ describe('PrimordynDB', () => {
       .get() as { query_hash: string };
     expect(remaining.query_hash).toBe('valid_hash');
   });
+
+  test('should bump the index generation and drop cached results', () => {
+    const database = db.getDatabase();
+    expect(db.getIndexGeneration()).toBe(0);
+
+    database.prepare(`
+      INSERT INTO context_cache (query_hash, result, expires_at)
+      VALUES (?, ?, datetime('now', '+1 day'))
+    `).run('dep_graph_main@0', '{}');
+
+    expect(db.bumpIndexGeneration()).toBe(1);
+    expect(db.getIndexGeneration()).toBe(1);
+    expect(db.getCacheStats().entries).toBe(0);
+  });
+
+  test('should track cache statistics', () => {
+    db.incrementMeta('cache_hits');
+    db.incrementMeta('cache_hits');
+    db.incrementMeta('cache_misses');
+
+    const stats = db.getCacheStats();
+    expect(stats.hits).toBe(2);
+    expect(stats.misses).toBe(1);
+    expect(stats.hitRate).toBeCloseTo(2 / 3);
+  });
+
+  test('should only switch storage mode on an empty index', () => {
+    const database = db.getDatabase();
+    expect(db.getStorageMode()).toBe('full');
+
+    db.setStorageMode('none');
+    expect(db.getStorageMode()).toBe('none');
+
+    addFile(db, 'missing.ts');
+
+    expect(() => db.setStorageMode('full')).toThrow("run 'primordyn index --clear'");
+
+    // Content that can't be verified against disk resolves to empty rather than stale text
+    const row = database.prepare('SELECT content FROM files_with_content').get() as { content: string };
+    expect(row.content).toBe('');
+  });
+
+  test('should open an up-to-date index while another connection holds the write lock', () => {
+    const writer = new Database(db.getDatabasePath());
+    writer.exec('BEGIN IMMEDIATE');
+    try {
+      const reader = new PrimordynDB(testDir);
+      expect(reader.getDatabase().pragma('user_version', { simple: true })).toBe(SCHEMA_VERSION);
+      reader.close();
+    } finally {
+      writer.exec('ROLLBACK');
+      writer.close();
+    }
+  });
+
+  test('should create missing tables when opening an older index', () => {
+    const database = db.getDatabase();
+    database.exec('DROP TABLE index_meta');
+    database.pragma('user_version = 1');
+    db.close();
+
+    db = new PrimordynDB(testDir);
+    const reopened = db.getDatabase();
+    expect(reopened.prepare("SELECT name FROM sqlite_master WHERE name = 'index_meta'").get()).toBeTruthy();
+    expect(reopened.pragma('user_version', { simple: true })).toBe(SCHEMA_VERSION);
+  });
+
+  test('should rebuild the symbols FTS update trigger once when upgrading', () => {
+    const database = db.getDatabase();
+    database.exec(`
+      DROP TRIGGER symbols_fts_update;
+      CREATE TRIGGER symbols_fts_update AFTER UPDATE ON symbols BEGIN
+        DELETE FROM symbols_fts WHERE rowid = old.id;
+        INSERT INTO symbols_fts(rowid, name, signature, documentation)
+        VALUES (new.id, new.name, new.signature, new.documentation);
+      END;
+    `);
+    database.pragma('user_version = 9');
+    db.close();
+
+    db = new PrimordynDB(testDir);
+    const trigger = db.getDatabase().prepare("SELECT sql FROM sqlite_master WHERE name = 'symbols_fts_update'").get() as { sql: string };
+    expect(trigger.sql).toContain("VALUES ('delete', old.id");
+
+    const fileId = addFile(db, 'src/user.ts');
+    const symbolId = addSymbol(db, fileId, 'loadUser');
+    db.getDatabase().prepare("UPDATE symbols SET name = 'fetchUser' WHERE id = ?").run(symbolId);
+    const matches = (name: string) => db.getDatabase().prepare('SELECT rowid FROM symbols_fts WHERE symbols_fts MATCH ?').all(name);
+    expect(matches('fetchUser')).toHaveLength(1);
+    expect(matches('loadUser')).toHaveLength(0);
+  });
+
+  test('should rebuild the files FTS update trigger when upgrading', () => {
+    const database = db.getDatabase();
+    database.exec(`
+      DROP TRIGGER files_fts_update;
+      CREATE TRIGGER files_fts_update AFTER UPDATE ON files BEGIN
+        DELETE FROM files_fts WHERE rowid = old.id;
+        INSERT INTO files_fts(rowid, relative_path, content, language)
+        VALUES (new.id, new.relative_path, new.content, new.language);
+      END;
+    `);
+    database.pragma('user_version = 10');
+    db.close();
+
+    db = new PrimordynDB(testDir);
+    const fileId = addFile(db, 'src/user.ts', { content: 'export function loadUser() {}' });
+    db.getDatabase().prepare("UPDATE files SET content = 'export function fetchUser() {}' WHERE id = ?").run(fileId);
+    const matches = (term: string) => db.getDatabase().prepare('SELECT rowid FROM files_fts WHERE files_fts MATCH ?').all(term);
+    expect(matches('fetchUser')).toHaveLength(1);
+    expect(matches('loadUser')).toHaveLength(0);
+  });
 });
\ No newline at end of file
@@ -0,0 +1,77 @@
+import { IndexLock, IndexLockError } from '../lock.js';
+import { mkdirSync, rmSync, existsSync, writeFileSync } from 'fs';
+import { join } from 'path';
+import { hostname } from 'os';
+
+describe('IndexLock', () => {
+  const testDir = join(process.cwd(), '.test-primordyn-lock');
+
+  beforeEach(() => {
+    if (existsSync(testDir)) {
+      rmSync(testDir, { recursive: true, force: true });
+    }
+    mkdirSync(testDir, { recursive: true });
+  });
+
+  afterEach(() => {
+    if (existsSync(testDir)) {
+      rmSync(testDir, { recursive: true, force: true });
+    }
+  });
+
+  test('should acquire and release the lock', () => {
+    const lock = new IndexLock(testDir);
+    lock.acquire('index');
+
+    expect(lock.isHeld()).toBe(true);
+    expect(IndexLock.read(testDir)?.pid).toBe(process.pid);
+
+    lock.release();
+    expect(lock.isHeld()).toBe(false);
+    expect(IndexLock.read(testDir)).toBeNull();
+  });
+
+  test('should refuse a second writer while the lock is held', () => {
+    const first = new IndexLock(testDir);
+    first.acquire('index');
+
+    const second = new IndexLock(testDir);
+    expect(() => second.acquire('index')).toThrow(IndexLockError);
+
+    first.release();
+    expect(() => second.acquire('index')).not.toThrow();
+    second.release();
+  });
+
+  test('should report progress', () => {
+    const lock = new IndexLock(testDir);
+    lock.acquire('index');
+    lock.update(50, 100);
+
+    const info = IndexLock.read(testDir);
+    expect(info).not.toBeNull();
+    expect(IndexLock.progress(info!)).toBe(50);
+    expect(IndexLock.describe(info!)).toBe('index is being rebuilt (50% done)');
+
+    lock.release();
+  });
+
+  test('should reclaim a lock left by a dead process', () => {
+    const now = new Date().toISOString();
+    writeFileSync(join(testDir, 'index.lock'), JSON.stringify({
+      pid: 2147483646,
+      hostname: hostname(),
+      operation: 'index',
+      startedAt: now,
+      updatedAt: now,
+      processed: 3,
+      total: 10
+    }));
+
+    expect(IndexLock.read(testDir)).toBeNull();
+
+    const lock = new IndexLock(testDir);
+    expect(() => lock.acquire('index')).not.toThrow();
+    lock.release();
+  });
+});
\ No newline at end of file
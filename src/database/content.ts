@@ -0,0 +1,52 @@
+import { createHash } from 'crypto';
+import { existsSync, readFileSync } from 'fs';
+import { gzipSync, gunzipSync } from 'zlib';
+import type { StorageMode } from '../types/index.js';
+
+export const STORAGE_MODES: StorageMode[] = ['full', 'compressed', 'none'];
+
+export interface StoredContent {
+  content: string;
+  blob: Buffer | null;
+}
+
+/**
+ * Encode file content for the `files` table. Only `full` keeps plaintext in
+ * `files.content`; `compressed` moves it to `files.content_blob` and `none`
+ * keeps nothing, so snippets are read back from disk at query time.
+ */
+export function encodeContent(content: string, mode: StorageMode): StoredContent {
+  switch (mode) {
+    case 'compressed':
+      return { content: '', blob: gzipSync(content) };
+    case 'none':
+      return { content: '', blob: null };
+    default:
+      return { content, blob: null };
+  }
+}
+
+/**
+ * Resolve the text of an indexed file regardless of storage mode. Content
+ * read from disk is only returned when it still matches the indexed hash, so
+ * line numbers never point into an edited file; null means it is unavailable
+ * until the file is re-indexed.
+ */
+export function resolveContent(path: string, hash: string, content: string | null, blob: Buffer | Uint8Array | null): string | null {
+  if (blob) {
+    return gunzipSync(blob).toString('utf-8');
+  }
+  if (content) {
+    return content;
+  }
+
+  try {
+    if (!existsSync(path)) {
+      return null;
+    }
+    const onDisk = readFileSync(path, 'utf-8');
+    return createHash('sha256').update(onDisk).digest('hex') === hash ? onDisk : null;
+  } catch {
+    return null;
+  }
+}
\ No newline at end of file
@@ -1,28 +1,81 @@
 import Database from 'better-sqlite3';
 import { join } from 'path';
 import { existsSync, mkdirSync } from 'fs';
-import type { DatabaseInfo } from '../types/index.js';
+import { IndexLock } from './lock.js';
+import { resolveContent } from './content.js';
+import { levenshtein } from '../query/match.js';
+import { subwordText } from '../search/identifiers.js';
+import type { IndexLockInfo } from './lock.js';
+import type { DatabaseInfo, CacheStats, StorageMode } from '../types/index.js';
+
+// How long a connection waits on a locked database before raising SQLITE_BUSY
+const BUSY_TIMEOUT_MS = 5000;
+
+// Bump whenever tables or triggers change; stored in PRAGMA user_version and
+// checked when opening an index (to create or migrate it) and importing bundles
+export const SCHEMA_VERSION = 11;
 
 export class PrimordynDB {
   private db: Database.Database;
   private dbPath: string;
+  private dbDir: string;
+
+  private projectRoot: string;
 
   constructor(projectPath: string = process.cwd()) {
+    this.projectRoot = projectPath;
     const dbDir = join(projectPath, '.primordyn');
     
     if (!existsSync(dbDir)) {
       mkdirSync(dbDir, { recursive: true });
     }
 
+    this.dbDir = dbDir;
     this.dbPath = join(dbDir, 'context.db');
-    this.db = new Database(this.dbPath);
+    this.db = new Database(this.dbPath, { timeout: BUSY_TIMEOUT_MS });
     this.initializeSchema();
   }
 
   private initializeSchema(): void {
+    // WAL lets queries keep reading the last committed index while a writer is active
+    this.db.pragma('journal_mode = WAL');
+    this.db.pragma('synchronous = NORMAL');
+    this.db.pragma(`busy_timeout = ${BUSY_TIMEOUT_MS}`);
+
     // Enable foreign keys
     this.db.pragma('foreign_keys = ON');
-    
+
+    // Triggers below call these, so they must exist before any writes
+    this.registerFunctions();
+
+    // Only a new or older index is written to here: opening one that is up to
+    // date must not wait on an indexer holding the write lock
+    const version = this.db.pragma('user_version', { simple: true }) as number;
+    if (version !== SCHEMA_VERSION) {
+      this.db.transaction(() => this.createSchema(version))();
+    }
+
+    // Readers go through this view so they get file text whether it is stored
+    // verbatim, compressed, or only on disk
+    this.db.exec(`
+      CREATE TEMP VIEW IF NOT EXISTS files_with_content AS
+      SELECT id, path, relative_path, hash, size, language, last_modified, indexed_at, metadata,
+        primordyn_file_content(path, hash, content, content_blob) AS content
+      FROM main.files
+    `);
+  }
+
+  private createSchema(fromVersion: number): void {
+    // Before version 10 the update trigger removed FTS rows by rowid, which
+    // tokenizes the already updated symbol and corrupts the index
+    if (fromVersion > 0 && fromVersion < 10) {
+      this.db.exec('DROP TRIGGER IF EXISTS symbols_fts_update');
+    }
+    // The files trigger had the same flaw until version 11
+    if (fromVersion > 0 && fromVersion < 11) {
+      this.db.exec('DROP TRIGGER IF EXISTS files_fts_update');
+    }
+
     // Create tables
     this.db.exec(`
       CREATE TABLE IF NOT EXISTS files (
@@ -30,6 +83,7 @@ export class PrimordynDB {
         path TEXT UNIQUE NOT NULL,
         relative_path TEXT NOT NULL,
         content TEXT NOT NULL,
+        content_blob BLOB,
         hash TEXT NOT NULL,
         size INTEGER NOT NULL,
         language TEXT,
@@ -59,6 +113,12 @@ export class PrimordynDB {
         expires_at TIMESTAMP NOT NULL
       );
 
+      -- Key/value bookkeeping (index generation, cache counters, ...)
+      CREATE TABLE IF NOT EXISTS index_meta (
+        key TEXT PRIMARY KEY,
+        value TEXT NOT NULL
+      );
+
       -- Call relationships table for dependency graph
       CREATE TABLE IF NOT EXISTS call_graph (
         id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -67,7 +127,7 @@ export class PrimordynDB {
         callee_name TEXT NOT NULL,
         callee_symbol_id INTEGER,
         callee_file_id INTEGER,
-        call_type TEXT NOT NULL, -- 'function', 'method', 'constructor', 'import'
+        call_type TEXT NOT NULL, -- 'function', 'method', 'constructor', 'import', 'interface' (fanned out to an implementation)
         line_number INTEGER NOT NULL,
         column_number INTEGER,
         FOREIGN KEY (caller_symbol_id) REFERENCES symbols (id) ON DELETE CASCADE,
@@ -76,6 +136,92 @@ export class PrimordynDB {
         FOREIGN KEY (callee_file_id) REFERENCES files (id) ON DELETE SET NULL
       );
 
+      -- Supertype edges: type_name extends/implements/embeds related_name. Names
+      -- are bare (no generics or qualifiers) and resolved to symbols at query time
+      CREATE TABLE IF NOT EXISTS type_relations (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        file_id INTEGER NOT NULL,
+        symbol_id INTEGER,
+        type_name TEXT NOT NULL,
+        related_name TEXT NOT NULL,
+        kind TEXT NOT NULL, -- 'extends', 'implements', 'embeds'
+        line_number INTEGER NOT NULL,
+        source TEXT NOT NULL DEFAULT 'declared', -- 'declared' in source or 'structural' (Go method sets)
+        FOREIGN KEY (file_id) REFERENCES files (id) ON DELETE CASCADE,
+        FOREIGN KEY (symbol_id) REFERENCES symbols (id) ON DELETE CASCADE
+      );
+
+      -- Types named outside calls: annotations, generic arguments, parameter and
+      -- return types, supertype clauses. symbol_id is the symbol it appears in
+      CREATE TABLE IF NOT EXISTS symbol_references (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        file_id INTEGER NOT NULL,
+        symbol_id INTEGER,
+        name TEXT NOT NULL,
+        kind TEXT NOT NULL, -- 'annotation', 'generic', 'parameter', 'return', 'extends', 'implements', 'embeds'
+        line_number INTEGER NOT NULL,
+        FOREIGN KEY (file_id) REFERENCES files (id) ON DELETE CASCADE,
+        FOREIGN KEY (symbol_id) REFERENCES symbols (id) ON DELETE CASCADE
+      );
+
+      -- Every identifier outside comments and strings, with what it does there.
+      -- column_number is 0-based; symbol_id is the symbol it appears in
+      CREATE TABLE IF NOT EXISTS occurrences (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        file_id INTEGER NOT NULL,
+        symbol_id INTEGER,
+        name TEXT NOT NULL,
+        role TEXT NOT NULL, -- 'definition', 'call', 'type', 'import', 'write', 'read'
+        line_number INTEGER NOT NULL,
+        column_number INTEGER NOT NULL,
+        FOREIGN KEY (file_id) REFERENCES files (id) ON DELETE CASCADE,
+        FOREIGN KEY (symbol_id) REFERENCES symbols (id) ON DELETE CASCADE
+      );
+
+      -- Sparse term vectors for local semantic search; weights are 1 + ln(tf),
+      -- combined with semantic_terms.idf at query time
+      CREATE TABLE IF NOT EXISTS semantic_postings (
+        term TEXT NOT NULL,
+        symbol_id INTEGER NOT NULL,
+        weight REAL NOT NULL,
+        PRIMARY KEY (term, symbol_id),
+        FOREIGN KEY (symbol_id) REFERENCES symbols (id) ON DELETE CASCADE
+      ) WITHOUT ROWID;
+
+      CREATE TABLE IF NOT EXISTS semantic_terms (
+        term TEXT PRIMARY KEY,
+        idf REAL NOT NULL
+      ) WITHOUT ROWID;
+
+      CREATE TABLE IF NOT EXISTS semantic_norms (
+        symbol_id INTEGER PRIMARY KEY,
+        norm REAL NOT NULL,
+        FOREIGN KEY (symbol_id) REFERENCES symbols (id) ON DELETE CASCADE
+      );
+
+      -- Vectors from the configured embedding provider, keyed by a hash of the
+      -- embedded text so unchanged code is never sent twice
+      CREATE TABLE IF NOT EXISTS embedding_cache (
+        content_hash TEXT NOT NULL,
+        provider TEXT NOT NULL,
+        vector BLOB NOT NULL, -- float32 array
+        PRIMARY KEY (content_hash, provider)
+      ) WITHOUT ROWID;
+
+      -- Symbols and line windows of files that have an embedding
+      CREATE TABLE IF NOT EXISTS embedding_chunks (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        file_id INTEGER NOT NULL,
+        file_hash TEXT NOT NULL,
+        symbol_id INTEGER,
+        line_start INTEGER NOT NULL,
+        line_end INTEGER NOT NULL,
+        content_hash TEXT NOT NULL,
+        provider TEXT NOT NULL,
+        FOREIGN KEY (file_id) REFERENCES files (id) ON DELETE CASCADE,
+        FOREIGN KEY (symbol_id) REFERENCES symbols (id) ON DELETE CASCADE
+      );
+
       -- Create indexes for better performance
       CREATE INDEX IF NOT EXISTS idx_files_path ON files(path);
       CREATE INDEX IF NOT EXISTS idx_files_relative_path ON files(relative_path);
@@ -93,14 +239,19 @@ export class PrimordynDB {
       CREATE INDEX IF NOT EXISTS idx_call_graph_callee_name ON call_graph(callee_name);
       CREATE INDEX IF NOT EXISTS idx_call_graph_caller_file ON call_graph(caller_file_id);
       CREATE INDEX IF NOT EXISTS idx_call_graph_callee_file ON call_graph(callee_file_id);
+      CREATE INDEX IF NOT EXISTS idx_type_relations_type ON type_relations(type_name);
+      CREATE INDEX IF NOT EXISTS idx_type_relations_related ON type_relations(related_name);
+      CREATE INDEX IF NOT EXISTS idx_type_relations_file ON type_relations(file_id);
+      CREATE INDEX IF NOT EXISTS idx_symbol_references_name ON symbol_references(name);
+      CREATE INDEX IF NOT EXISTS idx_symbol_references_file ON symbol_references(file_id);
+      CREATE INDEX IF NOT EXISTS idx_occurrences_name ON occurrences(name);
+      CREATE INDEX IF NOT EXISTS idx_occurrences_file ON occurrences(file_id, line_number);
+      CREATE INDEX IF NOT EXISTS idx_semantic_postings_symbol ON semantic_postings(symbol_id);
+      CREATE INDEX IF NOT EXISTS idx_embedding_chunks_file ON embedding_chunks(file_id);
+      CREATE INDEX IF NOT EXISTS idx_embedding_chunks_symbol ON embedding_chunks(symbol_id);
+      CREATE INDEX IF NOT EXISTS idx_embedding_chunks_provider ON embedding_chunks(provider, content_hash);
 
       -- Full-text search indexes
-      CREATE VIRTUAL TABLE IF NOT EXISTS files_fts USING fts5(
-        relative_path, content, language,
-        content='files',
-        content_rowid='id'
-      );
-
       CREATE VIRTUAL TABLE IF NOT EXISTS symbols_fts USING fts5(
         name, signature, documentation,
         content='symbols', 
@@ -108,21 +259,6 @@ export class PrimordynDB {
       );
 
       -- Triggers to keep FTS in sync
-      CREATE TRIGGER IF NOT EXISTS files_fts_insert AFTER INSERT ON files BEGIN
-        INSERT INTO files_fts(rowid, relative_path, content, language) 
-        VALUES (new.id, new.relative_path, new.content, new.language);
-      END;
-
-      CREATE TRIGGER IF NOT EXISTS files_fts_delete AFTER DELETE ON files BEGIN
-        DELETE FROM files_fts WHERE rowid = old.id;
-      END;
-
-      CREATE TRIGGER IF NOT EXISTS files_fts_update AFTER UPDATE ON files BEGIN
-        DELETE FROM files_fts WHERE rowid = old.id;
-        INSERT INTO files_fts(rowid, relative_path, content, language) 
-        VALUES (new.id, new.relative_path, new.content, new.language);
-      END;
-
       CREATE TRIGGER IF NOT EXISTS symbols_fts_insert AFTER INSERT ON symbols BEGIN
         INSERT INTO symbols_fts(rowid, name, signature, documentation) 
         VALUES (new.id, new.name, new.signature, new.documentation);
@@ -132,12 +268,187 @@ export class PrimordynDB {
         DELETE FROM symbols_fts WHERE rowid = old.id;
       END;
 
+      -- The external content row already holds the new values, so the old
+      -- tokens are removed with the 'delete' command
       CREATE TRIGGER IF NOT EXISTS symbols_fts_update AFTER UPDATE ON symbols BEGIN
-        DELETE FROM symbols_fts WHERE rowid = old.id;
+        INSERT INTO symbols_fts(symbols_fts, rowid, name, signature, documentation)
+        VALUES ('delete', old.id, old.name, old.signature, old.documentation);
         INSERT INTO symbols_fts(rowid, name, signature, documentation) 
         VALUES (new.id, new.name, new.signature, new.documentation);
       END;
     `);
+
+    // Indexes created before content storage modes existed lack this column
+    const fileColumns = this.db.prepare("SELECT name FROM pragma_table_info('files')").all() as { name: string }[];
+    if (!fileColumns.some(column => column.name === 'content_blob')) {
+      this.db.exec('ALTER TABLE files ADD COLUMN content_blob BLOB');
+    }
+    const relationColumns = this.db.prepare("SELECT name FROM pragma_table_info('type_relations')").all() as { name: string }[];
+    if (!relationColumns.some(column => column.name === 'source')) {
+      this.db.exec("ALTER TABLE type_relations ADD COLUMN source TEXT NOT NULL DEFAULT 'declared'");
+    }
+
+    this.createFileSearchIndex(this.getStorageMode());
+
+    this.createSymbolSearchIndex();
+
+    this.db.pragma(`user_version = ${SCHEMA_VERSION}`);
+  }
+
+  private registerFunctions(): void {
+    this.db.function('primordyn_file_content', (path, hash, content, blob) =>
+      resolveContent(path as string, hash as string, content as string | null, blob as Buffer | null) ?? ''
+    );
+
+    this.db.function('primordyn_subwords', { deterministic: true }, (text) =>
+      subwordText(text as string | null)
+    );
+
+    // Used by the structured query compiler (path globs and fuzzy matching)
+    const regexCache = new Map<string, RegExp>();
+    this.db.function('regexp', { deterministic: true }, (pattern, value) => {
+      if (typeof pattern !== 'string' || typeof value !== 'string') {
+        return 0;
+      }
+      let regex = regexCache.get(pattern);
+      if (!regex) {
+        regex = new RegExp(pattern);
+        regexCache.set(pattern, regex);
+      }
+      return regex.test(value) ? 1 : 0;
+    });
+    this.db.function('levenshtein', { deterministic: true }, (a, b) =>
+      levenshtein(String(a ?? ''), String(b ?? ''))
+    );
+  }
+
+  /**
+   * Identifier-aware symbol search: names, signatures and docs split into
+   * subwords (getUserById -> get user by id), plus trigrams of names for
+   * substring matches.
+   */
+  private createSymbolSearchIndex(): void {
+    const exists = this.db.prepare(
+      "SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'symbol_subwords'"
+    ).get();
+
+    this.db.exec(`
+      CREATE VIRTUAL TABLE IF NOT EXISTS symbol_subwords USING fts5(
+        name, signature, documentation
+      );
+
+      CREATE VIRTUAL TABLE IF NOT EXISTS symbol_subwords_vocab USING fts5vocab(
+        symbol_subwords, 'row'
+      );
+
+      CREATE VIRTUAL TABLE IF NOT EXISTS symbol_trigrams USING fts5(
+        name,
+        content='symbols',
+        content_rowid='id',
+        tokenize='trigram'
+      );
+
+      CREATE TRIGGER IF NOT EXISTS symbol_search_insert AFTER INSERT ON symbols BEGIN
+        INSERT INTO symbol_subwords(rowid, name, signature, documentation)
+        VALUES (new.id, primordyn_subwords(new.name), primordyn_subwords(new.signature), primordyn_subwords(new.documentation));
+        INSERT INTO symbol_trigrams(rowid, name) VALUES (new.id, new.name);
+      END;
+
+      CREATE TRIGGER IF NOT EXISTS symbol_search_delete AFTER DELETE ON symbols BEGIN
+        DELETE FROM symbol_subwords WHERE rowid = old.id;
+        INSERT INTO symbol_trigrams(symbol_trigrams, rowid, name) VALUES ('delete', old.id, old.name);
+      END;
+
+      CREATE TRIGGER IF NOT EXISTS symbol_search_update AFTER UPDATE ON symbols BEGIN
+        DELETE FROM symbol_subwords WHERE rowid = old.id;
+        INSERT INTO symbol_subwords(rowid, name, signature, documentation)
+        VALUES (new.id, primordyn_subwords(new.name), primordyn_subwords(new.signature), primordyn_subwords(new.documentation));
+        INSERT INTO symbol_trigrams(symbol_trigrams, rowid, name) VALUES ('delete', old.id, old.name);
+        INSERT INTO symbol_trigrams(rowid, name) VALUES (new.id, new.name);
+      END;
+    `);
+
+    // Backfill indexes built before these tables existed
+    if (!exists) {
+      this.db.exec(`
+        INSERT INTO symbol_subwords(rowid, name, signature, documentation)
+        SELECT id, primordyn_subwords(name), primordyn_subwords(signature), primordyn_subwords(documentation) FROM symbols;
+        INSERT INTO symbol_trigrams(symbol_trigrams) VALUES ('rebuild');
+      `);
+    }
+  }
+
+  private createFileSearchIndex(mode: StorageMode): void {
+    if (mode === 'full') {
+      this.db.exec(`
+        CREATE VIRTUAL TABLE IF NOT EXISTS files_fts USING fts5(
+          relative_path, content, language,
+          content='files',
+          content_rowid='id'
+        );
+
+        CREATE TRIGGER IF NOT EXISTS files_fts_insert AFTER INSERT ON files BEGIN
+          INSERT INTO files_fts(rowid, relative_path, content, language) 
+          VALUES (new.id, new.relative_path, new.content, new.language);
+        END;
+
+        CREATE TRIGGER IF NOT EXISTS files_fts_delete AFTER DELETE ON files BEGIN
+          DELETE FROM files_fts WHERE rowid = old.id;
+        END;
+
+        -- Old tokens are removed with the 'delete' command, as for symbols_fts
+        CREATE TRIGGER IF NOT EXISTS files_fts_update AFTER UPDATE ON files BEGIN
+          INSERT INTO files_fts(files_fts, rowid, relative_path, content, language)
+          VALUES ('delete', old.id, old.relative_path, old.content, old.language);
+          INSERT INTO files_fts(rowid, relative_path, content, language) 
+          VALUES (new.id, new.relative_path, new.content, new.language);
+        END;
+      `);
+      return;
+    }
+
+    // Contentless: the index keeps tokens but no copy of the text. The indexer
+    // writes rows itself since files.content no longer holds the source.
+    this.db.exec(`
+      CREATE VIRTUAL TABLE IF NOT EXISTS files_fts USING fts5(
+        relative_path, content, language,
+        content='',
+        contentless_delete=1
+      );
+
+      CREATE TRIGGER IF NOT EXISTS files_fts_delete AFTER DELETE ON files BEGIN
+        DELETE FROM files_fts WHERE rowid = old.id;
+      END;
+    `);
+  }
+
+  public getStorageMode(): StorageMode {
+    return (this.getMeta('storage_mode') as StorageMode | null) || 'full';
+  }
+
+  /**
+   * Switch how file contents are stored. Only allowed on an empty index since
+   * the search index layout depends on it.
+   */
+  public setStorageMode(mode: StorageMode): void {
+    const current = this.getStorageMode();
+    if (mode === current) {
+      return;
+    }
+
+    const fileCount = (this.db.prepare('SELECT COUNT(*) as count FROM files').get() as { count: number }).count;
+    if (fileCount > 0) {
+      throw new Error(`Index was built with '${current}' content storage; run 'primordyn index --clear' to switch to '${mode}'`);
+    }
+
+    this.db.exec(`
+      DROP TRIGGER IF EXISTS files_fts_insert;
+      DROP TRIGGER IF EXISTS files_fts_update;
+      DROP TRIGGER IF EXISTS files_fts_delete;
+      DROP TABLE IF EXISTS files_fts;
+    `);
+    this.createFileSearchIndex(mode);
+    this.setMeta('storage_mode', mode);
   }
 
   public getDatabase(): Database.Database {
@@ -148,6 +459,26 @@ export class PrimordynDB {
     return this.dbPath;
   }
 
+  public getProjectRoot(): string {
+    return this.projectRoot;
+  }
+
+  public getSchemaVersion(): number {
+    return this.db.pragma('user_version', { simple: true }) as number;
+  }
+
+  public getDataDir(): string {
+    return this.dbDir;
+  }
+
+  public createIndexLock(): IndexLock {
+    return new IndexLock(this.dbDir);
+  }
+
+  public getActiveIndexLock(): IndexLockInfo | null {
+    return IndexLock.read(this.dbDir);
+  }
+
   public async getDatabaseInfo(): Promise<DatabaseInfo> {
     const fileCount = (this.db.prepare('SELECT COUNT(*) as count FROM files').get() as { count: number }).count;
     const symbolCount = (this.db.prepare('SELECT COUNT(*) as count FROM symbols').get() as { count: number }).count;
@@ -164,6 +495,60 @@ export class PrimordynDB {
     };
   }
 
+  public getMeta(key: string): string | null {
+    const row = this.db.prepare('SELECT value FROM index_meta WHERE key = ?').get(key) as { value: string } | undefined;
+    return row ? row.value : null;
+  }
+
+  public setMeta(key: string, value: string): void {
+    this.db.prepare(`
+      INSERT INTO index_meta (key, value) VALUES (?, ?)
+      ON CONFLICT(key) DO UPDATE SET value = excluded.value
+    `).run(key, value);
+  }
+
+  public incrementMeta(key: string, amount: number = 1): void {
+    this.db.prepare(`
+      INSERT INTO index_meta (key, value) VALUES (?, ?)
+      ON CONFLICT(key) DO UPDATE SET value = CAST(value AS INTEGER) + excluded.value
+    `).run(key, amount);
+  }
+
+  public getIndexGeneration(): number {
+    return parseInt(this.getMeta('index_generation') || '0', 10);
+  }
+
+  /**
+   * Advance the index generation after an index run. Cached results are keyed
+   * by generation, so everything computed against the old index is dropped.
+   */
+  public bumpIndexGeneration(): number {
+    const generation = this.getIndexGeneration() + 1;
+    this.setMeta('index_generation', String(generation));
+    this.db.prepare('DELETE FROM context_cache').run();
+    return generation;
+  }
+
+  public getCacheStats(): CacheStats {
+    const usage = this.db.prepare(`
+      SELECT COUNT(*) as entries, COALESCE(SUM(LENGTH(result)), 0) as size
+      FROM context_cache
+      WHERE expires_at > datetime('now')
+    `).get() as { entries: number; size: number };
+
+    const hits = parseInt(this.getMeta('cache_hits') || '0', 10);
+    const misses = parseInt(this.getMeta('cache_misses') || '0', 10);
+
+    return {
+      entries: usage.entries,
+      sizeBytes: usage.size,
+      hits,
+      misses,
+      hitRate: hits + misses > 0 ? hits / (hits + misses) : 0,
+      generation: this.getIndexGeneration()
+    };
+  }
+
   public cleanupExpiredCache(): void {
     this.db.prepare("DELETE FROM context_cache WHERE expires_at < datetime('now')").run();
   }
@@ -0,0 +1,177 @@
+import { join } from 'path';
+import { hostname } from 'os';
+import { openSync, writeSync, closeSync, readFileSync, writeFileSync, renameSync, unlinkSync, existsSync } from 'fs';
+
+export interface IndexLockInfo {
+  pid: number;
+  hostname: string;
+  operation: string;
+  startedAt: string;
+  updatedAt: string;
+  processed: number;
+  total: number;
+}
+
+export class IndexLockError extends Error {
+  public readonly holder: IndexLockInfo;
+
+  constructor(holder: IndexLockInfo) {
+    super(`Another ${holder.operation} is already running (pid ${holder.pid}, ${IndexLock.progress(holder)}% done)`);
+    this.name = 'IndexLockError';
+    this.holder = holder;
+  }
+}
+
+// Locks held by another host can't be checked with a pid probe, so fall back to
+// treating them as abandoned once they stop reporting progress for this long.
+const STALE_LOCK_MS = 10 * 60 * 1000;
+const UPDATE_INTERVAL_MS = 500;
+
+/**
+ * Advisory lock file that serializes writers (index, clear, import) on a
+ * .primordyn directory. Readers never take it; they only inspect it to report
+ * rebuild progress while WAL keeps serving them the last committed snapshot.
+ */
+export class IndexLock {
+  private lockPath: string;
+  private info: IndexLockInfo | null = null;
+  private lastWrite = 0;
+
+  constructor(dataDir: string) {
+    this.lockPath = join(dataDir, 'index.lock');
+  }
+
+  public static read(dataDir: string): IndexLockInfo | null {
+    const lockPath = join(dataDir, 'index.lock');
+    if (!existsSync(lockPath)) {
+      return null;
+    }
+
+    try {
+      const info = JSON.parse(readFileSync(lockPath, 'utf-8')) as IndexLockInfo;
+      return IndexLock.isStale(info) ? null : info;
+    } catch {
+      // Lock file vanished or is mid-write; treat as not locked
+      return null;
+    }
+  }
+
+  public static progress(info: IndexLockInfo): number {
+    if (info.total <= 0) {
+      return 0;
+    }
+    return Math.min(100, Math.floor((info.processed / info.total) * 100));
+  }
+
+  public static describe(info: IndexLockInfo): string {
+    return `index is being rebuilt (${IndexLock.progress(info)}% done)`;
+  }
+
+  private static isStale(info: IndexLockInfo): boolean {
+    if (info.hostname === hostname()) {
+      try {
+        process.kill(info.pid, 0);
+        return false;
+      } catch (error) {
+        // EPERM means the process exists but belongs to someone else
+        return !(error && typeof error === 'object' && 'code' in error && error.code === 'EPERM');
+      }
+    }
+    return Date.now() - new Date(info.updatedAt).getTime() > STALE_LOCK_MS;
+  }
+
+  public acquire(operation: string): void {
+    const now = new Date().toISOString();
+    const info: IndexLockInfo = {
+      pid: process.pid,
+      hostname: hostname(),
+      operation,
+      startedAt: now,
+      updatedAt: now,
+      processed: 0,
+      total: 0
+    };
+
+    for (let attempt = 0; attempt < 2; attempt++) {
+      try {
+        const fd = openSync(this.lockPath, 'wx');
+        writeSync(fd, JSON.stringify(info));
+        closeSync(fd);
+        this.info = info;
+        this.lastWrite = Date.now();
+        return;
+      } catch (error) {
+        if (!(error && typeof error === 'object' && 'code' in error && error.code === 'EEXIST')) {
+          throw error;
+        }
+
+        let holder: IndexLockInfo | null = null;
+        try {
+          holder = JSON.parse(readFileSync(this.lockPath, 'utf-8')) as IndexLockInfo;
+        } catch {
+          // Unreadable lock file: assume it was left half-written by a crash
+        }
+
+        if (holder && !IndexLock.isStale(holder)) {
+          throw new IndexLockError(holder);
+        }
+
+        // Reclaim the abandoned lock and try once more
+        try {
+          unlinkSync(this.lockPath);
+        } catch {
+          // Someone else may have reclaimed it first
+        }
+      }
+    }
+
+    throw new Error(`Could not acquire index lock at ${this.lockPath}`);
+  }
+
+  public update(processed: number, total: number): void {
+    if (!this.info) {
+      return;
+    }
+
+    const totalChanged = this.info.total !== total;
+    this.info.processed = processed;
+    this.info.total = total;
+
+    // Progress is only informational, so avoid rewriting the file for every file indexed
+    const now = Date.now();
+    if (!totalChanged && now - this.lastWrite < UPDATE_INTERVAL_MS && processed < total) {
+      return;
+    }
+    this.lastWrite = now;
+    this.info.updatedAt = new Date(now).toISOString();
+
+    // Write-then-rename so readers never observe a partially written file
+    const tempPath = `${this.lockPath}.${process.pid}.tmp`;
+    try {
+      writeFileSync(tempPath, JSON.stringify(this.info));
+      renameSync(tempPath, this.lockPath);
+    } catch {
+      // Progress reporting is best effort
+    }
+  }
+
+  public release(): void {
+    if (!this.info) {
+      return;
+    }
+
+    try {
+      const holder = JSON.parse(readFileSync(this.lockPath, 'utf-8')) as IndexLockInfo;
+      if (holder.pid === this.info.pid && holder.hostname === this.info.hostname) {
+        unlinkSync(this.lockPath);
+      }
+    } catch {
+      // Already gone
+    }
+    this.info = null;
+  }
+
+  public isHeld(): boolean {
+    return this.info !== null;
+  }
+}
\ No newline at end of file
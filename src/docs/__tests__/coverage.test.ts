@@ -0,0 +1,73 @@
+import { PrimordynDB } from '../../database/index.js';
+import { DocCoverageAnalyzer, meetsMinimum } from '../coverage.js';
+import { createTestDb, removeTestDir, addFile, addSymbol } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('DocCoverageAnalyzer', () => {
+  const testDir = join(process.cwd(), '.test-doc-coverage');
+  let db: PrimordynDB;
+
+  const file = (relativePath: string, language: string, exports: string[] = []) =>
+    addFile(db, relativePath, { language, metadata: { exports } });
+  const symbol = (fileId: number, name: string, type: string, lines: [number, number], signature: string, documentation?: string) =>
+    addSymbol(db, fileId, name, { type, lines, signature, documentation });
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+
+    const store = file('src/store.ts', 'typescript', ['Store']);
+    symbol(store, 'Store', 'class', [1, 30], 'export class Store', 'Keeps records.');
+    symbol(store, 'save', 'method', [3, 10], 'save(record: Record)');
+    symbol(store, 'flush', 'method', [12, 14], 'private flush()');
+    symbol(store, 'helper', 'function', [32, 40], 'function helper()');
+
+    const server = file('pkg/server/server.go', 'go');
+    symbol(server, 'Serve', 'function', [5, 25], 'func Serve()', 'Serve starts the server.');
+    symbol(server, 'handle', 'function', [27, 30], 'func handle()');
+
+    const lib = file('src/lib.rs', 'rust');
+    symbol(lib, 'parse', 'function', [1, 12], 'pub fn parse()');
+    symbol(lib, 'inner', 'function', [14, 16], 'fn inner()');
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('counts only public symbols by each language\'s rules', () => {
+    const report = new DocCoverageAnalyzer(db).analyze();
+
+    // Store, save, Serve and parse are public; flush, helper, handle and inner aren't
+    expect(report.total).toBe(4);
+    expect(report.documented).toBe(2);
+    expect(report.coverage).toBe(50);
+    expect(report.undocumented.map(symbol => symbol.name)).toEqual(['parse', 'save']);
+  });
+
+  test('breaks coverage down by language, directory and file', () => {
+    const report = new DocCoverageAnalyzer(db).analyze();
+
+    expect(report.languages.find(language => language.name === 'go')?.coverage).toBe(100);
+    expect(report.directories.map(directory => `${directory.name} ${directory.documented}/${directory.total}`))
+      .toEqual(['pkg 1/1', 'pkg/server 1/1', 'src 1/3']);
+    expect(report.files.find(file => file.name === 'src/lib.rs')?.coverage).toBe(0);
+  });
+
+  test('filters by language', () => {
+    const report = new DocCoverageAnalyzer(db).analyze({ fileTypes: ['go'] });
+    expect(report.total).toBe(1);
+    expect(report.files.map(file => file.name)).toEqual(['pkg/server/server.go']);
+  });
+
+  test('fails a minimum when there are no public symbols to measure', () => {
+    const analyzer = new DocCoverageAnalyzer(db);
+
+    expect(meetsMinimum(analyzer.analyze(), 50)).toBe(true);
+    expect(meetsMinimum(analyzer.analyze(), 60)).toBe(false);
+
+    const empty = analyzer.analyze({ fileTypes: ['python'] });
+    expect(empty.total).toBe(0);
+    expect(() => meetsMinimum(empty, 0)).toThrow('No public symbols found');
+  });
+});
\ No newline at end of file
@@ -0,0 +1,114 @@
+import { PrimordynDB } from '../../database/index.js';
+import { ProjectContextGenerator, mergeGeneratedSections } from '../project-context.js';
+import { detectCommands } from '../manifests.js';
+import { createTestDb, removeTestDir, addFile, addSymbol, addCall } from '../../__tests__/helpers/db-fixtures.js';
+import { mkdirSync, writeFileSync } from 'fs';
+import { join } from 'path';
+
+describe('mergeGeneratedSections', () => {
+  const sections = [
+    { id: 'overview', content: '## Overview\n\nnew overview' },
+    { id: 'commands', content: '## Commands\n\n- test: `npm test`' }
+  ];
+
+  test('creates a file with every section when there is none', () => {
+    const created = mergeGeneratedSections(null, sections);
+    expect(created).toContain('<!-- primordyn:begin overview -->\n## Overview\n\nnew overview\n<!-- primordyn:end overview -->');
+    expect(created).toContain('<!-- primordyn:begin commands -->');
+  });
+
+  test('replaces only the marked blocks and keeps hand-written text', () => {
+    const existing = [
+      '# My project',
+      '',
+      'Written by hand.',
+      '',
+      '<!-- primordyn:begin overview -->',
+      'old overview',
+      '<!-- primordyn:end overview -->',
+      '',
+      '## Notes',
+      'Keep this.'
+    ].join('\n');
+
+    const merged = mergeGeneratedSections(existing, sections);
+    expect(merged).toContain('Written by hand.');
+    expect(merged).toContain('## Notes\nKeep this.');
+    expect(merged).toContain('new overview');
+    expect(merged).not.toContain('old overview');
+    // Sections without markers yet are appended
+    expect(merged.trimEnd().endsWith('<!-- primordyn:end commands -->')).toBe(true);
+
+    // Refreshing again changes nothing, so --check passes
+    expect(mergeGeneratedSections(merged, sections)).toBe(merged);
+  });
+});
+
+describe('ProjectContextGenerator', () => {
+  const testDir = join(process.cwd(), '.test-project-context');
+  let db: PrimordynDB;
+
+  // The index of a small project where save's body has `bodyLines` lines and main calls it `calls` times
+  const index = (bodyLines: number, calls: number) => {
+    db = createTestDb(testDir);
+    const source = [
+      'export class Store {',
+      '  save(key: string) {',
+      ...Array.from({ length: bodyLines }, (_, line) => `    check(key, ${line});`),
+      '  }',
+      '}'
+    ].join('\n');
+    const store = addFile(db, 'src/store.ts', { content: source, metadata: { exports: ['Store'] } });
+    const app = addFile(db, 'src/app.ts', { content: 'export function main() {}', metadata: { exports: ['main'] } });
+    addSymbol(db, store, 'Store', { type: 'class', lines: [1, bodyLines + 4] });
+    const save = addSymbol(db, store, 'save', { type: 'method', lines: [2, bodyLines + 3] });
+    const main = addSymbol(db, app, 'main', { lines: [1, calls + 2] });
+    for (let call = 0; call < calls; call++) {
+      addCall(db, { callerSymbolId: main, callerFileId: app, calleeName: 'save', calleeSymbolId: save, calleeFileId: store, line: call + 2 });
+    }
+    return new ProjectContextGenerator(db, testDir);
+  };
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('leaves --check clean after an edit inside a function body', () => {
+    const first = index(1, 1).render(null);
+    expect(first).toContain('- save (method) in src/store.ts');
+    db.close();
+
+    // save grows by three lines and main calls it once more; no declarations change
+    expect(index(4, 2).render(first)).toBe(first);
+  });
+});
+
+describe('detectCommands', () => {
+  const testDir = join(process.cwd(), '.test-manifests');
+
+  beforeEach(() => {
+    removeTestDir(testDir);
+    mkdirSync(testDir, { recursive: true });
+  });
+
+  afterEach(() => {
+    removeTestDir(testDir);
+  });
+
+  test('reads package.json scripts with the lockfile\'s package manager', () => {
+    writeFileSync(join(testDir, 'package.json'), JSON.stringify({ scripts: { build: 'tsc', test: 'jest', clean: 'rm -rf dist' } }));
+    writeFileSync(join(testDir, 'pnpm-lock.yaml'), '');
+
+    expect(detectCommands(testDir).map(command => command.command)).toEqual(['pnpm install', 'pnpm run build', 'pnpm test']);
+  });
+
+  test('knows the standard commands of Go modules and Makefiles', () => {
+    writeFileSync(join(testDir, 'go.mod'), 'module example.com/app');
+    writeFileSync(join(testDir, 'Makefile'), 'build:\n\tgo build\n\nrelease:\n\t./release.sh\n');
+
+    const commands = detectCommands(testDir);
+    expect(commands.map(command => command.command)).toEqual(['make build', 'go build ./...', 'go test ./...', 'go vet ./...']);
+    expect(commands[0].source).toBe('Makefile');
+  });
+});
\ No newline at end of file
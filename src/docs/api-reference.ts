@@ -0,0 +1,347 @@
+import { createHash } from 'crypto';
+import { existsSync, mkdirSync, readFileSync, rmSync, writeFileSync } from 'fs';
+import { dirname, join, posix } from 'path';
+import { PrimordynDB } from '../database/index.js';
+import { loadConfig } from '../config/index.js';
+import { SecretRedactor } from '../security/redactor.js';
+import { CONTAINER_TYPES } from '../utils/symbols.js';
+import type { ApiReferenceStats } from '../types/index.js';
+
+export interface ApiReferenceOptions {
+  outDir: string;
+  format?: 'md' | 'html';
+  // e.g. https://github.com/org/repo/blob/main; source links are relative to the pages otherwise
+  sourceUrl?: string;
+  // Project root the indexed relative paths start from
+  root?: string;
+  fileTypes?: string[];
+}
+
+interface ModuleRow {
+  id: number;
+  relativePath: string;
+  language: string | null;
+  hash: string;
+  metadata: string | null;
+}
+
+interface SymbolRow {
+  id: number;
+  name: string;
+  type: string;
+  lineStart: number;
+  lineEnd: number;
+  signature: string | null;
+  documentation: string | null;
+}
+
+interface ApiLink {
+  label: string;
+  href?: string;
+}
+
+interface ApiSymbol {
+  anchor: string;
+  name: string;
+  type: string;
+  depth: number;
+  parent?: string;
+  signature?: string;
+  documentation?: string;
+  source: string;
+  line: number;
+  calls: ApiLink[];
+  calledBy: ApiLink[];
+}
+
+interface ApiPage {
+  module: string;
+  language: string | null;
+  source: string;
+  exports: string[];
+  symbols: ApiSymbol[];
+}
+
+// Bump when page layout changes so every page is rewritten once
+const GENERATOR_VERSION = 2;
+const MANIFEST_FILE = '.primordyn-api.json';
+// Symbol kinds documented on a module page; locals and imports are noise here
+const DOCUMENTED_TYPES = new Set(['function', 'class', 'interface', 'type', 'method', 'struct', 'enum', 'trait', 'constant', 'namespace', 'module', 'property']);
+const MAX_LINKS = 20;
+
+/**
+ * Static API reference pages, one per indexed module, built from symbols,
+ * their docs and the call graph. Pages are only rewritten when the module or
+ * a module it links to has changed since the last run.
+ */
+export class ApiReferenceGenerator {
+  private db: PrimordynDB;
+  private options: Required<Omit<ApiReferenceOptions, 'sourceUrl' | 'fileTypes'>> & Pick<ApiReferenceOptions, 'sourceUrl' | 'fileTypes'>;
+  private redactor: SecretRedactor;
+
+  constructor(db: PrimordynDB, options: ApiReferenceOptions) {
+    this.db = db;
+    this.options = { format: 'md', root: process.cwd(), ...options };
+    this.redactor = new SecretRedactor(loadConfig(db.getProjectRoot()).redaction);
+  }
+
+  public generate(): ApiReferenceStats {
+    const database = this.db.getDatabase();
+    const params = this.options.fileTypes ?? [];
+    const modules = database.prepare(`
+      SELECT id, relative_path as relativePath, language, hash, metadata
+      FROM files
+      WHERE id IN (SELECT file_id FROM symbols)
+      ${params.length ? `AND language IN (${params.map(() => '?').join(',')})` : ''}
+      ORDER BY relative_path
+    `).all(...params) as ModuleRow[];
+
+    const manifestPath = join(this.options.outDir, MANIFEST_FILE);
+    const previous: Record<string, string> = existsSync(manifestPath) ? JSON.parse(readFileSync(manifestPath, 'utf8')) : {};
+    const manifest: Record<string, string> = {};
+    const stats: ApiReferenceStats = { outDir: this.options.outDir, pages: modules.length, written: 0, unchanged: 0, removed: 0 };
+
+    // Files this module's pages link to; their changes move anchors and labels on this page
+    const neighbours = database.prepare(`
+      SELECT DISTINCT f.hash FROM call_graph c JOIN files f ON f.id = c.callee_file_id WHERE c.caller_file_id = ?
+      UNION
+      SELECT DISTINCT f.hash FROM call_graph c JOIN files f ON f.id = c.caller_file_id WHERE c.callee_file_id = ?
+    `);
+
+    for (const module of modules) {
+      const linked = (neighbours.all(module.id, module.id) as { hash: string }[]).map(row => row.hash).sort();
+      const key = createHash('sha256')
+        .update(JSON.stringify([GENERATOR_VERSION, this.options.format, this.options.sourceUrl ?? '', module.hash, linked]))
+        .digest('hex');
+      const pagePath = join(this.options.outDir, this.pageFile(module.relativePath));
+      manifest[module.relativePath] = key;
+
+      if (previous[module.relativePath] === key && existsSync(pagePath)) {
+        stats.unchanged++;
+        continue;
+      }
+
+      const page = this.buildPage(module);
+      mkdirSync(dirname(pagePath), { recursive: true });
+      writeFileSync(pagePath, this.options.format === 'html' ? renderHtml(page) : renderMarkdown(page));
+      stats.written++;
+    }
+
+    // Pages of modules that are no longer indexed
+    for (const module of Object.keys(previous)) {
+      if (!(module in manifest)) {
+        rmSync(join(this.options.outDir, this.pageFile(module)), { force: true });
+        stats.removed++;
+      }
+    }
+
+    const indexFile = join(this.options.outDir, `index.${this.options.format}`);
+    mkdirSync(this.options.outDir, { recursive: true });
+    writeFileSync(indexFile, this.renderIndex(modules));
+    writeFileSync(manifestPath, JSON.stringify(manifest, null, 2));
+    return stats;
+  }
+
+  private buildPage(module: ModuleRow): ApiPage {
+    const database = this.db.getDatabase();
+    const symbols = (database.prepare(`
+      SELECT id, name, type, line_start as lineStart, line_end as lineEnd, signature, documentation
+      FROM symbols WHERE file_id = ? ORDER BY line_start, line_end DESC
+    `).all(module.id) as SymbolRow[]).filter(symbol => DOCUMENTED_TYPES.has(symbol.type));
+
+    const calls = database.prepare(`
+      SELECT DISTINCT c.callee_name as name, s.name as symbolName, s.line_start as line, f.relative_path as filePath
+      FROM call_graph c
+      LEFT JOIN symbols s ON s.id = c.callee_symbol_id
+      LEFT JOIN files f ON f.id = c.callee_file_id
+      WHERE c.caller_symbol_id = ?
+      ORDER BY c.line_number
+    `);
+    const calledBy = database.prepare(`
+      SELECT DISTINCT s.name as symbolName, s.line_start as line, f.relative_path as filePath, c.line_number as callLine
+      FROM call_graph c
+      LEFT JOIN symbols s ON s.id = c.caller_symbol_id
+      JOIN files f ON f.id = c.caller_file_id
+      WHERE c.callee_symbol_id = ?
+      ORDER BY f.relative_path, c.line_number
+    `);
+
+    const page: ApiPage = {
+      module: module.relativePath,
+      language: module.language,
+      source: this.sourceLink(module.relativePath, module.relativePath),
+      exports: module.metadata ? JSON.parse(module.metadata).exports || [] : [],
+      symbols: []
+    };
+
+    const open: SymbolRow[] = [];
+    for (const symbol of symbols) {
+      // Containers still open at this line are the symbol's ancestors
+      while (open.length > 0 && open[open.length - 1].lineEnd < symbol.lineStart) {
+        open.pop();
+      }
+      const parent = open[open.length - 1];
+
+      const callLinks = (calls.all(symbol.id) as { name: string; symbolName: string | null; line: number | null; filePath: string | null }[])
+        .map(call => call.symbolName && call.filePath
+          ? { label: call.symbolName, href: this.pageLink(module.relativePath, call.filePath, anchorFor(call.symbolName, call.line!)) }
+          : { label: call.name });
+      const callerLinks = (calledBy.all(symbol.id) as { symbolName: string | null; line: number | null; filePath: string; callLine: number }[])
+        .map(caller => caller.symbolName
+          ? { label: `${caller.symbolName} (${caller.filePath})`, href: this.pageLink(module.relativePath, caller.filePath, anchorFor(caller.symbolName, caller.line!)) }
+          : { label: `${caller.filePath}:${caller.callLine}`, href: this.sourceLink(module.relativePath, caller.filePath, caller.callLine) });
+
+      page.symbols.push({
+        anchor: anchorFor(symbol.name, symbol.lineStart),
+        name: symbol.name,
+        type: symbol.type,
+        depth: open.length,
+        parent: parent?.name,
+        // Pages get published, so they are redacted like query output
+        signature: symbol.signature ? this.redactor.redact(symbol.signature) : undefined,
+        documentation: symbol.documentation ? this.redactor.redact(symbol.documentation) : undefined,
+        source: this.sourceLink(module.relativePath, module.relativePath, symbol.lineStart),
+        line: symbol.lineStart,
+        calls: dedupe(callLinks),
+        calledBy: dedupe(callerLinks)
+      });
+
+      if (CONTAINER_TYPES.has(symbol.type)) {
+        open.push(symbol);
+      }
+    }
+    return page;
+  }
+
+  private renderIndex(modules: ModuleRow[]): string {
+    const links = modules.map(module => ({ label: module.relativePath, href: this.pageFile(module.relativePath) }));
+    if (this.options.format === 'html') {
+      return htmlDocument('API reference', `<h1>API reference</h1>\n<ul>\n${links.map(link =>
+        `<li><a href="${escapeHtml(link.href)}">${escapeHtml(link.label)}</a></li>`).join('\n')}\n</ul>`);
+    }
+    return `# API reference\n\n${links.map(link => `- [${link.label}](${encodeURI(link.href)})`).join('\n')}\n`;
+  }
+
+  private pageFile(modulePath: string): string {
+    return `${modulePath}.${this.options.format}`;
+  }
+
+  // Link from one module's page to another's, relative so the output directory can move
+  private pageLink(fromModule: string, toModule: string, anchor: string): string {
+    const target = fromModule === toModule ? '' : posix.relative(posix.dirname(fromModule), this.pageFile(toModule));
+    return `${target}#${anchor}`;
+  }
+
+  private sourceLink(fromModule: string, filePath: string, line?: number): string {
+    const fragment = line ? `#L${line}` : '';
+    if (this.options.sourceUrl) {
+      return `${this.options.sourceUrl.replace(/\/+$/, '')}/${filePath}${fragment}`;
+    }
+    // From <outDir>/<module dir>/ back up to the project root
+    const pageDir = posix.join(this.options.outDir.split('\\').join('/'), posix.dirname(fromModule));
+    const root = this.options.root.split('\\').join('/');
+    const fromDir = posix.isAbsolute(pageDir) ? pageDir : posix.join(root, pageDir);
+    return `${posix.relative(fromDir, posix.join(root, filePath))}${fragment}`;
+  }
+}
+
+function anchorFor(name: string, line: number): string {
+  return `${name.toLowerCase().replace(/[^a-z0-9]+/g, '-').replace(/^-|-$/g, '')}-l${line}`;
+}
+
+function dedupe(links: ApiLink[]): ApiLink[] {
+  const seen = new Set<string>();
+  return links.filter(link => {
+    const key = `${link.label}|${link.href ?? ''}`;
+    if (seen.has(key)) {
+      return false;
+    }
+    seen.add(key);
+    return true;
+  }).slice(0, MAX_LINKS);
+}
+
+function markdownLink(link: ApiLink): string {
+  return link.href ? `[${link.label}](${encodeURI(link.href)})` : `\`${link.label}\``;
+}
+
+function renderMarkdown(page: ApiPage): string {
+  const lines = [`# ${page.module}`, '', `${page.language || 'unknown language'} · [source](${encodeURI(page.source)})`];
+  if (page.exports.length > 0) {
+    lines.push('', `**Exports:** ${page.exports.map(name => `\`${name}\``).join(', ')}`);
+  }
+
+  for (const symbol of page.symbols) {
+    const level = '#'.repeat(Math.min(2 + symbol.depth, 6));
+    lines.push('', `<a id="${symbol.anchor}"></a>`, `${level} ${symbol.name}`, '');
+    lines.push(`*${symbol.type}*${symbol.parent ? ` in \`${symbol.parent}\`` : ''} · [line ${symbol.line}](${encodeURI(symbol.source)})`);
+    if (symbol.signature) {
+      lines.push('', '```' + (page.language || ''), symbol.signature, '```');
+    }
+    if (symbol.documentation) {
+      lines.push('', symbol.documentation);
+    }
+    if (symbol.calls.length > 0) {
+      lines.push('', `**Calls:** ${symbol.calls.map(markdownLink).join(', ')}`);
+    }
+    if (symbol.calledBy.length > 0) {
+      lines.push('', `**Called by:** ${symbol.calledBy.map(markdownLink).join(', ')}`);
+    }
+  }
+  return lines.join('\n') + '\n';
+}
+
+function renderHtml(page: ApiPage): string {
+  const link = (target: ApiLink) => target.href
+    ? `<a href="${escapeHtml(target.href)}">${escapeHtml(target.label)}</a>`
+    : `<code>${escapeHtml(target.label)}</code>`;
+
+  const body = [`<h1>${escapeHtml(page.module)}</h1>`,
+    `<p class="meta">${escapeHtml(page.language || 'unknown language')} · <a href="${escapeHtml(page.source)}">source</a></p>`];
+  if (page.exports.length > 0) {
+    body.push(`<p><strong>Exports:</strong> ${page.exports.map(name => `<code>${escapeHtml(name)}</code>`).join(', ')}</p>`);
+  }
+  for (const symbol of page.symbols) {
+    const level = Math.min(2 + symbol.depth, 6);
+    body.push(`<section id="${symbol.anchor}" style="margin-left:${symbol.depth * 1.5}em">`);
+    body.push(`<h${level}>${escapeHtml(symbol.name)}</h${level}>`);
+    body.push(`<p class="meta"><em>${escapeHtml(symbol.type)}</em>${symbol.parent ? ` in <code>${escapeHtml(symbol.parent)}</code>` : ''} · <a href="${escapeHtml(symbol.source)}">line ${symbol.line}</a></p>`);
+    if (symbol.signature) {
+      body.push(`<pre><code>${escapeHtml(symbol.signature)}</code></pre>`);
+    }
+    if (symbol.documentation) {
+      body.push(`<p>${escapeHtml(symbol.documentation).replace(/\n\n/g, '</p><p>')}</p>`);
+    }
+    if (symbol.calls.length > 0) {
+      body.push(`<p><strong>Calls:</strong> ${symbol.calls.map(link).join(', ')}</p>`);
+    }
+    if (symbol.calledBy.length > 0) {
+      body.push(`<p><strong>Called by:</strong> ${symbol.calledBy.map(link).join(', ')}</p>`);
+    }
+    body.push('</section>');
+  }
+  return htmlDocument(page.module, body.join('\n'));
+}
+
+function htmlDocument(title: string, body: string): string {
+  return `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>${escapeHtml(title)}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+pre { background: #f5f5f5; padding: 0.75em; overflow-x: auto; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+${body}
+</body>
+</html>
+`;
+}
+
+function escapeHtml(text: string): string {
+  return text.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
\ No newline at end of file
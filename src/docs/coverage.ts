@@ -0,0 +1,185 @@
+import { posix } from 'path';
+import { PrimordynDB } from '../database/index.js';
+import { isPublicName } from '../map/index.js';
+import { CONTAINER_TYPES } from '../utils/symbols.js';
+import type { CoverageBucket, DocCoverageReport, UndocumentedSymbol } from '../types/index.js';
+
+export interface DocCoverageAnalyzerOptions {
+  fileTypes?: string[];
+  // Undocumented symbols to list
+  limit?: number;
+}
+
+interface FileRow {
+  id: number;
+  relativePath: string;
+  language: string | null;
+  metadata: string | null;
+}
+
+interface SymbolRow {
+  name: string;
+  type: string;
+  lineStart: number;
+  lineEnd: number;
+  signature: string | null;
+  documentation: string | null;
+}
+
+// Symbol kinds that are expected to carry docs when public
+const DOCUMENTABLE_TYPES = new Set(['function', 'class', 'interface', 'type', 'method', 'struct', 'enum', 'trait', 'constant', 'namespace', 'module']);
+// ES modules expose only what they export
+const EXPORT_LIST_LANGUAGES = new Set(['typescript', 'javascript']);
+
+/**
+ * How much of the public surface has doc comments, overall and per
+ * language, directory and file. Public follows each language's rules: export
+ * lists, Go capitalisation, Rust `pub`, access modifiers and `_` prefixes.
+ */
+export class DocCoverageAnalyzer {
+  private db: PrimordynDB;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+  }
+
+  public analyze(options: DocCoverageAnalyzerOptions = {}): DocCoverageReport {
+    const database = this.db.getDatabase();
+    const params = options.fileTypes ?? [];
+    const files = database.prepare(`
+      SELECT id, relative_path as relativePath, language, metadata
+      FROM files
+      ${params.length ? `WHERE language IN (${params.map(() => '?').join(',')})` : ''}
+      ORDER BY relative_path
+    `).all(...params) as FileRow[];
+    const symbolsOf = database.prepare(`
+      SELECT name, type, line_start as lineStart, line_end as lineEnd, signature, documentation
+      FROM symbols WHERE file_id = ? ORDER BY line_start, line_end DESC
+    `);
+
+    const languages = new Map<string, CoverageBucket>();
+    const directories = new Map<string, CoverageBucket>();
+    const fileBuckets: CoverageBucket[] = [];
+    const undocumented: UndocumentedSymbol[] = [];
+    const totals = bucket('total');
+
+    for (const file of files) {
+      const publicSymbols = this.publicSymbols(file, symbolsOf.all(file.id) as SymbolRow[]);
+      if (publicSymbols.length === 0) {
+        continue;
+      }
+
+      const fileBucket = bucket(file.relativePath);
+      const targets = [totals, fileBucket, lookup(languages, file.language || 'unknown')];
+      // Each directory counts everything beneath it
+      for (let directory = posix.dirname(file.relativePath); directory !== '.'; directory = posix.dirname(directory)) {
+        targets.push(lookup(directories, directory));
+      }
+
+      for (const symbol of publicSymbols) {
+        const documented = Boolean(symbol.documentation?.trim());
+        for (const target of targets) {
+          target.total++;
+          if (documented) {
+            target.documented++;
+          }
+        }
+        if (!documented) {
+          undocumented.push({
+            name: symbol.name,
+            type: symbol.type,
+            filePath: file.relativePath,
+            line: symbol.lineStart,
+            lines: symbol.lineEnd - symbol.lineStart + 1
+          });
+        }
+      }
+      fileBuckets.push(fileBucket);
+    }
+
+    const finish = (buckets: Iterable<CoverageBucket>) => [...buckets].map(withCoverage);
+    const summary = withCoverage(totals);
+    return {
+      total: summary.total,
+      documented: summary.documented,
+      coverage: summary.coverage,
+      languages: finish(languages.values()).sort((a, b) => b.total - a.total),
+      directories: finish(directories.values()).sort((a, b) => a.name.localeCompare(b.name)),
+      files: finish(fileBuckets),
+      undocumented: undocumented
+        .sort((a, b) => b.lines - a.lines || a.filePath.localeCompare(b.filePath))
+        .slice(0, options.limit ?? 20)
+    };
+  }
+
+  private publicSymbols(file: FileRow, symbols: SymbolRow[]): SymbolRow[] {
+    const declared: string[] = file.metadata ? JSON.parse(file.metadata).exports || [] : [];
+    const exports = new Set(declared);
+    const open: { symbol: SymbolRow; visible: boolean }[] = [];
+    const result: SymbolRow[] = [];
+
+    for (const symbol of symbols) {
+      // Containers still open at this line enclose the symbol
+      while (open.length > 0 && open[open.length - 1].symbol.lineEnd < symbol.lineStart) {
+        open.pop();
+      }
+      const parent = open[open.length - 1];
+
+      let visible = parent ? parent.visible : true;
+      visible = visible && isPublicName(symbol.name, file.language) && !hasNonPublicModifier(symbol, file.language, parent?.symbol);
+      if (!parent && (exports.size > 0 || EXPORT_LIST_LANGUAGES.has(file.language || ''))) {
+        visible = visible && exports.has(symbol.name);
+      }
+
+      if (visible && DOCUMENTABLE_TYPES.has(symbol.type)) {
+        result.push(symbol);
+      }
+      if (CONTAINER_TYPES.has(symbol.type)) {
+        open.push({ symbol, visible });
+      }
+    }
+    return result;
+  }
+}
+
+// Access modifiers before the name; Rust items are private unless marked `pub`, trait members aside
+/**
+ * Whether a report meets a minimum percentage. A report with no public
+ * symbols measures nothing, so it fails instead of passing as 100%.
+ */
+export function meetsMinimum(report: DocCoverageReport, min: number): boolean {
+  if (report.total === 0) {
+    throw new Error(`No public symbols found, so coverage can't be checked against the ${min}% minimum. Run \`primordyn index\` first, or check --languages.`);
+  }
+  return report.coverage >= min;
+}
+
+function hasNonPublicModifier(symbol: SymbolRow, language: string | null, parent?: SymbolRow): boolean {
+  if (!symbol.signature) {
+    return false;
+  }
+  const nameAt = symbol.signature.indexOf(symbol.name);
+  const modifiers = nameAt >= 0 ? symbol.signature.slice(0, nameAt) : symbol.signature;
+  if (language === 'rust' && parent?.type !== 'trait') {
+    return !/\bpub\b/.test(modifiers);
+  }
+  return /\b(private|protected|internal|fileprivate)\b/.test(modifiers);
+}
+
+function bucket(name: string): CoverageBucket {
+  return { name, total: 0, documented: 0, coverage: 100 };
+}
+
+function lookup(buckets: Map<string, CoverageBucket>, name: string): CoverageBucket {
+  let found = buckets.get(name);
+  if (!found) {
+    found = bucket(name);
+    buckets.set(name, found);
+  }
+  return found;
+}
+
+function withCoverage(target: CoverageBucket): CoverageBucket {
+  const coverage = target.total === 0 ? 100 : Math.round((target.documented / target.total) * 1000) / 10;
+  return { ...target, coverage };
+}
\ No newline at end of file
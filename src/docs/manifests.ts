@@ -0,0 +1,143 @@
+import { existsSync, readFileSync, readdirSync } from 'fs';
+import { join } from 'path';
+import type { DetectedCommand } from '../types/index.js';
+
+// package.json scripts worth telling an agent about, in the order they're listed
+const SCRIPT_PURPOSES: Record<string, string> = {
+  install: 'install',
+  build: 'build',
+  dev: 'run (dev)',
+  start: 'run',
+  test: 'test',
+  lint: 'lint',
+  typecheck: 'type-check',
+  'type-check': 'type-check',
+  format: 'format',
+  fmt: 'format',
+  check: 'check'
+};
+
+const MAKE_TARGETS = ['install', 'build', 'test', 'lint', 'fmt', 'format', 'check', 'run'];
+
+/**
+ * Build, test and lint commands declared by the manifests at the project
+ * root: package.json scripts, Makefile targets and the standard commands of
+ * Go, Rust, Python, Maven and Gradle projects.
+ */
+export function detectCommands(root: string): DetectedCommand[] {
+  const commands: DetectedCommand[] = [];
+  const read = (name: string): string | null => {
+    const path = join(root, name);
+    return existsSync(path) ? readFileSync(path, 'utf8') : null;
+  };
+  const add = (purpose: string, command: string, source: string) => {
+    if (!commands.some(existing => existing.command === command)) {
+      commands.push({ purpose, command, source });
+    }
+  };
+
+  const packageJson = read('package.json');
+  if (packageJson) {
+    try {
+      const scripts = (JSON.parse(packageJson) as { scripts?: Record<string, string> }).scripts || {};
+      const runner = packageRunner(root);
+      add('install', `${runner} install`, 'package.json');
+      for (const [name, purpose] of Object.entries(SCRIPT_PURPOSES)) {
+        if (scripts[name]) {
+          add(purpose, name === 'test' || name === 'start' ? `${runner} ${name}` : `${runner} run ${name}`, 'package.json');
+        }
+      }
+    } catch {
+      // Unparseable package.json; other manifests may still tell us something
+    }
+  }
+
+  const makefile = read('Makefile');
+  if (makefile) {
+    const targets = new Set([...makefile.matchAll(/^([A-Za-z][\w-]*):/gm)].map(match => match[1]));
+    for (const target of MAKE_TARGETS.filter(target => targets.has(target))) {
+      add(target === 'fmt' ? 'format' : target, `make ${target}`, 'Makefile');
+    }
+  }
+
+  if (read('go.mod') !== null) {
+    add('build', 'go build ./...', 'go.mod');
+    add('test', 'go test ./...', 'go.mod');
+    add('lint', 'go vet ./...', 'go.mod');
+  }
+
+  if (read('Cargo.toml') !== null) {
+    add('build', 'cargo build', 'Cargo.toml');
+    add('test', 'cargo test', 'Cargo.toml');
+    add('lint', 'cargo clippy', 'Cargo.toml');
+  }
+
+  const pyproject = read('pyproject.toml');
+  if (pyproject !== null) {
+    if (/\[tool\.poetry\]/.test(pyproject)) {
+      add('install', 'poetry install', 'pyproject.toml');
+    } else if (/\[tool\.uv\]/.test(pyproject) || existsSync(join(root, 'uv.lock'))) {
+      add('install', 'uv sync', 'pyproject.toml');
+    } else {
+      add('install', 'pip install -e .', 'pyproject.toml');
+    }
+    if (/pytest/.test(pyproject)) {
+      add('test', 'pytest', 'pyproject.toml');
+    }
+    if (/\[tool\.ruff/.test(pyproject)) {
+      add('lint', 'ruff check .', 'pyproject.toml');
+    }
+    if (/\[tool\.mypy\]/.test(pyproject)) {
+      add('type-check', 'mypy .', 'pyproject.toml');
+    }
+  } else if (read('requirements.txt') !== null) {
+    add('install', 'pip install -r requirements.txt', 'requirements.txt');
+  }
+
+  if (read('pom.xml') !== null) {
+    add('build', 'mvn package', 'pom.xml');
+    add('test', 'mvn test', 'pom.xml');
+  }
+
+  const gradle = ['build.gradle', 'build.gradle.kts'].find(name => existsSync(join(root, name)));
+  if (gradle) {
+    const wrapper = existsSync(join(root, 'gradlew')) ? './gradlew' : 'gradle';
+    add('build', `${wrapper} build`, gradle);
+    add('test', `${wrapper} test`, gradle);
+  }
+
+  return commands;
+}
+
+/** Tool configuration files at the root that imply formatting or lint rules. */
+export function detectToolConfigs(root: string): string[] {
+  const patterns = [
+    /^\.editorconfig$/,
+    /^\.prettierrc(\..+)?$/, /^prettier\.config\./,
+    /^\.eslintrc(\..+)?$/, /^eslint\.config\./,
+    /^biome\.json$/,
+    /^tsconfig\.json$/,
+    /^ruff\.toml$/, /^\.flake8$/, /^mypy\.ini$/,
+    /^\.golangci\.ya?ml$/,
+    /^rustfmt\.toml$/, /^clippy\.toml$/,
+    /^\.rubocop\.yml$/
+  ];
+  try {
+    return readdirSync(root).filter(name => patterns.some(pattern => pattern.test(name))).sort();
+  } catch {
+    return [];
+  }
+}
+
+function packageRunner(root: string): string {
+  if (existsSync(join(root, 'pnpm-lock.yaml'))) {
+    return 'pnpm';
+  }
+  if (existsSync(join(root, 'yarn.lock'))) {
+    return 'yarn';
+  }
+  if (existsSync(join(root, 'bun.lockb')) || existsSync(join(root, 'bun.lock'))) {
+    return 'bun';
+  }
+  return 'npm';
+}
\ No newline at end of file
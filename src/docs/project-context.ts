@@ -0,0 +1,197 @@
+import { existsSync, readFileSync } from 'fs';
+import { join } from 'path';
+import { PrimordynDB } from '../database/index.js';
+import { RepoMapBuilder, formatEntryPoint, formatModule } from '../map/index.js';
+import { detectCommands, detectToolConfigs } from './manifests.js';
+
+export const DEFAULT_CONTEXT_FILE = 'AGENTS.md';
+
+export interface GeneratedSection {
+  id: string;
+  content: string;
+}
+
+// Test files by layout, checked in order; the first pattern a file matches names its convention
+const TEST_LAYOUTS: { pattern: RegExp; description: string }[] = [
+  { pattern: /(^|\/)__tests__\//, description: '`__tests__/` directories next to the code' },
+  { pattern: /\.(test|spec)\.[jt]sx?$/, description: '`*.test.*` / `*.spec.*` files' },
+  { pattern: /_test\.go$/, description: '`*_test.go` files beside the package' },
+  { pattern: /(^|\/)test_[^/]+\.py$|_test\.py$/, description: '`test_*.py` modules' },
+  { pattern: /(^|\/)src\/test\//, description: '`src/test/` tree (Maven layout)' },
+  { pattern: /(^|\/)tests?\//, description: 'a top-level `tests/` directory' }
+];
+
+const INTRO = '<!-- Sections between primordyn markers are regenerated by `primordyn docs generate`; edit outside them. -->';
+
+/**
+ * Writes an AGENTS.md / CLAUDE.md style project context file from the index:
+ * architecture, key modules, build and test commands, and conventions. Only
+ * the sections between markers are owned by the generator.
+ */
+export class ProjectContextGenerator {
+  private db: PrimordynDB;
+  private root: string;
+
+  constructor(db: PrimordynDB, root: string) {
+    this.db = db;
+    this.root = root;
+  }
+
+  public sections(): GeneratedSection[] {
+    // Generous budget: the doc is written once and read many times, and the lists are capped below
+    const map = new RepoMapBuilder(this.db).build({ maxTokens: 20000, maxDepth: 2 });
+
+    // Nothing here may change with an edit inside a function body, or --check
+    // fails on every commit: file counts, but no symbol totals, lines or call counts
+    const overview = ['## Architecture overview', ''];
+    const languages = map.languages.map(entry => `${entry.language} (${entry.count} files)`).join(', ');
+    overview.push(languages ? `Languages: ${languages}.` : 'No source files in the index.');
+    if (map.entryPoints.length > 0) {
+      overview.push('', 'Entry points:', ...map.entryPoints.slice(0, 10).map(formatEntryPoint));
+    }
+    const directories = map.directories.filter(directory => directory.depth > 0);
+    if (directories.length > 0) {
+      overview.push('', 'Layout:', '```');
+      for (const directory of directories) {
+        const name = directory.path.split('/').pop();
+        const languages = directory.languages.length ? `, ${directory.languages.join('/')}` : '';
+        overview.push(`${'  '.repeat(directory.depth - 1)}${name}/ (${directory.files} files${languages})`);
+      }
+      overview.push('```');
+    }
+
+    const modules = ['## Key modules', ''];
+    if (map.modules.length > 0) {
+      modules.push('Public API of the modules the rest of the code depends on most:', '', ...map.modules.slice(0, 15).map(formatModule));
+    } else {
+      modules.push('No exported symbols found in the index.');
+    }
+    if (map.centralSymbols.length > 0) {
+      // By name, so calls shifting between the top ten don't reorder the list
+      const central = map.centralSymbols.slice(0, 10).sort((a, b) => a.name.localeCompare(b.name));
+      modules.push('', 'Most-called symbols:', '', ...central.map(symbol => `- ${symbol.name} (${symbol.type}) in ${symbol.filePath}`));
+    }
+
+    const commands = ['## Build and test', ''];
+    const detected = detectCommands(this.root);
+    if (detected.length > 0) {
+      commands.push(...detected.map(command => `- ${command.purpose}: \`${command.command}\` (${command.source})`));
+    } else {
+      commands.push('No build manifest found at the project root.');
+    }
+
+    return [
+      { id: 'overview', content: overview.join('\n') },
+      { id: 'modules', content: modules.join('\n') },
+      { id: 'commands', content: commands.join('\n') },
+      { id: 'conventions', content: ['## Conventions', '', ...this.conventions()].join('\n') }
+    ];
+  }
+
+  /** The file with its generated sections replaced, or a new file when there is none. */
+  public render(existing: string | null): string {
+    return mergeGeneratedSections(existing, this.sections());
+  }
+
+  private conventions(): string[] {
+    const database = this.db.getDatabase();
+    const conventions: string[] = [];
+
+    const paths = (database.prepare('SELECT relative_path as path FROM files').all() as { path: string }[]).map(row => row.path);
+    const layoutCounts = new Map<string, number>();
+    for (const path of paths) {
+      const layout = TEST_LAYOUTS.find(candidate => candidate.pattern.test(path));
+      if (layout) {
+        layoutCounts.set(layout.description, (layoutCounts.get(layout.description) || 0) + 1);
+      }
+    }
+    const testLayout = [...layoutCounts.entries()].sort((a, b) => b[1] - a[1])[0];
+    if (testLayout) {
+      conventions.push(`- Tests live in ${testLayout[0]} (${testLayout[1]} files)`);
+    }
+
+    const packageJson = this.readRootFile('package.json');
+    if (packageJson && /"type"\s*:\s*"module"/.test(packageJson)) {
+      conventions.push('- ES modules (`"type": "module"`); relative imports carry the `.js` extension');
+    }
+    const tsconfig = this.readRootFile('tsconfig.json');
+    if (tsconfig && /"strict"\s*:\s*true/.test(tsconfig)) {
+      conventions.push('- TypeScript strict mode');
+    }
+
+    for (const [language, indent] of this.indentation()) {
+      conventions.push(`- ${language}: indented with ${indent}`);
+    }
+
+    const configs = detectToolConfigs(this.root);
+    if (configs.length > 0) {
+      conventions.push(`- Formatting and lint configuration: ${configs.map(config => `\`${config}\``).join(', ')}`);
+    }
+
+    return conventions.length > 0 ? conventions : ['No conventions detected yet.'];
+  }
+
+  // The most common first indentation step per language, sampled from indexed files
+  private indentation(): [string, string][] {
+    const files = this.db.getDatabase().prepare(`
+      SELECT language, content FROM files_with_content WHERE language IS NOT NULL ORDER BY relative_path
+    `).all() as { language: string; content: string }[];
+
+    const steps = new Map<string, Map<string, number>>();
+    for (const file of files) {
+      let previousIndent = 0;
+      for (const line of file.content.split('\n')) {
+        if (line.trim() === '') {
+          continue;
+        }
+        const indent = line.match(/^[ \t]*/)![0];
+        if (previousIndent === 0 && indent.length > 0) {
+          const step = indent.startsWith('\t') ? 'tabs' : `${indent.length} spaces`;
+          const counts = steps.get(file.language) || new Map<string, number>();
+          counts.set(step, (counts.get(step) || 0) + 1);
+          steps.set(file.language, counts);
+        }
+        previousIndent = indent.length;
+      }
+    }
+
+    return [...steps.entries()]
+      .filter(([language]) => !['json', 'markdown', 'yaml'].includes(language))
+      .map(([language, counts]): [string, string] => [language, [...counts.entries()].sort((a, b) => b[1] - a[1])[0][0]])
+      .sort((a, b) => a[0].localeCompare(b[0]));
+  }
+
+  private readRootFile(name: string): string | null {
+    const path = join(this.root, name);
+    return existsSync(path) ? readFileSync(path, 'utf8') : null;
+  }
+}
+
+/**
+ * Replaces each section's marked block in `existing`, appending sections that
+ * have no block yet. Everything outside the markers is left as written.
+ */
+export function mergeGeneratedSections(existing: string | null, sections: GeneratedSection[]): string {
+  const block = (section: GeneratedSection) =>
+    `<!-- primordyn:begin ${section.id} -->\n${section.content}\n<!-- primordyn:end ${section.id} -->`;
+
+  if (existing === null) {
+    return `# Project context\n\n${INTRO}\n\n${sections.map(block).join('\n\n')}\n`;
+  }
+
+  let merged = existing;
+  const missing: GeneratedSection[] = [];
+  for (const section of sections) {
+    const pattern = new RegExp(`<!-- primordyn:begin ${section.id} -->[\\s\\S]*?<!-- primordyn:end ${section.id} -->`);
+    if (pattern.test(merged)) {
+      // A function replacement keeps `$` in the content literal
+      merged = merged.replace(pattern, () => block(section));
+    } else {
+      missing.push(section);
+    }
+  }
+  if (missing.length > 0) {
+    merged = `${merged.trimEnd()}\n\n${missing.map(block).join('\n\n')}\n`;
+  }
+  return merged;
+}
\ No newline at end of file
@@ -0,0 +1,326 @@
+import { createHash } from 'crypto';
+import { PrimordynDB } from '../database/index.js';
+import { semanticTerms } from '../search/semantic-terms.js';
+import { querySubwords, ftsTerm } from '../search/identifiers.js';
+import { EmbeddingError } from './providers.js';
+import { SecretRedactor } from '../security/redactor.js';
+import { DEFAULT_CONFIG } from '../config/index.js';
+import type { EmbeddingProvider } from './providers.js';
+import type { EmbeddingsConfig, RedactionConfig } from '../config/index.js';
+import type { SemanticMatch } from '../search/semantic-index.js';
+import type { SymbolQueryRow } from '../types/index.js';
+
+export { createEmbeddingProvider, EmbeddingError } from './providers.js';
+export type { EmbeddingProvider } from './providers.js';
+
+export interface EmbeddingUpdateStats {
+  chunks: number;
+  embedded: number;
+  reused: number;
+}
+
+export interface EmbeddingSearchResult {
+  symbols: SemanticMatch[];
+  // Files ordered by their best matching chunk
+  fileIds: number[];
+}
+
+interface PendingChunk {
+  fileId: number;
+  fileHash: string;
+  symbolId: number | null;
+  lineStart: number;
+  lineEnd: number;
+  contentHash: string;
+}
+
+// Files are also embedded in windows of this many lines, to catch code outside symbols
+const FILE_CHUNK_LINES = 60;
+// Most embedding models truncate around here anyway
+const MAX_CHUNK_CHARS = 4000;
+const RETRY_BASE_DELAY_MS = 500;
+// Share of a symbol's score taken by vector similarity; the rest is bm25(symbols_fts)
+const VECTOR_WEIGHT = 0.6;
+
+/**
+ * Embeddings from a configured provider for symbols and file chunks. Vectors
+ * are cached by the hash of the text that was embedded, so re-indexing only
+ * sends new or changed code to the provider. Chunks are redacted first, since
+ * the provider may be a remote endpoint.
+ */
+export class EmbeddingIndex {
+  private db: PrimordynDB;
+  private provider: EmbeddingProvider;
+  private config: Pick<EmbeddingsConfig, 'batchSize' | 'maxRetries'>;
+  private redactor: SecretRedactor;
+
+  constructor(
+    db: PrimordynDB,
+    provider: EmbeddingProvider,
+    config: Pick<EmbeddingsConfig, 'batchSize' | 'maxRetries'>,
+    redaction: RedactionConfig = DEFAULT_CONFIG.redaction
+  ) {
+    this.db = db;
+    this.provider = provider;
+    this.config = config;
+    this.redactor = new SecretRedactor(redaction);
+  }
+
+  public hasEmbeddings(): boolean {
+    return Boolean(this.db.getDatabase().prepare(
+      'SELECT 1 FROM embedding_chunks WHERE provider = ? LIMIT 1'
+    ).get(this.provider.id));
+  }
+
+  /** Embed chunks of files that are new or changed since the last update. */
+  public async update(onProgress?: (done: number, total: number) => void): Promise<EmbeddingUpdateStats> {
+    const database = this.db.getDatabase();
+    const provider = this.provider.id;
+
+    // Chunks of changed files, and of any previously configured provider, are rebuilt
+    database.prepare(`
+      DELETE FROM embedding_chunks
+      WHERE provider != ?
+        OR file_hash != (SELECT hash FROM files WHERE files.id = embedding_chunks.file_id)
+    `).run(provider);
+
+    const fileIds = (database.prepare(`
+      SELECT id FROM files
+      WHERE id NOT IN (SELECT file_id FROM embedding_chunks)
+      ORDER BY id
+    `).all() as { id: number }[]).map(row => row.id);
+
+    const chunks: PendingChunk[] = [];
+    const texts = new Map<string, string>();
+    const isCached = database.prepare('SELECT 1 FROM embedding_cache WHERE content_hash = ? AND provider = ?');
+    for (const fileId of fileIds) {
+      for (const { chunk, text } of this.chunkFile(fileId)) {
+        chunks.push(chunk);
+        if (!texts.has(chunk.contentHash) && !isCached.get(chunk.contentHash, provider)) {
+          texts.set(chunk.contentHash, text);
+        }
+      }
+    }
+
+    const pending = [...texts.entries()];
+    const insertVector = database.prepare(
+      'INSERT OR REPLACE INTO embedding_cache (content_hash, provider, vector) VALUES (?, ?, ?)'
+    );
+    onProgress?.(0, pending.length);
+    for (let i = 0; i < pending.length; i += this.config.batchSize) {
+      const batch = pending.slice(i, i + this.config.batchSize);
+      const vectors = await this.embedWithRetry(batch.map(([, text]) => text));
+      database.transaction(() => {
+        batch.forEach(([hash], index) => insertVector.run(hash, provider, encodeVector(vectors[index])));
+      })();
+      onProgress?.(Math.min(i + batch.length, pending.length), pending.length);
+    }
+
+    const insertChunk = database.prepare(`
+      INSERT INTO embedding_chunks (file_id, file_hash, symbol_id, line_start, line_end, content_hash, provider)
+      VALUES (?, ?, ?, ?, ?, ?, ?)
+    `);
+    database.transaction(() => {
+      for (const chunk of chunks) {
+        insertChunk.run(chunk.fileId, chunk.fileHash, chunk.symbolId, chunk.lineStart, chunk.lineEnd, chunk.contentHash, provider);
+      }
+      // Vectors nothing refers to any more (deleted or edited code)
+      database.prepare(`
+        DELETE FROM embedding_cache
+        WHERE provider = ? AND content_hash NOT IN (SELECT content_hash FROM embedding_chunks WHERE provider = ?)
+      `).run(provider, provider);
+    })();
+
+    return { chunks: chunks.length, embedded: pending.length, reused: chunks.length - pending.length };
+  }
+
+  /** Vector similarity over embedded chunks, blended with bm25(symbols_fts) for symbols. */
+  public async search(query: string, options: { fileTypes?: string[]; limit?: number } = {}): Promise<EmbeddingSearchResult> {
+    const limit = options.limit || 20;
+    const database = this.db.getDatabase();
+    const [queryVector] = await this.embedWithRetry([query]);
+    const queryNorm = norm(queryVector);
+
+    const params: unknown[] = [this.provider.id];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+    const rows = database.prepare(`
+      SELECT c.symbol_id as symbolId, c.file_id as fileId, ec.vector
+      FROM embedding_chunks c
+      JOIN embedding_cache ec ON ec.content_hash = c.content_hash AND ec.provider = c.provider
+      JOIN files f ON f.id = c.file_id
+      WHERE c.provider = ?
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+    `).all(...params) as { symbolId: number | null; fileId: number; vector: Buffer }[];
+
+    const symbolSimilarity = new Map<number, number>();
+    const fileSimilarity = new Map<number, number>();
+    for (const row of rows) {
+      const similarity = cosine(queryVector, queryNorm, decodeVector(row.vector));
+      if (similarity <= 0) {
+        continue;
+      }
+      if (row.symbolId !== null) {
+        symbolSimilarity.set(row.symbolId, Math.max(symbolSimilarity.get(row.symbolId) || 0, similarity));
+      }
+      fileSimilarity.set(row.fileId, Math.max(fileSimilarity.get(row.fileId) || 0, similarity));
+    }
+
+    const bm25 = this.bm25Scores(query, options, limit * 5);
+    const maxBm25 = Math.max(0, ...bm25.values());
+    const candidates = [...symbolSimilarity.entries()]
+      .sort((a, b) => b[1] - a[1])
+      .slice(0, limit * 5)
+      .map(([symbolId]) => symbolId);
+    const ids = [...new Set([...candidates, ...bm25.keys()])];
+
+    const symbols = this.loadSymbols(ids)
+      .map(row => {
+        const similarity = symbolSimilarity.get(row.id) || 0;
+        const lexical = maxBm25 > 0 ? (bm25.get(row.id) || 0) / maxBm25 : 0;
+        return { ...row, similarity, score: VECTOR_WEIGHT * similarity + (1 - VECTOR_WEIGHT) * lexical };
+      })
+      .sort((a, b) => b.score - a.score)
+      .slice(0, limit);
+
+    const fileIds = [...fileSimilarity.entries()]
+      .sort((a, b) => b[1] - a[1])
+      .slice(0, limit)
+      .map(([fileId]) => fileId);
+
+    return { symbols, fileIds };
+  }
+
+  private chunkFile(fileId: number): { chunk: PendingChunk; text: string }[] {
+    const database = this.db.getDatabase();
+    const file = database.prepare(
+      'SELECT relative_path as relativePath, hash, content FROM files_with_content WHERE id = ?'
+    ).get(fileId) as { relativePath: string; hash: string; content: string } | undefined;
+    if (!file) {
+      return [];
+    }
+
+    const lines = file.content.split('\n');
+    const chunks: { chunk: PendingChunk; text: string }[] = [];
+    const add = (symbolId: number | null, lineStart: number, lineEnd: number, text: string) => {
+      const trimmed = this.redactor.redact(text).slice(0, MAX_CHUNK_CHARS);
+      if (!trimmed.trim()) {
+        return;
+      }
+      const contentHash = createHash('sha256').update(trimmed).digest('hex');
+      chunks.push({ chunk: { fileId, fileHash: file.hash, symbolId, lineStart, lineEnd, contentHash }, text: trimmed });
+    };
+
+    const symbols = database.prepare(`
+      SELECT id, name, type, signature, documentation, line_start as lineStart, line_end as lineEnd
+      FROM symbols WHERE file_id = ?
+    `).all(fileId) as { id: number; name: string; type: string; signature: string | null; documentation: string | null; lineStart: number; lineEnd: number }[];
+    for (const symbol of symbols) {
+      const body = lines.slice(symbol.lineStart - 1, symbol.lineEnd).join('\n');
+      add(symbol.id, symbol.lineStart, symbol.lineEnd, [
+        `${symbol.type} ${symbol.name}`,
+        symbol.documentation,
+        body || symbol.signature
+      ].filter(Boolean).join('\n'));
+    }
+
+    for (let start = 0; start < lines.length; start += FILE_CHUNK_LINES) {
+      const end = Math.min(start + FILE_CHUNK_LINES, lines.length);
+      add(null, start + 1, end, `${file.relativePath}\n${lines.slice(start, end).join('\n')}`);
+    }
+
+    return chunks;
+  }
+
+  private async embedWithRetry(texts: string[]): Promise<number[][]> {
+    for (let attempt = 0; ; attempt++) {
+      try {
+        const vectors = await this.provider.embed(texts);
+        if (vectors.length !== texts.length) {
+          throw new EmbeddingError(`Expected ${texts.length} embeddings, got ${vectors.length}`);
+        }
+        return vectors;
+      } catch (error) {
+        if (attempt >= this.config.maxRetries) {
+          throw error;
+        }
+        await new Promise(resolve => setTimeout(resolve, RETRY_BASE_DELAY_MS * 2 ** attempt));
+      }
+    }
+  }
+
+  private bm25Scores(query: string, options: { fileTypes?: string[] }, limit: number): Map<number, number> {
+    const words = querySubwords(query).filter(word => semanticTerms(word).length > 0);
+    if (words.length === 0) {
+      return new Map();
+    }
+
+    const params: unknown[] = [words.map(word => ftsTerm(word, true)).join(' OR ')];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+
+    const rows = this.db.getDatabase().prepare(`
+      SELECT fts.rowid as symbolId, -bm25(symbols_fts) as score
+      FROM symbols_fts fts
+      JOIN symbols s ON s.id = fts.rowid
+      JOIN files f ON f.id = s.file_id
+      WHERE symbols_fts MATCH ?
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY score DESC
+      LIMIT ${limit}
+    `).all(...params) as { symbolId: number; score: number }[];
+
+    return new Map(rows.map(row => [row.symbolId, Math.max(0, row.score)]));
+  }
+
+  private loadSymbols(ids: number[]): (SymbolQueryRow & { fileId: number })[] {
+    if (ids.length === 0) {
+      return [];
+    }
+    return this.db.getDatabase().prepare(`
+      SELECT
+        s.id,
+        s.name,
+        s.type,
+        s.line_start as lineStart,
+        s.line_end as lineEnd,
+        s.signature,
+        s.file_id as fileId,
+        f.relative_path as filePath
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      WHERE s.id IN (${ids.map(() => '?').join(',')})
+    `).all(...ids) as (SymbolQueryRow & { fileId: number })[];
+  }
+}
+
+function encodeVector(vector: number[]): Buffer {
+  return Buffer.from(new Float32Array(vector).buffer);
+}
+
+function decodeVector(blob: Buffer): Float32Array {
+  // Copy first: the blob's offset into its backing buffer needn't be 4-byte aligned
+  return new Float32Array(new Uint8Array(blob).buffer);
+}
+
+function norm(vector: ArrayLike<number>): number {
+  let sum = 0;
+  for (let i = 0; i < vector.length; i++) {
+    sum += vector[i] * vector[i];
+  }
+  return Math.sqrt(sum);
+}
+
+function cosine(query: number[], queryNorm: number, vector: Float32Array): number {
+  if (vector.length !== query.length || queryNorm === 0) {
+    return 0;
+  }
+  let dot = 0;
+  for (let i = 0; i < vector.length; i++) {
+    dot += query[i] * vector[i];
+  }
+  const vectorNorm = norm(vector);
+  return vectorNorm === 0 ? 0 : dot / (queryNorm * vectorNorm);
+}
\ No newline at end of file
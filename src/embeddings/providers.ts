@@ -0,0 +1,141 @@
+import { spawn } from 'child_process';
+import { createHash } from 'crypto';
+import { semanticTerms } from '../search/semantic-terms.js';
+import type { EmbeddingsConfig } from '../config/index.js';
+
+export interface EmbeddingProvider {
+  // Identifies the vector space; vectors from different ids are never compared
+  readonly id: string;
+  embed(texts: string[]): Promise<number[][]>;
+}
+
+export class EmbeddingError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'EmbeddingError';
+  }
+}
+
+/**
+ * Any server speaking the OpenAI `/embeddings` API (llama.cpp, Ollama, vLLM,
+ * LocalAI, ...).
+ */
+export class OpenAIEmbeddingProvider implements EmbeddingProvider {
+  public readonly id: string;
+  private endpoint: string;
+  private model: string;
+  private apiKey?: string;
+
+  constructor(endpoint: string, model: string, apiKey?: string) {
+    this.endpoint = endpoint.replace(/\/+$/, '');
+    this.model = model;
+    this.apiKey = apiKey;
+    this.id = `openai:${model}`;
+  }
+
+  public async embed(texts: string[]): Promise<number[][]> {
+    const response = await fetch(`${this.endpoint}/embeddings`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {})
+      },
+      body: JSON.stringify({ model: this.model, input: texts })
+    });
+
+    if (!response.ok) {
+      const detail = await response.text().catch(() => '');
+      throw new EmbeddingError(`${this.endpoint}/embeddings returned ${response.status}${detail ? `: ${detail.slice(0, 200)}` : ''}`);
+    }
+
+    const body = await response.json() as { data?: { index: number; embedding: number[] }[] };
+    if (!Array.isArray(body.data) || body.data.length !== texts.length) {
+      throw new EmbeddingError(`Expected ${texts.length} embeddings from ${this.endpoint}, got ${body.data?.length ?? 'none'}`);
+    }
+    return [...body.data].sort((a, b) => a.index - b.index).map(item => item.embedding);
+  }
+}
+
+/**
+ * Runs a command per batch. Each input text is written to stdin as one JSON
+ * string per line; the command prints one JSON array of numbers per line in
+ * the same order.
+ */
+export class CommandEmbeddingProvider implements EmbeddingProvider {
+  public readonly id: string;
+  private command: string;
+
+  constructor(command: string) {
+    this.command = command;
+    this.id = `command:${command}`;
+  }
+
+  public embed(texts: string[]): Promise<number[][]> {
+    return new Promise((resolve, reject) => {
+      const child = spawn(this.command, { shell: true, stdio: ['pipe', 'pipe', 'pipe'] });
+      let stdout = '';
+      let stderr = '';
+      child.stdout.on('data', chunk => { stdout += chunk; });
+      child.stderr.on('data', chunk => { stderr += chunk; });
+      child.on('error', error => reject(new EmbeddingError(`Failed to run "${this.command}": ${error.message}`)));
+      child.on('close', code => {
+        if (code !== 0) {
+          reject(new EmbeddingError(`"${this.command}" exited with code ${code}${stderr ? `: ${stderr.trim().slice(0, 200)}` : ''}`));
+          return;
+        }
+        try {
+          const vectors = stdout.split('\n').filter(line => line.trim()).map(line => JSON.parse(line) as number[]);
+          if (vectors.length !== texts.length || vectors.some(vector => !Array.isArray(vector))) {
+            throw new Error(`expected ${texts.length} vectors, got ${vectors.length}`);
+          }
+          resolve(vectors);
+        } catch (error) {
+          reject(new EmbeddingError(`Invalid output from "${this.command}": ${error instanceof Error ? error.message : error}`));
+        }
+      });
+
+      // A command that exits without reading stdin is reported through 'close'
+      child.stdin.on('error', () => {});
+      child.stdin.end(texts.map(text => JSON.stringify(text)).join('\n') + '\n');
+    });
+  }
+}
+
+/**
+ * Deterministic, dependency-free vectors for tests: stemmed terms are hashed
+ * into a fixed number of buckets, so texts sharing vocabulary are similar.
+ */
+export class StubEmbeddingProvider implements EmbeddingProvider {
+  public readonly id: string;
+  private dimensions: number;
+
+  constructor(dimensions: number = 64) {
+    this.dimensions = dimensions;
+    this.id = `stub:${dimensions}`;
+  }
+
+  public async embed(texts: string[]): Promise<number[][]> {
+    return texts.map(text => {
+      const vector = new Array<number>(this.dimensions).fill(0);
+      for (const term of semanticTerms(text)) {
+        const hash = createHash('md5').update(term).digest();
+        vector[hash.readUInt32LE(0) % this.dimensions] += hash[4] & 1 ? 1 : -1;
+      }
+      return vector;
+    });
+  }
+}
+
+/** The configured provider, or null when embeddings are turned off. */
+export function createEmbeddingProvider(config: EmbeddingsConfig): EmbeddingProvider | null {
+  switch (config.provider) {
+    case 'openai':
+      return new OpenAIEmbeddingProvider(config.endpoint, config.model, config.apiKeyEnv ? process.env[config.apiKeyEnv] : undefined);
+    case 'command':
+      return new CommandEmbeddingProvider(config.command);
+    case 'stub':
+      return new StubEmbeddingProvider();
+    default:
+      return null;
+  }
+}
\ No newline at end of file
@@ -0,0 +1,101 @@
+import { attachDocumentation, parseDocTags } from '../doc-comments.js';
+import type { Symbol } from '../../types/index.js';
+
+const symbol = (name: string, type: Symbol['type'], lineStart: number, lineEnd = lineStart): Symbol =>
+  ({ name, type, lineStart, lineEnd });
+
+describe('attachDocumentation', () => {
+  test('attaches JSDoc across decorators and parses its tags', () => {
+    const content = [
+      '/**',
+      ' * Loads a user by id.',
+      ' * @param {string} id - The user id',
+      ' * @returns {Promise<User>} the user',
+      ' * @deprecated Use findUser instead',
+      ' */',
+      '@cached()',
+      'export async function loadUser(id: string) {}',
+      '',
+      '// Not a doc comment',
+      'function helper() {}'
+    ].join('\n');
+    const symbols = [symbol('loadUser', 'function', 8), symbol('helper', 'function', 11)];
+
+    attachDocumentation(symbols, content, 'typescript');
+
+    expect(symbols[0].documentation).toBe('Loads a user by id.\n@param {string} id - The user id\n@returns {Promise<User>} the user\n@deprecated Use findUser instead');
+    expect(symbols[0].metadata?.docTags).toEqual({
+      params: [{ name: 'id', type: 'string', description: 'The user id' }],
+      returns: 'the user',
+      deprecated: 'Use findUser instead'
+    });
+    expect(symbols[1].documentation).toBeUndefined();
+  });
+
+  test('attaches Go, Rust and Javadoc comments', () => {
+    const go = [symbol('Open', 'function', 5)];
+    attachDocumentation(go, '//go:build linux\n\n// Open opens the store.\n// Deprecated: use OpenContext.\nfunc Open() {}', 'go');
+    expect(go[0].documentation).toBe('Open opens the store.\nDeprecated: use OpenContext.');
+    expect(go[0].metadata?.docTags).toEqual({ deprecated: 'use OpenContext.' });
+
+    const rust = [symbol('parse', 'function', 5)];
+    attachDocumentation(rust, '/// Parses the input.\n///\n/// Panics on empty input.\n#[deprecated]\npub fn parse() {}', 'rust');
+    expect(rust[0].documentation).toBe('Parses the input.\n\nPanics on empty input.');
+    expect(rust[0].metadata?.docTags).toEqual({ deprecated: '' });
+
+    const java = [symbol('save', 'method', 6)];
+    attachDocumentation(java, 'class A {\n  /**\n   * Saves.\n   * @throws IOException when the disk is full\n   */\n  void save() {}\n}', 'java');
+    expect(java[0].documentation).toBe('Saves.\n@throws IOException when the disk is full');
+    expect(java[0].metadata?.docTags).toEqual({ throws: ['IOException when the disk is full'] });
+  });
+
+  test('reads Python docstrings with Google and Sphinx sections', () => {
+    const content = [
+      'class Store:',
+      '    """Keeps records."""',
+      '',
+      '    def get(self, key: str,',
+      '            default=None) -> str:',
+      '        """Fetch a record.',
+      '',
+      '        Args:',
+      '            key (str): The record key.',
+      '            default: Returned when missing,',
+      '                instead of raising.',
+      '',
+      '        Raises:',
+      '            KeyError: If the key is malformed.',
+      '        """',
+      '',
+      '    def put(self, key, value):',
+      '        """Store a value.',
+      '',
+      '        :param str key: The key.',
+      '        :returns: Nothing useful.',
+      '        """'
+    ].join('\n');
+    const symbols = [symbol('Store', 'class', 1, 22), symbol('get', 'method', 4, 15), symbol('put', 'method', 17, 22)];
+
+    attachDocumentation(symbols, content, 'python');
+
+    expect(symbols[0].documentation).toBe('Keeps records.');
+    expect(symbols[1].documentation?.split('\n')[0]).toBe('Fetch a record.');
+    expect(symbols[1].metadata?.docTags).toEqual({
+      params: [
+        { name: 'key', type: 'str', description: 'The record key.' },
+        { name: 'default', description: 'Returned when missing, instead of raising.' }
+      ],
+      throws: ['KeyError: If the key is malformed.']
+    });
+    expect(symbols[2].metadata?.docTags).toEqual({
+      params: [{ name: 'key', type: 'str', description: 'The key.' }],
+      returns: 'Nothing useful.'
+    });
+  });
+});
+
+describe('parseDocTags', () => {
+  test('returns no tags for plain prose', () => {
+    expect(parseDocTags('Just a description.\nOver two lines.')).toEqual({});
+  });
+});
\ No newline at end of file
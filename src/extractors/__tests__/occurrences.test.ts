@@ -0,0 +1,98 @@
+import { findOccurrences } from '../occurrences.js';
+import type { ExtractedContext, Symbol, TypeReference } from '../../types/index.js';
+
+const context = (symbols: Symbol[], typeReferences: TypeReference[] = []): ExtractedContext => ({
+  symbols,
+  imports: [],
+  exports: [],
+  dependencies: [],
+  comments: [],
+  calls: [],
+  typeRelations: [],
+  typeReferences,
+  occurrences: [],
+  structure: {}
+});
+
+describe('findOccurrences', () => {
+  const of = (name: string, content: string, language: string, extracted: ExtractedContext) =>
+    findOccurrences(content, language, extracted)
+      .filter(occurrence => occurrence.name === name)
+      .map(occurrence => `${occurrence.line}:${occurrence.column} ${occurrence.role}`);
+
+  test('classifies TypeScript occurrences and skips comments, strings and longer names', () => {
+    const content = [
+      "import { User, UserService } from './user';",
+      '// User is loaded here',
+      'function load(id: string): User {',
+      '  const user = new User(id);',
+      '  user.name = `User ${User.label}`;',
+      '  return user;',
+      '}',
+      "const label = 'User';",
+      'let count = 0;',
+      'count += 1;'
+    ].join('\n');
+    const extracted = context(
+      [{ name: 'load', type: 'function', lineStart: 3, lineEnd: 7 }],
+      [{ name: 'User', kind: 'return', line: 3 }]
+    );
+
+    expect(of('User', content, 'typescript', extracted)).toEqual(['1:9 import', '3:27 type', '4:19 call', '5:22 read']);
+    expect(of('load', content, 'typescript', extracted)).toEqual(['3:9 definition']);
+    expect(of('name', content, 'typescript', extracted)).toEqual(['5:7 write']);
+    expect(of('count', content, 'typescript', extracted)).toEqual(['9:4 write', '10:0 write']);
+  });
+
+  test('skips Python docstrings and continues parenthesized imports', () => {
+    const content = [
+      'from models import (',
+      '    Record,',
+      ')',
+      '',
+      'def build(data):',
+      '    """Build a Record from data."""',
+      '    return Record(data)  # a Record'
+    ].join('\n');
+    const extracted = context([{ name: 'build', type: 'function', lineStart: 5, lineEnd: 7 }]);
+
+    expect(of('Record', content, 'python', extracted)).toEqual(['2:4 import', '7:11 call']);
+    expect(of('data', content, 'python', extracted)).toEqual(['5:10 read', '7:18 read']);
+  });
+
+  test('does not read Rust lifetimes as string literals', () => {
+    const content = "fn first<'a>(items: &'a [Item]) -> &'a Item {\n    let c = 'x';\n    &items[0]\n}";
+    const extracted = context([{ name: 'first', type: 'function', lineStart: 1, lineEnd: 4 }]);
+
+    expect(of('Item', content, 'rust', extracted)).toEqual(['1:25 read', '1:39 read']);
+    expect(of('items', content, 'rust', extracted)).toEqual(['1:13 read', '3:5 read']);
+  });
+
+  test('uses the keywords of the file\'s own language and keeps single-letter names', () => {
+    const content = [
+      'export function map(items: number[], f: (x: number) => number) {',
+      '  return items.map(f);',
+      '}',
+      'export function select(query: string) {',
+      '  return map([1], x => x + 1);',
+      '}',
+      "const type = select('a');"
+    ].join('\n');
+    const extracted = context([
+      { name: 'map', type: 'function', lineStart: 1, lineEnd: 3 },
+      { name: 'select', type: 'function', lineStart: 4, lineEnd: 6 }
+    ]);
+
+    expect(of('map', content, 'typescript', extracted)).toEqual(['1:16 definition', '2:15 call', '5:9 call']);
+    expect(of('select', content, 'typescript', extracted)).toEqual(['4:16 definition', '7:13 call']);
+    expect(of('type', content, 'typescript', extracted)).toEqual(['7:6 write']);
+    expect(of('f', content, 'typescript', extracted)).toEqual(['1:37 read', '2:19 read']);
+
+    // Still keywords in Go
+    const go = 'func wait(c chan int) map[string]int {\n\tselect {\n\t}\n}';
+    const goExtracted = context([{ name: 'wait', type: 'function', lineStart: 1, lineEnd: 4 }]);
+    expect(of('map', go, 'go', goExtracted)).toEqual([]);
+    expect(of('select', go, 'go', goExtracted)).toEqual([]);
+    expect(of('c', go, 'go', goExtracted)).toEqual(['1:10 read']);
+  });
+});
\ No newline at end of file
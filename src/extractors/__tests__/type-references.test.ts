@@ -0,0 +1,73 @@
+import { findTypeReferences } from '../type-references.js';
+
+describe('findTypeReferences', () => {
+  const refs = (content: string, language: string) =>
+    findTypeReferences(content, language).map(reference => `${reference.line} ${reference.kind} ${reference.name}`);
+
+  test('reads Python parameter, return and annotation types', () => {
+    const source = [
+      'def load(path: str, parser: Parser) -> List[Record]:',
+      '    cache: Dict[str, Record] = {}',
+      '    if ready: pass'
+    ].join('\n');
+    expect(refs(source, 'python')).toEqual([
+      '1 parameter Parser',
+      '1 return List',
+      '1 generic Record',
+      '2 annotation Dict',
+      '2 generic Record'
+    ]);
+  });
+
+  test('reads Go signatures and struct fields', () => {
+    const source = [
+      'type Server struct {',
+      '\tstore Store',
+      '\tlog.Logger',
+      '\tusers []*model.User',
+      '}',
+      '',
+      'func (s *Server) Handle(req *http.Request, n int) (*Response, error) {',
+      '}'
+    ].join('\n');
+    expect(refs(source, 'go')).toEqual([
+      '2 annotation Store',
+      '4 annotation User',
+      '7 parameter Request',
+      '7 return Response'
+    ]);
+  });
+
+  test('reads Java signatures across lines and skips type parameters', () => {
+    const source = [
+      'public class Repo {',
+      '  private final Map<String, User> users;',
+      '  public <T> Optional<User> find(UserId id,',
+      '      T hint) {',
+      '    return null;',
+      '  }',
+      '}'
+    ].join('\n');
+    expect(refs(source, 'java')).toEqual([
+      '2 annotation Map',
+      '2 generic String',
+      '2 generic User',
+      '3 parameter UserId',
+      '3 return Optional',
+      '3 generic User'
+    ]);
+  });
+
+  test('reads Rust signatures and let bindings', () => {
+    const source = 'fn render(scene: &Scene, out: &mut Vec<Pixel>) -> Result<Frame, Error> {\n    let cam: Camera = scene.camera();\n}';
+    expect(refs(source, 'rust')).toEqual([
+      '1 parameter Scene',
+      '1 parameter Vec',
+      '1 generic Pixel',
+      '1 return Result',
+      '1 generic Frame',
+      '1 generic Error',
+      '2 annotation Camera'
+    ]);
+  });
+});
\ No newline at end of file
@@ -0,0 +1,49 @@
+import { baseTypeName, findTypeRelations } from '../type-relations.js';
+
+describe('findTypeRelations', () => {
+  const edges = (content: string, language: string) =>
+    findTypeRelations(content, language).map(relation => `${relation.typeName} ${relation.kind} ${relation.relatedName}`);
+
+  test('reads Java extends and implements clauses', () => {
+    const source = 'public class Cache<K extends Comparable<K>> extends Base<K>\n    implements Map<K, V>, Closeable {\n}';
+    expect(edges(source, 'java')).toEqual(['Cache extends Base', 'Cache implements Map', 'Cache implements Closeable']);
+  });
+
+  test('reads Go struct and interface embedding', () => {
+    const source = [
+      'type Server struct {',
+      '\t*bufio.Writer',
+      '\tlog.Logger // embedded',
+      '\tname string',
+      '}',
+      '',
+      'type ReadCloser interface {',
+      '\tio.Reader',
+      '\tClose() error',
+      '}'
+    ].join('\n');
+    expect(findTypeRelations(source, 'go')).toEqual([
+      { typeName: 'Server', relatedName: 'Writer', kind: 'embeds', line: 2 },
+      { typeName: 'Server', relatedName: 'Logger', kind: 'embeds', line: 3 },
+      { typeName: 'ReadCloser', relatedName: 'Reader', kind: 'extends', line: 8 }
+    ]);
+  });
+
+  test('reads Rust trait impls and supertraits', () => {
+    const source = "impl<T: Clone> fmt::Display for Wrapper<T> {}\npub trait Shape: Debug + 'static {}";
+    expect(edges(source, 'rust')).toEqual(['Wrapper implements Display', 'Shape extends Debug']);
+  });
+
+  test('tells C# base classes from interfaces', () => {
+    expect(edges('public class Repo : DbContext, IRepository<User> {', 'csharp')).toEqual([
+      'Repo extends DbContext',
+      'Repo implements IRepository'
+    ]);
+  });
+
+  test('strips qualifiers and generics from references', () => {
+    expect(baseTypeName('com.example.Base<T>')).toBe('Base');
+    expect(baseTypeName('*pkg.Node')).toBe('Node');
+    expect(baseTypeName('std::fmt::Debug')).toBe('Debug');
+  });
+});
\ No newline at end of file
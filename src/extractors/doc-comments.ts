@@ -0,0 +1,327 @@
+import type { DocParam, DocTags, Symbol } from '../types/index.js';
+
+// Line comments that document the declaration below them, by language. Block
+// comments only count when they open with `/**` (Go accepts any `/*`).
+const LINE_DOC_PREFIXES: Record<string, string[]> = {
+  go: ['//'],
+  rust: ['///'],
+  csharp: ['///'],
+  swift: ['///'],
+  fsharp: ['///'],
+  dart: ['///'],
+  c: ['///', '//'],
+  cpp: ['///', '//'],
+  'objective-c': ['///', '//'],
+  'objective-cpp': ['///', '//'],
+  ruby: ['#'],
+  python: ['#'],
+  elixir: ['#'],
+  r: ["#'"],
+  lua: ['---'],
+  haskell: ['-- |']
+};
+
+// Decorators, annotations and attributes that may sit between a doc comment and its declaration
+const ATTRIBUTE_LINE = /^(@[\w.]+(\(.*\))?|#\[.*\]|\[[\w.]+(\(.*\))?\])$/;
+const DEPRECATED_ATTRIBUTE = /^(@deprecated\b|@Deprecated\b|#\[deprecated\b|\[Obsolete\b)/;
+const DOCSTRING_OWNERS = new Set(['function', 'method', 'class']);
+const MAX_HEADER_LINES = 10;
+
+/**
+ * Fills in `documentation` for symbols from the comment or docstring that
+ * documents them, and records `@param`-style tags as `metadata.docTags`.
+ * Works on source lines, so it serves every extractor the same way.
+ */
+export function attachDocumentation(symbols: Symbol[], content: string, language: string | null): void {
+  const lines = content.split('\n');
+  for (const symbol of symbols) {
+    if (symbol.type === 'import' || symbol.type === 'export') {
+      continue;
+    }
+
+    const above = commentAbove(lines, symbol.lineStart, language);
+    if (!symbol.documentation) {
+      const docstring = language === 'python' && DOCSTRING_OWNERS.has(symbol.type) ? docstringBelow(lines, symbol.lineStart) : null;
+      symbol.documentation = docstring ?? above.text ?? undefined;
+    }
+    if (!symbol.documentation && !above.deprecated) {
+      continue;
+    }
+
+    const tags = symbol.documentation ? parseDocTags(symbol.documentation) : {};
+    if (above.deprecated && tags.deprecated === undefined) {
+      tags.deprecated = '';
+    }
+    if (Object.keys(tags).length > 0) {
+      symbol.metadata = { ...symbol.metadata, docTags: tags };
+    }
+  }
+}
+
+/**
+ * Structured tags from a cleaned doc comment: JSDoc/Javadoc `@param`,
+ * `@returns`, `@throws` and `@deprecated`, Sphinx `:param x:` fields, Google
+ * style `Args:` / `Returns:` / `Raises:` sections and Go/Rust `Deprecated:`
+ * paragraphs. `deprecated` is an empty string when no reason is given.
+ */
+export function parseDocTags(text: string): DocTags {
+  const tags: DocTags = {};
+  const params: DocParam[] = [];
+  const throws: string[] = [];
+
+  const addParam = (name: string, type: string | undefined, description: string) => {
+    const param: DocParam = { name, description: description.trim() };
+    if (type) {
+      param.type = type.trim();
+    }
+    params.push(param);
+  };
+
+  // Tag blocks: a tag line plus its continuation lines, up to the next tag or a blank line
+  const blocks: { tag: string; text: string; indent?: number }[] = [];
+  let section: string | null = null;
+  for (const line of text.split('\n')) {
+    const trimmed = line.trim();
+    const jsdoc = trimmed.match(/^@(\w+)\s*(.*)$/);
+    const sphinx = trimmed.match(/^:(param|parameter|arg|type|returns?|rtype|raises?|except|exception)\b\s*([^:]*):\s*(.*)$/);
+    const heading = trimmed.match(/^(Args|Arguments|Parameters|Params|Returns|Return|Yields|Raises|Throws|Deprecated):\s*(.*)$/);
+
+    if (jsdoc) {
+      blocks.push({ tag: jsdoc[1], text: jsdoc[2] });
+      section = null;
+    } else if (sphinx) {
+      blocks.push({ tag: `:${sphinx[1]}`, text: `${sphinx[2].trim()}\u0000${sphinx[3]}` });
+      section = null;
+    } else if (heading) {
+      section = heading[1];
+      if (heading[2]) {
+        blocks.push({ tag: section, text: heading[2] });
+      }
+    } else if (trimmed === '') {
+      // Blank lines end tag blocks and Google sections
+      section = null;
+      blocks.push({ tag: '', text: '' });
+    } else if (section) {
+      // Google sections indent their entries; deeper indentation continues the previous entry
+      const previous = blocks[blocks.length - 1];
+      const indent = line.length - line.trimStart().length;
+      if (previous?.tag === section && previous.indent !== undefined && indent > previous.indent) {
+        previous.text += ` ${trimmed}`;
+      } else {
+        blocks.push({ tag: section, text: trimmed, indent });
+      }
+    } else if (blocks.length > 0 && blocks[blocks.length - 1].tag !== '') {
+      blocks[blocks.length - 1].text += ` ${trimmed}`;
+    }
+  }
+
+  for (const { tag, text: body } of blocks) {
+    switch (tag) {
+      case 'param':
+      case 'arg':
+      case 'argument': {
+        const match = body.match(/^(?:\{([^}]*)\}\s*)?\[?([\w$.]+)(?:=[^\]]*)?\]?\s*(?:-\s*)?([\s\S]*)$/);
+        if (match) {
+          addParam(match[2], match[1], match[3]);
+        }
+        break;
+      }
+      case 'return':
+      case 'returns':
+      case 'Returns':
+      case 'Return':
+      case 'Yields':
+      case ':return':
+      case ':returns':
+        tags.returns = [tags.returns, body.replace(/^\{[^}]*\}\s*/, '').replace(/^\u0000/, '').trim()].filter(Boolean).join(' ');
+        break;
+      case 'throws':
+      case 'exception':
+      case 'Raises':
+      case 'Throws':
+        throws.push(body.replace(/^\{([^}]*)\}/, '$1').trim());
+        break;
+      case ':raise':
+      case ':raises':
+      case ':except':
+      case ':exception': {
+        const [type, description] = body.split('\u0000');
+        throws.push([type, description].filter(Boolean).join(': ').trim());
+        break;
+      }
+      case ':param':
+      case ':parameter':
+      case ':arg': {
+        // `:param str name: description` puts the type before the name
+        const [declaration, description] = body.split('\u0000');
+        const words = declaration.split(/\s+/).filter(Boolean);
+        if (words.length > 0) {
+          addParam(words[words.length - 1], words.slice(0, -1).join(' ') || undefined, description);
+        }
+        break;
+      }
+      case ':type': {
+        const [name, type] = body.split('\u0000');
+        const param = params.find(candidate => candidate.name === name);
+        if (param) {
+          param.type = type.trim();
+        }
+        break;
+      }
+      case 'Args':
+      case 'Arguments':
+      case 'Parameters':
+      case 'Params': {
+        const match = body.match(/^\*{0,2}([\w$]+)\s*(?:\(([^)]*)\))?\s*:\s*([\s\S]*)$/);
+        if (match) {
+          addParam(match[1], match[2], match[3]);
+        }
+        break;
+      }
+      case 'deprecated':
+      case 'Deprecated':
+        tags.deprecated = body.trim();
+        break;
+    }
+  }
+
+  if (params.length > 0) {
+    tags.params = params;
+  }
+  if (throws.length > 0) {
+    tags.throws = throws;
+  }
+  if (tags.deprecated === undefined) {
+    // reStructuredText directive
+    const directive = text.match(/^\.\. deprecated::\s*(.*)$/m);
+    if (directive) {
+      tags.deprecated = directive[1].trim();
+    }
+  }
+  return tags;
+}
+
+/** The doc comment directly above a declaration, skipping attributes, and whether one of those marks it deprecated. */
+function commentAbove(lines: string[], lineStart: number, language: string | null): { text: string | null; deprecated: boolean } {
+  let index = lineStart - 2;
+  let deprecated = false;
+  // The declaration line itself may carry the decorator when the extractor starts the symbol there
+  if (DEPRECATED_ATTRIBUTE.test(lines[lineStart - 1]?.trim() ?? '')) {
+    deprecated = true;
+  }
+  while (index >= 0 && ATTRIBUTE_LINE.test(lines[index].trim())) {
+    deprecated = deprecated || DEPRECATED_ATTRIBUTE.test(lines[index].trim());
+    index--;
+  }
+  if (index < 0) {
+    return { text: null, deprecated };
+  }
+
+  const last = lines[index].trim();
+  if (last.endsWith('*/')) {
+    let start = index;
+    while (start > 0 && !lines[start].includes('/*')) {
+      start--;
+    }
+    const opening = lines[start].trim();
+    if (!opening.startsWith('/**') && !opening.startsWith('/*!') && !(language === 'go' && opening.startsWith('/*'))) {
+      return { text: null, deprecated };
+    }
+    return { text: cleanBlockComment(lines.slice(start, index + 1)), deprecated };
+  }
+
+  const prefixes = language ? LINE_DOC_PREFIXES[language] : undefined;
+  if (!prefixes) {
+    return { text: null, deprecated };
+  }
+  const collected: string[] = [];
+  for (let i = index; i >= 0; i--) {
+    const trimmed = lines[i].trim();
+    const prefix = prefixes.find(candidate => trimmed.startsWith(candidate));
+    // Shebangs, encoding lines and compiler directives aren't documentation
+    if (!prefix || /^#!|^#\s*-\*-|^\/\/(go:|line |nolint)/.test(trimmed) || (prefix === '//' && trimmed.startsWith('////'))) {
+      break;
+    }
+    collected.unshift(trimmed.slice(prefix.length).replace(/^ /, ''));
+  }
+  const text = trimBlankLines(collected).join('\n');
+  return { text: text || null, deprecated };
+}
+
+/** A Python docstring: the string literal opening the body of the def or class at `lineStart`. */
+function docstringBelow(lines: string[], lineStart: number): string | null {
+  let index = lineStart - 1;
+  const limit = Math.min(lines.length, index + MAX_HEADER_LINES);
+  while (index < limit && !/:\s*(#.*)?$/.test(lines[index])) {
+    index++;
+  }
+  index++;
+  while (index < lines.length && lines[index].trim() === '') {
+    index++;
+  }
+  if (index >= lines.length) {
+    return null;
+  }
+
+  const first = lines[index].trim();
+  const opening = first.match(/^[rRuU]?("""|'''|"|')/);
+  if (!opening) {
+    return null;
+  }
+  const quote = opening[1];
+  const rest = first.slice(opening[0].length);
+  const closing = rest.indexOf(quote);
+  if (closing >= 0) {
+    return rest.slice(0, closing).trim() || null;
+  }
+  if (quote.length === 1) {
+    return null;
+  }
+
+  const body = [rest];
+  for (let i = index + 1; i < lines.length; i++) {
+    const end = lines[i].indexOf(quote);
+    if (end >= 0) {
+      body.push(lines[i].slice(0, end));
+      return cleanDocstring(body) || null;
+    }
+    body.push(lines[i]);
+  }
+  return null;
+}
+
+function cleanBlockComment(lines: string[]): string {
+  const cleaned = lines.map((line, i) => {
+    let text = line.trim();
+    if (i === 0) {
+      text = text.replace(/^\/\*[*!]?/, '');
+    }
+    if (i === lines.length - 1) {
+      text = text.replace(/\*\/$/, '');
+    }
+    return text.replace(/^\* ?/, '').trimEnd();
+  });
+  return trimBlankLines(cleaned).join('\n');
+}
+
+// Like Python's inspect.cleandoc: the first line as is, the rest dedented by their common indentation
+function cleanDocstring(lines: string[]): string {
+  const indents = lines.slice(1)
+    .filter(line => line.trim() !== '')
+    .map(line => line.length - line.trimStart().length);
+  const indent = indents.length > 0 ? Math.min(...indents) : 0;
+  const cleaned = [lines[0].trim(), ...lines.slice(1).map(line => line.slice(indent).trimEnd())];
+  return trimBlankLines(cleaned).join('\n');
+}
+
+function trimBlankLines(lines: string[]): string[] {
+  let start = 0;
+  let end = lines.length;
+  while (start < end && lines[start].trim() === '') {
+    start++;
+  }
+  while (end > start && lines[end - 1].trim() === '') {
+    end--;
+  }
+  return lines.slice(start, end);
+}
\ No newline at end of file
@@ -3,6 +3,8 @@ import { TypeScriptExtractor } from './typescript-extractor.js';
 import { PythonExtractor } from './python-extractor.js';
 import { TreeSitterExtractor } from './treesitter-extractor.js';
 import { RegexExtractor } from './regex-extractor.js';
+import { attachDocumentation } from './doc-comments.js';
+import { findOccurrences } from './occurrences.js';
 import type { FileInfo, ExtractedContext } from '../types/index.js';
 
 /**
@@ -42,9 +44,18 @@ export class ExtractorManager {
   }
   
   /**
-   * Extract context from a file using the appropriate extractor
+   * Extract context from a file using the appropriate extractor, with doc
+   * comments attached to the symbols they document and every identifier
+   * occurrence classified
    */
   public async extract(fileInfo: FileInfo): Promise<ExtractedContext> {
+    const context = await this.extractWithFallback(fileInfo);
+    attachDocumentation(context.symbols, fileInfo.content, fileInfo.language);
+    context.occurrences = findOccurrences(fileInfo.content, fileInfo.language, context);
+    return context;
+  }
+
+  private async extractWithFallback(fileInfo: FileInfo): Promise<ExtractedContext> {
     // First, try to find a specific extractor for the language
     if (fileInfo.language && this.languageMap.has(fileInfo.language)) {
       const extractor = this.languageMap.get(fileInfo.language)!;
@@ -76,6 +87,9 @@ export class ExtractorManager {
       dependencies: [],
       comments: [],
       calls: [],
+      typeRelations: [],
+      typeReferences: [],
+      occurrences: [],
       structure: {}
     };
   }
@@ -0,0 +1,254 @@
+import type { ExtractedContext, Occurrence, OccurrenceRole } from '../types/index.js';
+import { lastSegment } from '../utils/symbols.js';
+
+const HASH_COMMENTS = new Set(['python', 'ruby', 'shell', 'bash', 'yaml', 'toml', 'r', 'perl']);
+const DASH_COMMENTS = new Set(['sql', 'lua', 'haskell']);
+const BACKTICK_STRINGS = new Set(['typescript', 'javascript', 'go']);
+
+// Lines that start an import; brackets left open continue the statement
+const IMPORTS: Record<string, RegExp> = {
+  typescript: /^\s*(?:import\b|export\s+(?:\*|\{[^}]*\})\s*(?:as\s+\w+\s*)?from\b)|\brequire\s*\(/,
+  python: /^\s*(?:import|from)\s/,
+  go: /^\s*import\b/,
+  rust: /^\s*(?:pub(?:\([^)]*\))?\s+)?use\s/,
+  java: /^\s*import\s/,
+  kotlin: /^\s*import\s/,
+  scala: /^\s*import\s/,
+  swift: /^\s*import\s/,
+  csharp: /^\s*(?:global\s+)?using\s+(?:static\s+)?[\w.]+(?:\s*=\s*[\w.<>]+)?\s*;/,
+  c: /^\s*#\s*include\b/,
+  cpp: /^\s*#\s*include\b/,
+  ruby: /^\s*require(?:_relative)?\b/,
+  php: /^\s*use\s/
+};
+
+// Reserved words and literals per language; never worth indexing. Each
+// language gets its own list so `map` in TypeScript or `end` in Go is kept.
+const C_KEYWORDS = 'auto break case char const continue default do double else enum extern float for goto if inline int long ' +
+  'register restrict return short signed sizeof static struct switch typedef union unsigned void volatile while NULL ' +
+  'include define undef ifdef ifndef elif endif pragma';
+const KEYWORDS: Record<string, Set<string>> = {
+  typescript: words('break case catch class const continue debugger default delete do else enum export extends false finally ' +
+    'for function if import in instanceof new null return super switch this throw true try typeof var void while with let ' +
+    'static yield await implements interface package private protected public readonly abstract declare keyof undefined ' +
+    'any unknown never string number boolean bigint symbol'),
+  python: words('False None True and as assert async await break class continue def del elif else except finally for from ' +
+    'global if import in is lambda nonlocal not or pass raise return try while with yield self'),
+  go: words('break case chan const continue default defer else fallthrough for func go goto if import interface map package ' +
+    'range return select struct switch type var true false nil'),
+  rust: words('as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move ' +
+    'mut pub ref return self Self static struct super trait true type unsafe use where while'),
+  java: words('abstract assert boolean break byte case catch char class const continue default do double else enum extends ' +
+    'final finally float for goto if implements import instanceof int interface long native new package private protected ' +
+    'public return short static strictfp super switch synchronized this throw throws transient try var void volatile while ' +
+    'true false null'),
+  kotlin: words('as break class continue do else false for fun if in interface is null object package return super this ' +
+    'throw true try typealias typeof val var when while private protected public internal override abstract final'),
+  scala: words('abstract case catch class def do else extends false final finally for forSome if implicit import lazy match ' +
+    'new null object override package private protected return sealed super this throw trait try true type val var while ' +
+    'with yield'),
+  swift: words('associatedtype class deinit enum extension fileprivate func import init inout internal let open operator ' +
+    'private protocol public rethrows static struct subscript typealias var break case continue default defer do else ' +
+    'fallthrough for guard if in repeat return switch where while as catch false is nil self Self super throw throws true try'),
+  csharp: words('abstract as base bool break byte case catch char checked class const continue decimal default delegate do ' +
+    'double else enum event explicit extern false finally fixed float for foreach goto if implicit in int interface ' +
+    'internal is lock long namespace new null object operator out override params private protected public readonly ref ' +
+    'return sbyte sealed short sizeof stackalloc static string struct switch this throw true try typeof uint ulong ' +
+    'unchecked unsafe ushort using virtual void volatile while var async await'),
+  c: words(C_KEYWORDS),
+  cpp: words(`${C_KEYWORDS} alignas alignof asm bool catch class constexpr const_cast decltype delete dynamic_cast explicit ` +
+    'export false friend mutable namespace new noexcept nullptr operator private protected public reinterpret_cast ' +
+    'static_assert static_cast template this throw true try typeid typename using virtual'),
+  ruby: words('BEGIN END alias and begin break case class def defined do else elsif end ensure false for if in module next ' +
+    'nil not or redo rescue retry return self super then true undef unless until when while yield'),
+  php: words('abstract and array as break callable case catch class clone const continue declare default do echo else ' +
+    'elseif empty enddeclare endfor endforeach endif endswitch endwhile extends final finally fn for foreach function ' +
+    'global goto if implements include include_once instanceof insteadof interface isset list match namespace new or ' +
+    'print private protected public readonly require require_once return static switch throw trait try unset use var ' +
+    'while yield true false null')
+};
+// Languages without a list of their own only drop what nearly every language reserves
+const DEFAULT_KEYWORDS = words('if else for while do switch case return break continue true false null');
+
+/**
+ * Every identifier in the file with what it does there: defined, called,
+ * named as a type, imported, assigned or read. Comments and string literals
+ * are skipped, so prose and log messages don't count as references.
+ */
+export function findOccurrences(content: string, language: string | null, context: ExtractedContext): Occurrence[] {
+  const lines = maskCode(content, language).split('\n');
+  const dialect = language === 'javascript' ? 'typescript' : language ?? '';
+  const importStart = IMPORTS[dialect];
+  const keywords = KEYWORDS[dialect] ?? DEFAULT_KEYWORDS;
+  const identifier = dialect === 'typescript' ? /[A-Za-z_$][\w$]*/g : /[A-Za-z_]\w*/g;
+
+  const definitions = new Set(context.symbols.map(symbol => `${lastSegment(symbol.name)}:${symbol.lineStart}`));
+  const types = new Set([
+    ...context.typeReferences.map(reference => `${reference.name}:${reference.line}`),
+    ...context.typeRelations.map(relation => `${relation.relatedName}:${relation.line}`)
+  ]);
+
+  const occurrences: Occurrence[] = [];
+  let openImport = 0;
+  for (let index = 0; index < lines.length; index++) {
+    const line = lines[index];
+    const lineNumber = index + 1;
+
+    const inImport = openImport > 0 || (importStart?.test(line) ?? false);
+    openImport = inImport ? Math.max(0, openImport + bracketBalance(line)) : 0;
+
+    identifier.lastIndex = 0;
+    let match: RegExpExecArray | null;
+    while ((match = identifier.exec(line)) !== null) {
+      const name = match[0];
+      const column = match.index;
+      // Suffixes of numeric literals like 0x1F or 1e10
+      if (keywords.has(name) || /\d/.test(line[column - 1] ?? '')) {
+        continue;
+      }
+      const after = line.slice(column + name.length).trimStart();
+      // String prefixes like rb"..." or f"..."
+      if (dialect === 'python' && /^["']/.test(after)) {
+        continue;
+      }
+
+      const key = `${name}:${lineNumber}`;
+      let role: OccurrenceRole;
+      if (definitions.has(key)) {
+        // Only the first occurrence on the line is the declaration
+        definitions.delete(key);
+        role = 'definition';
+      } else if (inImport) {
+        role = 'import';
+      } else if (types.has(key)) {
+        role = 'type';
+      } else if (/^(?:!\s*)?\(/.test(after)) {
+        role = 'call';
+      } else if (isWrite(line.slice(0, column).trimEnd(), after)) {
+        role = 'write';
+      } else {
+        role = 'read';
+      }
+      occurrences.push({ name, role, line: lineNumber, column });
+    }
+  }
+  return occurrences;
+}
+
+function words(list: string): Set<string> {
+  return new Set(list.split(' '));
+}
+
+function bracketBalance(line: string): number {
+  return (line.match(/[({]/g)?.length ?? 0) - (line.match(/[)}]/g)?.length ?? 0);
+}
+
+function isWrite(before: string, after: string): boolean {
+  return /^(?:\*\*|<<|>>|\?\?|\|\||&&|[-+*/%&|^:])?=(?![=>])/.test(after) ||
+    /^(?:\+\+|--)/.test(after) ||
+    /(?:\+\+|--)$/.test(before) ||
+    /\b(?:let|const|var|val)$/.test(before);
+}
+
+/**
+ * The source with comments and string literals blanked out; lines and columns
+ * are unchanged. Code inside template literal `${...}` is kept.
+ */
+function maskCode(content: string, language: string | null): string {
+  const hashComments = HASH_COMMENTS.has(language ?? '');
+  const dashComments = DASH_COMMENTS.has(language ?? '');
+  const backticks = BACKTICK_STRINGS.has(language ?? '');
+  const chars = content.split('');
+  const blank = (from: number, to: number) => {
+    for (let index = from; index < to && index < chars.length; index++) {
+      if (chars[index] !== '\n') {
+        chars[index] = ' ';
+      }
+    }
+  };
+  // Brace depth of each open template literal substitution
+  const templates: number[] = [];
+
+  let index = 0;
+  while (index < content.length) {
+    const char = content[index];
+    const next = content[index + 1];
+
+    if (templates.length > 0 && (char === '{' || char === '}')) {
+      if (char === '{') {
+        templates[templates.length - 1]++;
+      } else if (--templates[templates.length - 1] === 0) {
+        // Back inside the template literal
+        templates.pop();
+        index = skipString(content, index + 1, '`', language, templates, blank);
+        continue;
+      }
+      index++;
+      continue;
+    }
+
+    if ((hashComments && char === '#') || (dashComments && char === '-' && next === '-') ||
+        (!hashComments && !dashComments && char === '/' && next === '/')) {
+      const end = content.indexOf('\n', index);
+      const stop = end === -1 ? content.length : end;
+      blank(index, stop);
+      index = stop;
+    } else if (!hashComments && char === '/' && next === '*') {
+      const end = content.indexOf('*/', index + 2);
+      const stop = end === -1 ? content.length : end + 2;
+      blank(index, stop);
+      index = stop;
+    } else if (language === 'python' && (content.startsWith('"""', index) || content.startsWith("'''", index))) {
+      const end = content.indexOf(content.slice(index, index + 3), index + 3);
+      const stop = end === -1 ? content.length : end + 3;
+      blank(index, stop);
+      index = stop;
+    } else if (char === "'" && language === 'rust') {
+      // Lifetimes use a lone quote; only char literals are closed
+      const literal = content.slice(index, index + 12).match(/^'(?:\\[^']*|[^'\\\n])'/);
+      if (literal) {
+        blank(index, index + literal[0].length);
+      }
+      index += literal?.[0].length ?? 1;
+    } else if (char === '"' || char === "'" || (char === '`' && backticks)) {
+      index = skipString(content, index + 1, char, language, templates, blank, index);
+    } else {
+      index++;
+    }
+  }
+  return chars.join('');
+}
+
+// Blank a string literal from `start` and return the index after it
+function skipString(
+  content: string,
+  index: number,
+  quote: string,
+  language: string | null,
+  templates: number[],
+  blank: (from: number, to: number) => void,
+  start = index
+): number {
+  const substitutions = quote === '`' && (language === 'typescript' || language === 'javascript');
+  while (index < content.length) {
+    const char = content[index];
+    if (char === '\\') {
+      index += 2;
+    } else if (char === quote) {
+      blank(start, index + 1);
+      return index + 1;
+    } else if (substitutions && char === '$' && content[index + 1] === '{') {
+      blank(start, index + 2);
+      templates.push(1);
+      return index + 2;
+    } else if (char === '\n' && quote !== '`') {
+      // Unterminated; don't swallow the rest of the file
+      blank(start, index);
+      return index;
+    } else {
+      index++;
+    }
+  }
+  blank(start, content.length);
+  return content.length;
+}
\ No newline at end of file
@@ -1,5 +1,7 @@
 import { BaseExtractor } from './base.js';
-import type { FileInfo, ExtractedContext, Symbol, CallReference } from '../types/index.js';
+import { baseTypeName } from './type-relations.js';
+import { findTypeReferences } from './type-references.js';
+import type { FileInfo, ExtractedContext, Symbol, CallReference, TypeRelation } from '../types/index.js';
 import type { StructureCategory, SymbolDetail } from './types.js';
 
 export class PythonExtractor extends BaseExtractor {
@@ -25,14 +27,20 @@ export class PythonExtractor extends BaseExtractor {
       dependencies: [],
       comments: [],
       calls: [],
+      typeRelations: [],
+      typeReferences: [],
+      occurrences: [],
       structure: {}
     };
     
     // Extract functions and methods
     this.extractFunctions(context.symbols);
     
-    // Extract classes
-    this.extractClasses(context.symbols);
+    // Extract classes and their base classes
+    this.extractClasses(context.symbols, context.typeRelations);
+
+    // Extract type hints
+    context.typeReferences = findTypeReferences(this.content, 'python');
     
     // Extract imports
     this.extractImports(context.imports, context.dependencies);
@@ -54,7 +62,7 @@ export class PythonExtractor extends BaseExtractor {
   
   private extractFunctions(symbols: Symbol[]): void {
     // Match regular functions and async functions
-    const functionPattern = /^(\s*)(?:async\s+)?def\s+(\w+)\s*\(([^)]*)\)\s*(?:->\s*([^:]+))?\s*:/gm;
+    const functionPattern = /^([ \t]*)(?:async\s+)?def\s+(\w+)\s*\(([^)]*)\)\s*(?:->\s*([^:]+))?\s*:/gm;
     
     let match;
     while ((match = functionPattern.exec(this.content)) !== null) {
@@ -106,8 +114,8 @@ export class PythonExtractor extends BaseExtractor {
     }
   }
   
-  private extractClasses(symbols: Symbol[]): void {
-    const classPattern = /^(\s*)class\s+(\w+)(?:\s*\(([^)]*)\))?\s*:/gm;
+  private extractClasses(symbols: Symbol[], relations: TypeRelation[]): void {
+    const classPattern = /^([ \t]*)class\s+(\w+)(?:\s*\(([^)]*)\))?\s*:/gm;
     
     let match;
     while ((match = classPattern.exec(this.content)) !== null) {
@@ -121,6 +129,13 @@ export class PythonExtractor extends BaseExtractor {
       if (bases) {
         signature += `(${bases})`;
       }
+
+      // Keyword arguments such as metaclass= aren't bases
+      for (const base of bases.split(',').map(b => b.trim())) {
+        if (base && !base.includes('=') && base !== 'object') {
+          relations.push({ typeName: name, relatedName: baseTypeName(base), kind: 'extends', line: lineStart });
+        }
+      }
       
       // Extract class members
       const methods: string[] = [];
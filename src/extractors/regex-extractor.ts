@@ -1,4 +1,6 @@
 import { BaseExtractor } from './base.js';
+import { findTypeRelations } from './type-relations.js';
+import { findTypeReferences } from './type-references.js';
 import type { FileInfo, ExtractedContext, Symbol, CallReference } from '../types/index.js';
 import type { StructureCategory, SymbolDetail } from './types.js';
 
@@ -451,6 +453,9 @@ export class RegexExtractor extends BaseExtractor {
       dependencies: [],
       comments: [],
       calls: [],
+      typeRelations: [],
+      typeReferences: [],
+      occurrences: [],
       structure: {}
     };
     
@@ -517,6 +522,10 @@ export class RegexExtractor extends BaseExtractor {
     
     // Extract function calls
     context.calls = this.extractFunctionCalls(language);
+
+    // Extract extends/implements/embeds edges and type references
+    context.typeRelations = findTypeRelations(this.content, fileInfo.language);
+    context.typeReferences = findTypeReferences(this.content, fileInfo.language);
     
     // Build structure
     context.structure = this.buildStructure(context.symbols);
@@ -1,5 +1,7 @@
 import { Parser, Language, Node } from 'web-tree-sitter';
 import { BaseExtractor } from './base.js';
+import { findTypeRelations } from './type-relations.js';
+import { findTypeReferences } from './type-references.js';
 import type { FileInfo, ExtractedContext, Symbol, CallReference } from '../types/index.js';
 import type { StructureCategory, SymbolDetail } from './types.js';
 // Note: File path utilities would be used when loading WASM files
@@ -140,6 +142,9 @@ export class TreeSitterExtractor extends BaseExtractor {
       dependencies: [],
       comments: [],
       calls: [],
+      typeRelations: [],
+      typeReferences: [],
+      occurrences: [],
       structure: {}
     };
     
@@ -182,6 +187,10 @@ export class TreeSitterExtractor extends BaseExtractor {
       
       // Extract function calls
       this.extractCalls(rootNode, config.queries.calls, context.calls);
+
+      // Extract extends/implements/embeds edges and type references
+      context.typeRelations = findTypeRelations(this.content, fileInfo.language);
+      context.typeReferences = findTypeReferences(this.content, fileInfo.language);
       
       // Extract comments
       this.extractTreeSitterComments(rootNode, context.comments);
@@ -364,14 +373,20 @@ export class TreeSitterExtractor extends BaseExtractor {
   }
   
   private extractCallName(node: Node): string | null {
+    // Java names the invoked method in a field of its own
+    if (node.type === 'method_invocation') {
+      const name = node.childForFieldName('name');
+      return name ? this.content.substring(name.startIndex, name.endIndex) : null;
+    }
+
     // Look for function/method name in call expression
     for (let i = 0; i < node.childCount; i++) {
       const child = node.child(i);
-      if (child && (child.type === 'identifier' || child.type === 'field_expression')) {
+      if (child && (child.type === 'identifier' || child.type === 'field_expression' || child.type === 'selector_expression')) {
         if (child.type === 'identifier') {
           return this.content.substring(child.startIndex, child.endIndex);
-        } else if (child.type === 'field_expression') {
-          // Handle method calls like obj.method()
+        } else {
+          // Handle method calls like obj.method() and Go's pkg.Func() / recv.Method()
           const fieldName = this.findLastIdentifier(child);
           if (fieldName) {
             return this.content.substring(fieldName.startIndex, fieldName.endIndex);
@@ -405,10 +420,14 @@ export class TreeSitterExtractor extends BaseExtractor {
       return 'constructor';
     }
     
+    if (node.type === 'method_invocation' && node.childForFieldName('object')) {
+      return 'method';
+    }
+
     // Check if it's a method call
     for (let i = 0; i < node.childCount; i++) {
       const child = node.child(i);
-      if (child && (child.type === 'field_expression' || child.type === 'member_expression')) {
+      if (child && (child.type === 'field_expression' || child.type === 'member_expression' || child.type === 'selector_expression')) {
         return 'method';
       }
     }
@@ -483,6 +502,9 @@ export class TreeSitterExtractor extends BaseExtractor {
       dependencies: [],
       comments: this.extractComments(),
       calls: [],
+      typeRelations: [],
+      typeReferences: [],
+      occurrences: [],
       structure: {}
     };
     
@@ -506,6 +528,9 @@ export class TreeSitterExtractor extends BaseExtractor {
         }
       });
     }
+
+    context.typeRelations = findTypeRelations(this.content, fileInfo.language);
+    context.typeReferences = findTypeReferences(this.content, fileInfo.language);
     
     context.structure = this.buildStructure(context.symbols);
     
@@ -577,11 +602,13 @@ export class TreeSitterExtractor extends BaseExtractor {
     while ((match = pattern.exec(this.content)) !== null) {
       if (match[1]) {
         const lineStart = this.getLineNumber(match.index);
+        // Brace-delimited bodies can be measured; anything else stays a one-line symbol
+        const lineEnd = this.lines[lineStart - 1].includes('{') ? this.findBlockEnd(lineStart) : lineStart;
         symbols.push({
           name: match[1],
           type,
           lineStart,
-          lineEnd: lineStart,
+          lineEnd,
           signature: match[0].trim(),
           metadata: {}
         });
@@ -0,0 +1,212 @@
+import { splitTypeList } from './type-relations.js';
+import type { TypeReference, TypeReferenceKind } from '../types/index.js';
+
+type ParameterStyle = 'colon' | 'prefix' | 'go';
+
+interface SignatureRule {
+  // Group 1 is the function name; the match ends at the opening parenthesis
+  pattern: RegExp;
+  params: ParameterStyle;
+  // Return type after the parameter list, or before the name for C-style languages
+  returns: 'arrow' | 'colon' | 'go' | 'prefix';
+}
+
+const C_STYLE: SignatureRule = {
+  pattern: /^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:public|private|protected|internal|static|final|abstract|synchronized|native|override|virtual|async|sealed|default|inline|extern|unsafe|partial)\s+)*(?:<[^>]*>\s*)?[\w.<>[\]?,]+(?:\s*<[^>]*>)?[\s*&]+(\w+)\s*\(/,
+  params: 'prefix',
+  returns: 'prefix'
+};
+
+const SIGNATURES: Record<string, SignatureRule> = {
+  python: { pattern: /^\s*(?:async\s+)?def\s+(\w+)\s*\(/, params: 'colon', returns: 'arrow' },
+  go: { pattern: /^\s*func\s+(?:\([^)]*\)\s*)?(\w+)\s*(?:\[[^\]]*\])?\s*\(/, params: 'go', returns: 'go' },
+  rust: { pattern: /\bfn\s+(\w+)\s*(?:<[^>]*>)?\s*\(/, params: 'colon', returns: 'arrow' },
+  swift: { pattern: /\bfunc\s+(\w+)\s*(?:<[^>]*>)?\s*\(/, params: 'colon', returns: 'arrow' },
+  kotlin: { pattern: /\bfun\s+(?:<[^>]*>\s*)?(?:[\w.]+\.)?(\w+)\s*\(/, params: 'colon', returns: 'colon' },
+  scala: { pattern: /\bdef\s+(\w+)\s*(?:\[[^\]]*\])?\s*\(/, params: 'colon', returns: 'colon' },
+  typescript: { pattern: /\bfunction\s*\*?\s*(\w+)\s*(?:<[^>]*>)?\s*\(/, params: 'colon', returns: 'colon' },
+  java: C_STYLE,
+  csharp: C_STYLE,
+  cpp: C_STYLE,
+  c: C_STYLE
+};
+
+// Annotated fields and variables; group 1 is the type
+const ANNOTATIONS: Record<string, RegExp[]> = {
+  python: [/^\s*(?:self\.)?[A-Za-z_]\w*\s*:\s*([^=]+?)\s*(?:=.*)?$/],
+  go: [/^\s*var\s+\w+(?:\s*,\s*\w+)*\s+([^=]+?)\s*(?:=.*)?$/],
+  rust: [/\blet\s+(?:mut\s+)?\w+\s*:\s*([^=;]+)/, /^\s*(?:pub(?:\([^)]*\))?\s+)?\w+\s*:\s*([^,{}=]+),?\s*$/],
+  swift: [/\b(?:let|var)\s+\w+\s*:\s*([^={]+)/],
+  kotlin: [/\b(?:val|var)\s+\w+\s*:\s*([^={]+)/],
+  scala: [/\b(?:val|var)\s+\w+\s*:\s*([^={]+)/],
+  typescript: [/\b(?:let|var|const)\s+\w+\s*:\s*([^=;]+)/],
+  java: [/^\s*(?:(?:public|private|protected|static|final|transient|volatile)\s+)+([\w.<>[\]?, ]+?)\s+\w+\s*(?:=[^=].*)?;\s*$/],
+  csharp: [/^\s*(?:(?:public|private|protected|internal|static|readonly|const|volatile)\s+)+([\w.<>[\]?, ]+?)\s+\w+\s*(?:=[^=].*)?;\s*$/]
+};
+
+// Python statements that look like `name: type`
+const PYTHON_BLOCKS = /^\s*(?:if|elif|else|for|while|try|except|finally|with|def|class|return|lambda|match|case)\b/;
+
+// Primitives, builtins and type-position keywords; nothing to look up for these
+const IGNORED = new Set([
+  'int', 'str', 'float', 'bool', 'bytes', 'None', 'object', 'list', 'dict', 'set', 'tuple', 'type', 'complex',
+  'string', 'number', 'boolean', 'void', 'any', 'unknown', 'never', 'null', 'undefined', 'symbol', 'bigint',
+  'error', 'byte', 'rune', 'uint', 'uintptr', 'int8', 'int16', 'int32', 'int64', 'uint8', 'uint16', 'uint32', 'uint64',
+  'float32', 'float64', 'complex64', 'complex128', 'char', 'short', 'long', 'double', 'unsigned', 'signed',
+  'usize', 'isize', 'u8', 'u16', 'u32', 'u64', 'u128', 'i8', 'i16', 'i32', 'i64', 'i128', 'f32', 'f64',
+  'self', 'Self', 'mut', 'dyn', 'impl', 'const', 'struct', 'func', 'chan', 'map', 'interface', 'final',
+  'in', 'out', 'ref', 'params', 'var', 'let', 'val', 'static', 'inout', 'some', 'where', 'true', 'false',
+  'readonly', 'keyof', 'typeof', 'extends', 'infer', 'is', 'asserts', 'this', 'super', 'new', 'throws'
+]);
+
+/**
+ * Types named in signatures and annotations, for extractors without a typed
+ * AST: parameter and return types, annotated fields and variables, and the
+ * generic arguments inside them. Type parameters and builtins are skipped.
+ */
+export function findTypeReferences(content: string, language: string | null): TypeReference[] {
+  const rule = language ? SIGNATURES[language === 'javascript' ? 'typescript' : language] : undefined;
+  const annotations = language ? ANNOTATIONS[language === 'javascript' ? 'typescript' : language] ?? [] : [];
+  const lines = content.split('\n');
+  const references: TypeReference[] = [];
+  const add = (typeText: string, kind: TypeReferenceKind, line: number) => {
+    references.push(...typeNames(typeText, kind, line));
+  };
+
+  let goStruct = false;
+  for (let index = 0; index < lines.length; index++) {
+    const line = lines[index];
+    const lineNumber = index + 1;
+
+    if (language === 'go') {
+      // Struct fields are `Name Type`; embedded fields are supertype edges, not references
+      if (goStruct) {
+        if (/^\s*}/.test(line)) {
+          goStruct = false;
+        } else {
+          const field = line.replace(/`.*$|\/\/.*$/, '').match(/^\s*\w+(?:\s*,\s*\w+)*\s+(\S.*?)\s*$/);
+          if (field) {
+            add(field[1], 'annotation', lineNumber);
+          }
+          continue;
+        }
+      }
+      if (/^\s*type\s+\w+(?:\[[^\]]*\])?\s+struct\s*\{\s*$/.test(line)) {
+        goStruct = true;
+        continue;
+      }
+    }
+
+    const signature = rule && line.match(rule.pattern);
+    if (rule && signature && !/^\s*(?:return|new|else|throw)\b/.test(line)) {
+      const header = lines.slice(index, index + 10).join('\n');
+      const open = header.indexOf('(', (signature.index ?? 0) + signature[0].length - 1);
+      const close = matchingParen(header, open);
+      if (close > open) {
+        for (const parameter of splitTypeList(header.slice(open + 1, close))) {
+          const typeText = parameterType(parameter, rule.params, header.slice(open + 1, close));
+          if (typeText) {
+            add(typeText, 'parameter', lineNumber);
+          }
+        }
+        const returnType = returnTypeText(rule.returns, header.slice(close + 1), signature[0].slice(0, signature[0].lastIndexOf(signature[1])));
+        if (returnType) {
+          add(returnType, 'return', lineNumber);
+        }
+        // Parameters on the following lines are done
+        index += header.slice(0, close).split('\n').length - 1;
+        continue;
+      }
+    }
+
+    if (language === 'python' && PYTHON_BLOCKS.test(line)) {
+      continue;
+    }
+    for (const pattern of annotations) {
+      const annotation = line.match(pattern);
+      if (annotation) {
+        add(annotation[1], 'annotation', lineNumber);
+        break;
+      }
+    }
+  }
+  return references;
+}
+
+/**
+ * Type names in a type expression. Names nested in generic arguments
+ * (`List[User]`, `Map<string, User>`) are reported as `generic`.
+ */
+function typeNames(typeText: string, kind: TypeReferenceKind, line: number): TypeReference[] {
+  const references: TypeReference[] = [];
+  let depth = 0;
+  const pattern = /[A-Za-z_$][\w$]*(?:(?:\.|::)[A-Za-z_$][\w$]*)*|[<>[\]]/g;
+  let match: RegExpExecArray | null;
+  while ((match = pattern.exec(typeText)) !== null) {
+    const token = match[0];
+    // `List[User]` is generic, Go's `[]User` isn't
+    if (token === '<' || (token === '[' && /[\w$]/.test(typeText[match.index - 1] ?? ''))) {
+      depth++;
+    } else if ((token === '>' || token === ']') && depth > 0) {
+      depth--;
+    } else if (!'<>[]'.includes(token)) {
+      const name = token.split(/\.|::/).pop()!;
+      // Single letters are type parameters
+      if (name.length > 1 && !IGNORED.has(name)) {
+        references.push({ name, kind: depth > 0 ? 'generic' : kind, line });
+      }
+    }
+  }
+  return references;
+}
+
+function matchingParen(text: string, open: number): number {
+  let depth = 0;
+  for (let index = open; index >= 0 && index < text.length; index++) {
+    if (text[index] === '(') {
+      depth++;
+    } else if (text[index] === ')' && --depth === 0) {
+      return index;
+    }
+  }
+  return -1;
+}
+
+function parameterType(parameter: string, style: ParameterStyle, allParameters: string): string | null {
+  const text = parameter.replace(/=.*$/s, '').trim();
+  switch (style) {
+    case 'colon': {
+      const colon = text.indexOf(':');
+      return colon >= 0 ? text.slice(colon + 1) : null;
+    }
+    case 'go': {
+      const parts = text.split(/\s+/);
+      if (parts.length > 1) {
+        return parts.slice(1).join(' ');
+      }
+      // In `a, b int` the bare names share the type named later
+      return /\w\s+\S/.test(allParameters) ? null : text;
+    }
+    case 'prefix': {
+      const cleaned = text.replace(/@\w+(?:\([^)]*\))?/g, '').replace(/\b(?:final|this|params|ref|out|in|const)\s+/g, '').trim();
+      const name = cleaned.match(/[\w$]+\s*(?:\[\s*\])*$/);
+      return name && name.index! > 0 ? cleaned.slice(0, name.index) : null;
+    }
+  }
+}
+
+function returnTypeText(style: SignatureRule['returns'], afterParameters: string, beforeName: string): string | null {
+  switch (style) {
+    case 'arrow':
+      return afterParameters.match(/^\s*->\s*([^{:;\n]+?)\s*(?:\bwhere\b|[{:;\n]|$)/)?.[1] ?? null;
+    case 'colon':
+      return afterParameters.match(/^\s*:\s*([^{=;\n]+?)\s*(?:[{=;\n]|$)/)?.[1] ?? null;
+    case 'go':
+      return afterParameters.match(/^[ \t]*([^{\n]+?)[ \t]*(?:\{|\n|$)/)?.[1] ?? null;
+    case 'prefix':
+      return beforeName
+        .replace(/@\w+(?:\([^)]*\))?/g, '')
+        .replace(/\b(?:public|private|protected|internal|static|final|abstract|synchronized|native|override|virtual|async|sealed|default|inline|extern|unsafe|partial)\s+/g, '')
+        .replace(/^\s*<[^>]*>/, '');
+  }
+}
\ No newline at end of file
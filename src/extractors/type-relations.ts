@@ -0,0 +1,197 @@
+import type { TypeRelation, TypeRelationKind } from '../types/index.js';
+
+// Declaration headers can wrap; this many lines after the keyword are read as one header
+const MAX_HEADER_LINES = 5;
+
+/**
+ * Bare type name from a reference as written: generic arguments, qualifiers,
+ * pointers and nullability removed (`pkg.Reader`, `Base<T>`, `*Node` ->
+ * `Reader`, `Base`, `Node`).
+ */
+export function baseTypeName(reference: string): string {
+  const name = withoutTypeArguments(reference.trim()).replace(/\(.*\)$/, '').replace(/^[*&]+|[?!]+$/g, '').trim();
+  const parts = name.split(/::|\.|\\/);
+  return parts[parts.length - 1].trim();
+}
+
+/**
+ * Supertype edges found in source text, for extractors without an AST:
+ * `extends` / `implements` clauses, `:` base lists, Go struct and interface
+ * embedding and Rust `impl Trait for Type`.
+ */
+export function findTypeRelations(content: string, language: string | null): TypeRelation[] {
+  const lines = content.split('\n');
+  const relations: TypeRelation[] = [];
+  const add = (typeName: string, reference: string, kind: TypeRelationKind, line: number) => {
+    const relatedName = baseTypeName(reference);
+    if (relatedName && /^[A-Za-z_$][\w$]*$/.test(relatedName) && relatedName !== typeName) {
+      relations.push({ typeName, relatedName, kind, line });
+    }
+  };
+  // The header from the declaration keyword up to the opening brace
+  const header = (index: number) => {
+    const text = lines.slice(index, index + MAX_HEADER_LINES).join(' ');
+    const brace = text.indexOf('{');
+    return (brace >= 0 ? text.slice(0, brace) : lines[index]).replace(/\s+/g, ' ');
+  };
+
+  for (let index = 0; index < lines.length; index++) {
+    const line = lines[index];
+    const lineNumber = index + 1;
+
+    switch (language) {
+      case 'java':
+      case 'php':
+      case 'typescript':
+      case 'javascript': {
+        const match = line.match(/\b(class|interface|enum|record)\s+(\w+)/);
+        if (!match) {
+          break;
+        }
+        // Bounds like `<T extends Comparable<T>>` aren't supertypes
+        const text = withoutTypeArguments(header(index));
+        const extendsList = text.match(/\bextends\s+(.+?)(?=\bimplements\b|$)/)?.[1];
+        const implementsList = text.match(/\bimplements\s+(.+)$/)?.[1];
+        for (const reference of splitTypeList(extendsList)) {
+          add(match[2], reference, 'extends', lineNumber);
+        }
+        for (const reference of splitTypeList(implementsList)) {
+          add(match[2], reference, 'implements', lineNumber);
+        }
+        break;
+      }
+
+      case 'csharp':
+      case 'kotlin':
+      case 'swift':
+      case 'cpp': {
+        const match = line.match(/\b(class|interface|struct|record|object|protocol|extension)\s+(\w+)/);
+        if (!match) {
+          break;
+        }
+        // Base list after the name and any generic parameters or primary constructor
+        const full = header(index);
+        const text = full.slice(full.indexOf(match[0]) + match[0].length);
+        const bases = text.match(/^\s*(?:<[^{]*?>)?\s*(?:\([^)]*\))?\s*(?:final\s*)?:\s*([^{]+?)(?:\bwhere\b|$)/)?.[1];
+        splitTypeList(bases).forEach((reference, position) => {
+          const cleaned = reference.replace(/^((public|protected|private|virtual)\s+)+/, '');
+          add(match[2], cleaned, baseKind(language, match[1], cleaned, position), lineNumber);
+        });
+        break;
+      }
+
+      case 'scala': {
+        const match = line.match(/\b(class|trait|object)\s+(\w+)/);
+        const text = match ? header(index) : '';
+        const extended = text.match(/\bextends\s+([\w.[\]]+)/)?.[1];
+        if (match && extended) {
+          add(match[2], extended, 'extends', lineNumber);
+          for (const mixin of text.matchAll(/\bwith\s+([\w.[\]]+)/g)) {
+            add(match[2], mixin[1], 'implements', lineNumber);
+          }
+        }
+        break;
+      }
+
+      case 'ruby': {
+        const match = line.match(/^\s*class\s+([\w:]+)\s*<\s*([\w:]+)/);
+        if (match) {
+          add(baseTypeName(match[1]), match[2], 'extends', lineNumber);
+        }
+        break;
+      }
+
+      case 'go': {
+        const match = line.match(/^\s*type\s+(\w+)(?:\[[^\]]*\])?\s+(struct|interface)\s*\{/);
+        if (!match) {
+          break;
+        }
+        // Embedded fields are a bare (possibly qualified or pointer) type on their own line
+        for (let inner = index + 1; inner < lines.length; inner++) {
+          const field = lines[inner].replace(/\/\/.*$/, '').trim();
+          if (field.startsWith('}')) {
+            break;
+          }
+          const embedded = field.match(/^\*?([\w.]+(?:\[[^\]]*\])?)$/);
+          if (embedded) {
+            add(match[1], embedded[1], match[2] === 'interface' ? 'extends' : 'embeds', inner + 1);
+          }
+        }
+        break;
+      }
+
+      case 'rust': {
+        const impl = line.match(/^\s*(?:unsafe\s+)?impl\s*(?:<.*?>)?\s+(?:!)?([\w:]+(?:<.*?>)?)\s+for\s+([\w:]+)/);
+        if (impl) {
+          add(baseTypeName(impl[2]), impl[1], 'implements', lineNumber);
+          break;
+        }
+        const trait = line.match(/^\s*(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?trait\s+(\w+)(?:<[^>]*>)?\s*:\s*([^{]+)/);
+        if (trait) {
+          for (const reference of trait[2].replace(/\bwhere\b.*$/, '').split('+')) {
+            // Lifetime bounds aren't types
+            if (!reference.trim().startsWith("'")) {
+              add(trait[1], reference, 'extends', lineNumber);
+            }
+          }
+        }
+        break;
+      }
+    }
+  }
+  return relations;
+}
+
+// Drops generic arguments, innermost first so nested ones go too
+function withoutTypeArguments(text: string): string {
+  let result = text;
+  let previous = '';
+  while (previous !== result) {
+    previous = result;
+    result = result.replace(/<[^<>]*>/g, '').replace(/\[[^[\]]*\]/g, '');
+  }
+  return result;
+}
+
+// Splits "A, B<C, D>, E" at top-level commas
+export function splitTypeList(list: string | undefined): string[] {
+  if (!list) {
+    return [];
+  }
+  const parts: string[] = [];
+  let depth = 0;
+  let current = '';
+  for (const char of list) {
+    if (char === '<' || char === '(' || char === '[') {
+      depth++;
+    } else if (char === '>' || char === ')' || char === ']') {
+      depth--;
+    }
+    if (char === ',' && depth === 0) {
+      parts.push(current.trim());
+      current = '';
+    } else {
+      current += char;
+    }
+  }
+  parts.push(current.trim());
+  return parts.filter(Boolean);
+}
+
+// `:` lists mix a base class with interfaces; tell them apart by each language's conventions
+function baseKind(language: string, keyword: string, reference: string, position: number): TypeRelationKind {
+  if (keyword === 'interface' || keyword === 'protocol') {
+    return 'extends';
+  }
+  switch (language) {
+    case 'csharp':
+      return /^I[A-Z]/.test(baseTypeName(reference)) ? 'implements' : 'extends';
+    case 'kotlin':
+      // Superclasses are called with constructor arguments; interfaces aren't
+      return /\)\s*$/.test(reference) ? 'extends' : 'implements';
+    case 'swift':
+      return keyword === 'class' && position === 0 ? 'extends' : 'implements';
+    default:
+      return 'extends';
+  }
+}
\ No newline at end of file
@@ -5,7 +5,9 @@ import type { NodePath } from '@babel/traverse';
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
 const traverse = (_traverse as any)?.default || _traverse;
 import { BaseExtractor } from './base.js';
-import type { FileInfo, ExtractedContext, Symbol, CallReference } from '../types/index.js';
+import { findTypeRelations } from './type-relations.js';
+import { findTypeReferences } from './type-references.js';
+import type { FileInfo, ExtractedContext, Symbol, CallReference, TypeRelation, TypeReference, TypeReferenceKind } from '../types/index.js';
 import type { 
   BabelNode, 
   BabelIdentifier, 
@@ -42,6 +44,9 @@ export class TypeScriptExtractor extends BaseExtractor {
       dependencies: [],
       comments: [],
       calls: [],
+      typeRelations: [],
+      typeReferences: [],
+      occurrences: [],
       structure: {}
     };
     
@@ -82,15 +87,15 @@ export class TypeScriptExtractor extends BaseExtractor {
           }
         },
         ClassDeclaration: (path: NodePath) => {
-          this.extractClass(path.node as unknown as BabelNode, context.symbols);
+          this.extractClass(path.node as unknown as BabelNode, context.symbols, context.typeRelations);
         },
         ClassExpression: (path: NodePath) => {
           if (path.parent.type === 'VariableDeclarator' && path.parent.id.type === 'Identifier') {
-            this.extractClass(path.node as unknown as BabelNode, context.symbols, path.parent.id.name);
+            this.extractClass(path.node as unknown as BabelNode, context.symbols, context.typeRelations, path.parent.id.name);
           }
         },
         TSInterfaceDeclaration: (path: NodePath) => {
-          this.extractInterface(path.node as unknown as BabelTSNode, context.symbols);
+          this.extractInterface(path.node as unknown as BabelTSNode, context.symbols, context.typeRelations);
         },
         TSTypeAliasDeclaration: (path: NodePath) => {
           this.extractTypeAlias(path.node as unknown as BabelTSNode, context.symbols);
@@ -132,6 +137,9 @@ export class TypeScriptExtractor extends BaseExtractor {
         },
         NewExpression: (path: NodePath) => {
           this.extractNewExpression(path.node as unknown as BabelNode, context.calls);
+        },
+        TSTypeReference: (path: NodePath) => {
+          this.extractTypeReference(path, context.typeReferences);
         }
       });
       
@@ -206,7 +214,7 @@ export class TypeScriptExtractor extends BaseExtractor {
     });
   }
   
-  private extractClass(node: BabelNode, symbols: Symbol[], name?: string): void {
+  private extractClass(node: BabelNode, symbols: Symbol[], relations: TypeRelation[], name?: string): void {
     const className = name || node.id?.name;
     if (!className) return;
     
@@ -215,8 +223,17 @@ export class TypeScriptExtractor extends BaseExtractor {
     
     let signature = `class ${className}`;
     if (node.superClass) {
-      const superName = node.superClass.type === 'Identifier' ? node.superClass.name : 'unknown';
-      signature += ` extends ${superName}`;
+      const superName = this.referenceName(node.superClass);
+      signature += ` extends ${superName || 'unknown'}`;
+      if (superName) {
+        relations.push({ typeName: className, relatedName: superName, kind: 'extends', line: lineStart });
+      }
+    }
+    for (const implemented of (node.implements as BabelTSExpressionWithTypeArguments[] | undefined) || []) {
+      const interfaceName = this.referenceName(implemented.expression);
+      if (interfaceName) {
+        relations.push({ typeName: className, relatedName: interfaceName, kind: 'implements', line: implemented.loc?.start.line || lineStart });
+      }
     }
     
     const methods: string[] = [];
@@ -266,7 +283,7 @@ export class TypeScriptExtractor extends BaseExtractor {
     });
   }
   
-  private extractInterface(node: BabelTSNode, symbols: Symbol[]): void {
+  private extractInterface(node: BabelTSNode, symbols: Symbol[], relations: TypeRelation[]): void {
     const name = node.id.name;
     const lineStart = node.loc?.start.line || 1;
     const lineEnd = node.loc?.end.line || lineStart;
@@ -277,6 +294,12 @@ export class TypeScriptExtractor extends BaseExtractor {
         (e.expression as BabelIdentifier).name
       ).join(', ');
       signature += ` extends ${extendsList}`;
+      for (const extended of node.extends) {
+        const extendedName = this.referenceName(extended.expression);
+        if (extendedName) {
+          relations.push({ typeName: name, relatedName: extendedName, kind: 'extends', line: extended.loc?.start.line || lineStart });
+        }
+      }
     }
     
     const interfaceBody = node.body as { body: BabelTSPropertySignature[] };
@@ -300,6 +323,40 @@ export class TypeScriptExtractor extends BaseExtractor {
     });
   }
   
+  private extractTypeReference(path: NodePath, references: TypeReference[]): void {
+    const node = path.node as unknown as BabelNode;
+    const name = this.referenceName(node.typeName as BabelNode | undefined);
+    if (name) {
+      references.push({ name, kind: this.referenceKind(path), line: node.loc?.start.line || 1 });
+    }
+  }
+  
+  // Nested in type arguments, a parameter or return annotation, or any other annotation
+  private referenceKind(path: NodePath): TypeReferenceKind {
+    for (let current = path.parentPath; current; current = current.parentPath) {
+      if (current.node.type === 'TSTypeParameterInstantiation') return 'generic';
+      if (current.node.type === 'TSTypeAnnotation') {
+        if (current.key === 'returnType') return 'return';
+        // `x: T = value` and `private x: T` wrap the parameter
+        let parameter = current.parentPath;
+        while (parameter?.parentPath && ['AssignmentPattern', 'TSParameterProperty'].includes(parameter.parentPath.node.type)) {
+          parameter = parameter.parentPath;
+        }
+        return parameter?.listKey === 'params' ? 'parameter' : 'annotation';
+      }
+    }
+    return 'annotation';
+  }
+  
+  // Last name of `Base`, `ns.Base` or `React.Component` in a heritage clause or type reference
+  private referenceName(node: BabelNode | undefined): string | null {
+    if (!node) return null;
+    if (node.type === 'Identifier') return node.name || null;
+    if (node.type === 'MemberExpression') return this.referenceName(node.property);
+    if (node.type === 'TSQualifiedName') return this.referenceName(node.right as BabelNode);
+    return null;
+  }
+  
   private extractTypeAlias(node: BabelTSNode, symbols: Symbol[]): void {
     const name = node.id.name;
     const lineStart = node.loc?.start.line || 1;
@@ -451,6 +508,9 @@ export class TypeScriptExtractor extends BaseExtractor {
       dependencies: [],
       comments: this.extractComments(),
       calls: [],
+      typeRelations: findTypeRelations(this.content, this.language),
+      typeReferences: findTypeReferences(this.content, this.language),
+      occurrences: [],
       structure: {}
     };
     
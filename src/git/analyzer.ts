@@ -36,6 +36,18 @@ export class GitAnalyzer {
     }
   }
 
+  public getHeadCommit(): string | null {
+    if (!this.isGitRepo) {
+      return null;
+    }
+
+    try {
+      return this.execGit('rev-parse HEAD').trim() || null;
+    } catch {
+      return null;
+    }
+  }
+
   public async getGitHistory(filePath: string, symbolName?: string, lineStart?: number, lineEnd?: number): Promise<GitHistory | null> {
     if (!this.isGitRepo) {
       return null;
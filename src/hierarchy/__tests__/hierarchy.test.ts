@@ -0,0 +1,70 @@
+import { PrimordynDB } from '../../database/index.js';
+import { TypeHierarchyBuilder } from '../index.js';
+import { createTestDb, removeTestDir, addFile, addSymbol, addRelation } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('TypeHierarchyBuilder', () => {
+  const testDir = join(process.cwd(), '.test-hierarchy');
+  let db: PrimordynDB;
+
+  const file = (relativePath: string, language: string) => addFile(db, relativePath, { language });
+  const type = (fileId: number, name: string, kind: string, line: number) =>
+    addSymbol(db, fileId, name, { type: kind, lines: [line, line + 5] });
+  const relation = (fileId: number, typeName: string, relatedName: string, kind: string, line: number) =>
+    addRelation(db, { fileId, typeName, relatedName, kind, line });
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+
+    // Repository <- CachedRepository (interface); SqlRepository implements CachedRepository;
+    // PostgresRepository extends SqlRepository
+    const api = file('src/Repository.java', 'java');
+    const sql = file('src/SqlRepository.java', 'java');
+    type(api, 'Repository', 'interface', 1);
+    type(api, 'CachedRepository', 'interface', 10);
+    relation(api, 'CachedRepository', 'Repository', 'extends', 10);
+    type(sql, 'BaseStore', 'class', 1);
+    type(sql, 'SqlRepository', 'class', 10);
+    type(sql, 'PostgresRepository', 'class', 30);
+    relation(sql, 'SqlRepository', 'BaseStore', 'extends', 10);
+    relation(sql, 'SqlRepository', 'CachedRepository', 'implements', 10);
+    relation(sql, 'PostgresRepository', 'SqlRepository', 'extends', 30);
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('follows supertypes upwards', () => {
+    const hierarchy = new TypeHierarchyBuilder(db).build('PostgresRepository')!;
+
+    expect(hierarchy.definitions).toEqual([{ filePath: 'src/SqlRepository.java', line: 30, type: 'class' }]);
+    expect(hierarchy.supertypes).toHaveLength(1);
+    const parent = hierarchy.supertypes[0];
+    expect(parent.name).toBe('SqlRepository');
+    expect(parent.filePath).toBe('src/SqlRepository.java');
+    expect(parent.children.map(node => `${node.kind} ${node.name}`)).toEqual([
+      'extends BaseStore',
+      'implements CachedRepository'
+    ]);
+    expect(parent.children[1].children[0].name).toBe('Repository');
+  });
+
+  test('lists subtypes and transitive implementors', () => {
+    const hierarchy = new TypeHierarchyBuilder(db).build('Repository')!;
+
+    expect(hierarchy.subtypes.map(node => node.name)).toEqual(['CachedRepository']);
+    expect(hierarchy.implementors.map(implementor => [implementor.name, implementor.via])).toEqual([
+      ['SqlRepository', ['CachedRepository']],
+      ['PostgresRepository', ['CachedRepository', 'SqlRepository']]
+    ]);
+  });
+
+  test('respects the depth limit and unknown types', () => {
+    const builder = new TypeHierarchyBuilder(db);
+
+    expect(builder.build('PostgresRepository', { maxDepth: 1 })!.supertypes[0].children).toEqual([]);
+    expect(builder.build('Missing')).toBeNull();
+  });
+});
\ No newline at end of file
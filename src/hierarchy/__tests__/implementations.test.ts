@@ -0,0 +1,223 @@
+import { PrimordynDB } from '../../database/index.js';
+import { ImplementationIndex } from '../implementations.js';
+import { createTestDb, removeTestDir, addFile, addSymbol, addCall, addRelation } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('ImplementationIndex', () => {
+  const testDir = join(process.cwd(), '.test-implementations');
+  let db: PrimordynDB;
+
+  const file = (relativePath: string, language: string, lines: string[]) => addFile(db, relativePath, { language, content: lines.join('\n') });
+  const symbol = (fileId: number, name: string, type: string, lineStart: number, lineEnd: number, signature?: string) =>
+    addSymbol(db, fileId, name, { type, lines: [lineStart, lineEnd], signature });
+  const call = (fileId: number, callerId: number, calleeName: string, calleeId: number | null, line: number) =>
+    addCall(db, { callerSymbolId: callerId, callerFileId: fileId, calleeName, calleeSymbolId: calleeId, callType: 'method', line });
+
+  const interfaceCalls = () => db.getDatabase().prepare(`
+    SELECT caller.name as caller, s.signature FROM call_graph cg
+    JOIN symbols s ON s.id = cg.callee_symbol_id
+    JOIN symbols caller ON caller.id = cg.caller_symbol_id
+    WHERE cg.call_type = 'interface' ORDER BY caller.name, s.signature
+  `).all().map(row => `${(row as { caller: string }).caller} -> ${(row as { signature: string }).signature}`);
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  describe('Go', () => {
+    beforeEach(() => {
+      const store = file('store/store.go', 'go', [
+        'package store',
+        '',
+        'type Store interface {',
+        '\tGet(key string) ([]byte, error)',
+        '\tPut(key string, value []byte) error',
+        '}',
+        '',
+        'type Getter interface {',
+        '\tGet(key string) ([]byte, error)',
+        '}'
+      ]);
+      symbol(store, 'Store', 'class', 3, 6);
+      symbol(store, 'Getter', 'class', 8, 10);
+
+      const pg = file('store/pg.go', 'go', [
+        'package store',
+        '',
+        'type PgStore struct {',
+        '\tdb *sql.DB',
+        '}',
+        '',
+        'func (s *PgStore) Get(key string) ([]byte, error) {',
+        '\treturn nil, nil',
+        '}',
+        '',
+        'func (s *PgStore) Put(key string, value []byte) error {',
+        '\treturn nil',
+        '}',
+        '',
+        'type CachedStore struct {',
+        '\t*PgStore',
+        '}',
+        '',
+        'type Fixed []byte',
+        '',
+        'func (f Fixed) Get(key string) ([]byte, error) {',
+        '\treturn f, nil',
+        '}'
+      ]);
+      symbol(pg, 'PgStore', 'class', 3, 5);
+      symbol(pg, 'Get', 'function', 7, 9, 'func (s *PgStore) Get(key string) ([]byte, error) {');
+      symbol(pg, 'Put', 'function', 11, 13, 'func (s *PgStore) Put(key string, value []byte) error {');
+      symbol(pg, 'CachedStore', 'class', 15, 17);
+      symbol(pg, 'Fixed', 'class', 19, 19);
+      symbol(pg, 'Get', 'function', 21, 23, 'func (f Fixed) Get(key string) ([]byte, error) {');
+
+      const service = file('service/service.go', 'go', [
+        'package service',
+        '',
+        'func Load(s store.Store) {',
+        '\ts.Get("key")',
+        '\tfmt.Println("done")',
+        '}',
+        '',
+        'func Read(g store.Getter) {',
+        '\tg.Get("key")',
+        '}',
+        '',
+        'func Direct(f store.Fixed) {',
+        '\tf.Get("key")',
+        '}',
+        '',
+        'type Handler struct {',
+        '\tcache store.Getter',
+        '}',
+        '',
+        'func (h *Handler) Serve() {',
+        '\th.cache.Get("key")',
+        '}'
+      ]);
+      const load = symbol(service, 'Load', 'function', 3, 6, 'func Load(s store.Store) {');
+      call(service, load, 'Get', null, 4);
+      call(service, load, 'Println', null, 5);
+      call(service, symbol(service, 'Read', 'function', 8, 10, 'func Read(g store.Getter) {'), 'Get', null, 9);
+      call(service, symbol(service, 'Direct', 'function', 12, 14, 'func Direct(f store.Fixed) {'), 'Get', null, 13);
+      symbol(service, 'Handler', 'class', 16, 18);
+      call(service, symbol(service, 'Serve', 'function', 20, 22, 'func (h *Handler) Serve() {'), 'Get', null, 21);
+
+      // Embedding is recorded by the extractors
+      addRelation(db, { fileId: pg, typeName: 'CachedStore', relatedName: 'PgStore', kind: 'embeds', line: 16 });
+    });
+
+    test('finds types whose method sets satisfy an interface', () => {
+      const index = new ImplementationIndex(db);
+      index.refresh();
+
+      const store = index.find('store.Store')!;
+      expect(store.methods).toEqual(['Get', 'Put']);
+      expect(store.implementations.map(implementation => [implementation.name, implementation.structural])).toEqual([
+        ['CachedStore', true],
+        ['PgStore', true]
+      ]);
+      // Promoted from the embedded PgStore
+      expect(store.implementations[0].methods).toEqual([
+        { name: 'Get', filePath: 'store/pg.go', line: 7 },
+        { name: 'Put', filePath: 'store/pg.go', line: 11 }
+      ]);
+
+      expect(index.find('Getter')!.implementations.map(implementation => implementation.name)).toEqual(['CachedStore', 'Fixed', 'PgStore']);
+    });
+
+    test('fans calls on interface-typed receivers out to the implementations', () => {
+      const index = new ImplementationIndex(db);
+      index.refresh();
+      // Refreshing again replaces the derived rows rather than duplicating them
+      index.refresh();
+
+      // s is a Store, which Fixed doesn't implement; f is a concrete Fixed, so Direct gets no edges
+      expect(interfaceCalls()).toEqual([
+        'Load -> func (s *PgStore) Get(key string) ([]byte, error) {',
+        'Read -> func (f Fixed) Get(key string) ([]byte, error) {',
+        'Read -> func (s *PgStore) Get(key string) ([]byte, error) {',
+        'Serve -> func (f Fixed) Get(key string) ([]byte, error) {',
+        'Serve -> func (s *PgStore) Get(key string) ([]byte, error) {'
+      ]);
+    });
+
+    test('keeps structural edges when no Go file changed', () => {
+      const index = new ImplementationIndex(db);
+      index.refresh();
+      const mem = file('store/mem.go', 'go', [
+        'package store',
+        '',
+        'type MemStore struct{}',
+        '',
+        'func (m MemStore) Get(key string) ([]byte, error) { return nil, nil }',
+        'func (m MemStore) Put(key string, value []byte) error { return nil }'
+      ]);
+      symbol(mem, 'MemStore', 'class', 3, 3);
+      const implementors = () => index.find('Store')!.implementations.map(implementation => implementation.name);
+
+      index.refresh({ languages: ['java'] });
+      expect(implementors()).toEqual(['CachedStore', 'PgStore']);
+      index.refresh({ languages: ['go'] });
+      expect(implementors()).toEqual(['CachedStore', 'MemStore', 'PgStore']);
+    });
+  });
+
+  test('uses declared implements edges and interface method symbols', () => {
+    const api = file('src/Repository.java', 'java', [
+      'public interface Repository {',
+      '    void save(User user);',
+      '    Optional<User> find(long id);',
+      '}'
+    ]);
+    symbol(api, 'Repository', 'interface', 1, 4);
+    const save = symbol(api, 'save', 'method', 2, 2, 'void save(User user);');
+    symbol(api, 'find', 'method', 3, 3, 'Optional<User> find(long id);');
+
+    const sql = file('src/SqlRepository.java', 'java', [
+      'public class SqlRepository implements Repository {',
+      '    public void save(User user) {}',
+      '    public Optional<User> find(long id) { return Optional.empty(); }',
+      '}'
+    ]);
+    symbol(sql, 'SqlRepository', 'class', 1, 4);
+    symbol(sql, 'save', 'method', 2, 2, 'public void save(User user) {}');
+    symbol(sql, 'find', 'method', 3, 3, 'public Optional<User> find(long id) { return Optional.empty(); }');
+    addRelation(db, { fileId: sql, typeName: 'SqlRepository', relatedName: 'Repository', kind: 'implements', line: 1 });
+    call(sql, save, 'repo.save', save, 2);
+
+    const service = file('src/Service.java', 'java', [
+      'public class Service {',
+      '    private Repository repo;',
+      '    private SqlRepository concrete;',
+      '    public void run(User user) {',
+      '        this.repo.save(user);',
+      '        concrete.save(user);',
+      '    }',
+      '}'
+    ]);
+    symbol(service, 'Service', 'class', 1, 8);
+    const run = symbol(service, 'run', 'method', 4, 7, 'public void run(User user) {');
+    call(service, run, 'repo.save', null, 5);
+    call(service, run, 'concrete.save', null, 6);
+
+    const index = new ImplementationIndex(db);
+    index.refresh();
+
+    const report = index.find('Repository')!;
+    expect(report.methods).toEqual(['find', 'save']);
+    expect(report.implementations).toHaveLength(1);
+    expect(report.implementations[0].structural).toBeUndefined();
+    expect(report.implementations[0].methods.map(method => `${method.name}:${method.line}`)).toEqual(['find:3', 'save:2']);
+    // Only the call through the Repository-typed field fans out
+    expect(interfaceCalls()).toEqual(['run -> public void save(User user) {}', 'save -> public void save(User user) {}']);
+    expect(index.find('Missing')).toBeNull();
+  });
+});
\ No newline at end of file
@@ -0,0 +1,483 @@
+import { PrimordynDB } from '../database/index.js';
+import { baseTypeName, findTypeRelations } from '../extractors/type-relations.js';
+import { TypeHierarchyBuilder, TYPE_KINDS } from './index.js';
+import type { Implementation, ImplementationsReport, MethodLocation } from '../types/index.js';
+
+export interface ImplementationsOptions {
+  // Levels of sub-interfaces and subclasses to follow
+  maxDepth?: number;
+}
+
+export interface ImplementationsRefreshOptions {
+  // Languages of the files that changed since the last refresh; all of them when unset
+  languages?: string[];
+}
+
+interface TypeRange {
+  fileId: number;
+  filePath: string;
+  lineStart: number;
+  lineEnd: number;
+  language: string | null;
+}
+
+interface MethodTarget extends MethodLocation {
+  symbolId: number;
+  fileId: number;
+}
+
+interface CallRow {
+  callerSymbolId: number | null;
+  callerFileId: number;
+  // First line of the calling symbol; 1 for top-level calls
+  callerLine: number;
+  calleeName: string;
+  calleeSymbolId: number | null;
+  line: number;
+  column: number | null;
+}
+
+interface GoDeclarations {
+  // Interface name -> method names it declares itself
+  interfaces: Map<string, Set<string>>;
+  // Non-interface type name -> where it's declared
+  types: Map<string, { fileId: number; line: number }>;
+  // Receiver type name -> method names
+  methods: Map<string, Set<string>>;
+  // Type name -> embedded type names (struct fields and interface embedding)
+  embeds: Map<string, Set<string>>;
+}
+
+const METHOD_KINDS = ['method', 'function'];
+const NOT_METHODS = new Set(['if', 'for', 'while', 'switch', 'catch', 'return', 'new', 'function', 'fn', 'func', 'def', 'fun']);
+// Languages that put the type before the variable name
+const PREFIX_DECLARATIONS = new Set(['java', 'csharp', 'cpp', 'c']);
+const NOT_TYPES = new Set(['return', 'new', 'throw', 'else', 'case', 'in', 'out', 'ref', 'delete']);
+const SELF_NAMES = new Set(['this', 'self']);
+
+/**
+ * Which concrete types implement an interface, and where each of its methods
+ * lives on them. Declared `implements` edges come from the extractors; Go has
+ * none, so `refresh` adds structural edges by comparing method sets (by name,
+ * not signature). Calls on a receiver declared as an interface are fanned out
+ * to the implementations in the call graph.
+ */
+export class ImplementationIndex {
+  private db: PrimordynDB;
+  private hierarchy: TypeHierarchyBuilder;
+  // File contents read during a refresh, by file id
+  private lines = new Map<number, string[]>();
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+    this.hierarchy = new TypeHierarchyBuilder(db);
+  }
+
+  /**
+   * Recomputes structural edges and interface call edges; run after indexing.
+   * Structural edges come from every Go file, so they're kept as they are
+   * when no Go file changed.
+   */
+  public refresh(options: ImplementationsRefreshOptions = {}): void {
+    const database = this.db.getDatabase();
+    try {
+      if (!options.languages || options.languages.includes('go')) {
+        database.prepare("DELETE FROM type_relations WHERE source = 'structural'").run();
+        this.addGoStructuralEdges();
+      }
+      database.prepare("DELETE FROM call_graph WHERE call_type = 'interface'").run();
+      this.fanOutInterfaceCalls();
+    } finally {
+      this.lines.clear();
+    }
+  }
+
+  /** Null when the index knows nothing about the interface. */
+  public find(interfaceName: string, options: ImplementationsOptions = {}): ImplementationsReport | null {
+    const name = baseTypeName(interfaceName);
+    const definitions = this.hierarchy.definitions(name);
+    const implementors = this.hierarchy.implementors(name, options.maxDepth ?? 10);
+    if (definitions.length === 0 && implementors.length === 0) {
+      return null;
+    }
+
+    const methods = [...this.interfaceMethods(name)].sort();
+    const implementations: Implementation[] = implementors.map(implementor => ({
+      ...implementor,
+      methods: methods.flatMap(method => this.methodTargets(implementor.name, method).slice(0, 1))
+        .map(({ name: methodName, filePath, line }) => ({ name: methodName, filePath, line }))
+    }));
+    return { interface: name, definitions, methods, implementations };
+  }
+
+  private addGoStructuralEdges(): void {
+    const database = this.db.getDatabase();
+    const declarations = this.goDeclarations();
+
+    const interfaceSets = new Map<string, Set<string>>();
+    for (const name of declarations.interfaces.keys()) {
+      interfaceSets.set(name, collectMethods(name, declarations, new Set()));
+    }
+
+    const insert = database.prepare(`
+      INSERT INTO type_relations (file_id, symbol_id, type_name, related_name, kind, line_number, source)
+      VALUES (?, ?, ?, ?, 'implements', ?, 'structural')
+    `);
+    const findType = database.prepare(
+      'SELECT id FROM symbols WHERE file_id = ? AND name = ? ORDER BY ABS(line_start - ?) LIMIT 1'
+    );
+    for (const [typeName, declared] of declarations.types) {
+      const methodSet = collectMethods(typeName, declarations, new Set());
+      if (methodSet.size === 0) {
+        continue;
+      }
+      for (const [interfaceName, required] of interfaceSets) {
+        // The empty interface is satisfied by everything and says nothing
+        if (required.size > 0 && [...required].every(method => methodSet.has(method))) {
+          const symbol = findType.get(declared.fileId, typeName, declared.line) as { id: number } | undefined;
+          insert.run(declared.fileId, symbol?.id ?? null, typeName, interfaceName, declared.line);
+        }
+      }
+    }
+  }
+
+  // Interfaces, types, receivers and embedding across every indexed Go file
+  private goDeclarations(): GoDeclarations {
+    const declarations: GoDeclarations = { interfaces: new Map(), types: new Map(), methods: new Map(), embeds: new Map() };
+    const files = this.db.getDatabase().prepare(`
+      SELECT id, content FROM files_with_content WHERE language = 'go'
+    `).all() as { id: number; content: string }[];
+
+    for (const file of files) {
+      const lines = file.content.split('\n');
+      lines.forEach((line, index) => {
+        const type = line.match(/^\s*type\s+(\w+)(?:\[[^\]]*\])?\s+(\S+)/);
+        if (type && !type[2].startsWith('=')) {
+          if (type[2].startsWith('interface')) {
+            const methods = lookup(declarations.interfaces, type[1]);
+            memberNames(lines, index, 'go').forEach(method => methods.add(method));
+          } else if (!declarations.types.has(type[1])) {
+            declarations.types.set(type[1], { fileId: file.id, line: index + 1 });
+          }
+        }
+
+        const receiver = line.match(/^func\s*\(\s*(?:\w+\s+)?\*?(\w+)(?:\[[^\]]*\])?\s*\)\s*(\w+)/);
+        if (receiver) {
+          lookup(declarations.methods, receiver[1]).add(receiver[2]);
+        }
+      });
+
+      for (const relation of findTypeRelations(file.content, 'go')) {
+        lookup(declarations.embeds, relation.typeName).add(relation.relatedName);
+      }
+    }
+    return declarations;
+  }
+
+  private fanOutInterfaceCalls(): void {
+    const database = this.db.getDatabase();
+    const interfaces = database.prepare(`
+      SELECT DISTINCT related_name as name FROM type_relations WHERE kind = 'implements'
+    `).all() as { name: string }[];
+    if (interfaces.length === 0) {
+      return;
+    }
+
+    // Calls made on a receiver, grouped by the method name they end in
+    const calls = database.prepare(`
+      SELECT cg.caller_symbol_id as callerSymbolId, cg.caller_file_id as callerFileId, COALESCE(s.line_start, 1) as callerLine,
+        cg.callee_name as calleeName, cg.callee_symbol_id as calleeSymbolId, cg.line_number as line, cg.column_number as column
+      FROM call_graph cg
+      LEFT JOIN symbols s ON s.id = cg.caller_symbol_id
+      WHERE cg.call_type = 'method' OR (cg.call_type = 'function' AND cg.callee_name LIKE '%.%')
+    `).all() as CallRow[];
+    const callsByMethod = new Map<string, CallRow[]>();
+    for (const call of calls) {
+      const method = call.calleeName.split('.').pop()!;
+      const group = callsByMethod.get(method);
+      if (group) {
+        group.push(call);
+      } else {
+        callsByMethod.set(method, [call]);
+      }
+    }
+
+    const insert = database.prepare(`
+      INSERT INTO call_graph (caller_symbol_id, caller_file_id, callee_name, callee_symbol_id, callee_file_id, call_type, line_number, column_number)
+      VALUES (?, ?, ?, ?, ?, 'interface', ?, ?)
+    `);
+    const added = new Set<string>();
+    const receiverTypes = new Map<CallRow, string | null>();
+    const receiverType = (call: CallRow) => {
+      if (!receiverTypes.has(call)) {
+        receiverTypes.set(call, this.receiverType(call));
+      }
+      return receiverTypes.get(call)!;
+    };
+    for (const { name } of interfaces) {
+      const implementors = this.hierarchy.implementors(name);
+      for (const method of this.interfaceMethods(name)) {
+        const targets = implementors.flatMap(implementor => this.methodTargets(implementor.name, method).slice(0, 1));
+        const candidates = callsByMethod.get(method);
+        if (targets.length === 0 || !candidates) {
+          continue;
+        }
+        const declaredOnInterface = new Set(this.methodTargets(name, method).map(target => target.symbolId));
+
+        for (const call of candidates) {
+          // Only calls the extractor resolved to the interface's own method, or
+          // made on something declared with the interface type
+          const onInterface = call.calleeSymbolId !== null && declaredOnInterface.has(call.calleeSymbolId);
+          if (!onInterface && receiverType(call) !== name) {
+            continue;
+          }
+          for (const target of targets) {
+            const key = `${call.callerFileId}:${call.line}:${call.column}:${target.symbolId}`;
+            if (target.symbolId !== call.calleeSymbolId && !added.has(key)) {
+              added.add(key);
+              insert.run(call.callerSymbolId, call.callerFileId, call.calleeName, target.symbolId, target.fileId, call.line, call.column);
+            }
+          }
+        }
+      }
+    }
+  }
+
+  /**
+   * The declared type of what a method is called on: a parameter or local of
+   * the calling function, or a field of a type for `this.x`, `self.x` and
+   * chains like Go's `h.store.Get` where `h` is the receiver. Null when
+   * nothing in the index declares it.
+   */
+  private receiverType(call: CallRow): string | null {
+    const language = (this.db.getDatabase().prepare('SELECT language FROM files WHERE id = ?')
+      .get(call.callerFileId) as { language: string | null } | undefined)?.language ?? null;
+    const lines = this.fileLines(call.callerFileId);
+    const method = escapeIdentifier(call.calleeName.split('.').pop()!);
+    const expression = (lines[call.line - 1] ?? '')
+      .match(new RegExp(`((?:[A-Za-z_$][\\w$]*\\s*\\.\\s*)*[A-Za-z_$][\\w$]*)\\s*\\.\\s*${method}\\s*[(<]`))?.[1];
+    if (!expression) {
+      return null;
+    }
+
+    const [variable, ...fields] = expression.split(/\s*\.\s*/);
+    const enclosing = this.db.getDatabase().prepare(`
+      SELECT name FROM symbols
+      WHERE file_id = ? AND line_start <= ? AND line_end >= ? AND type IN (${TYPE_KINDS.map(() => '?').join(',')})
+      ORDER BY line_end - line_start LIMIT 1
+    `).get(call.callerFileId, call.line, call.line, ...TYPE_KINDS) as { name: string } | undefined;
+
+    let type: string | null;
+    if (SELF_NAMES.has(variable)) {
+      type = enclosing?.name ?? null;
+    } else {
+      // The closest declaration above the call wins; unqualified fields come last
+      type = declaredType(lines.slice(call.callerLine - 1, call.line).reverse(), variable, language) ??
+        (enclosing ? this.fieldType(enclosing.name, variable) : null);
+    }
+    for (const field of fields) {
+      type = type && this.fieldType(type, field);
+    }
+    return type;
+  }
+
+  private fieldType(typeName: string, field: string): string | null {
+    for (const range of this.typeRanges(typeName)) {
+      const type = declaredType(this.fileLines(range.fileId).slice(range.lineStart - 1, range.lineEnd), field, range.language);
+      if (type) {
+        return type;
+      }
+    }
+    return null;
+  }
+
+  // Method names an interface requires, including those of the interfaces it extends
+  private interfaceMethods(name: string, seen = new Set<string>()): Set<string> {
+    const methods = new Set<string>();
+    if (seen.has(name)) {
+      return methods;
+    }
+    seen.add(name);
+
+    for (const range of this.typeRanges(name)) {
+      const lines = this.fileLines(range.fileId);
+      memberNames(lines, range.lineStart - 1, range.language).forEach(method => methods.add(method));
+    }
+    const parents = this.db.getDatabase().prepare(`
+      SELECT DISTINCT related_name as name FROM type_relations WHERE type_name = ? AND kind = 'extends'
+    `).all(name) as { name: string }[];
+    for (const parent of parents) {
+      this.interfaceMethods(parent.name, seen).forEach(method => methods.add(method));
+    }
+    return methods;
+  }
+
+  /**
+   * Where `typeName` implements `method`: Go receivers (or promoted from an
+   * embedded type), otherwise a method inside the type's declaration or one
+   * of its impl blocks, falling back to what it inherits.
+   */
+  private methodTargets(typeName: string, method: string, seen = new Set<string>()): MethodTarget[] {
+    if (seen.has(typeName)) {
+      return [];
+    }
+    seen.add(typeName);
+    const database = this.db.getDatabase();
+    // The TypeScript extractor names class methods `Class.method`
+    const candidates = database.prepare(`
+      SELECT s.id as symbolId, s.file_id as fileId, s.name, s.line_start as line, s.signature, f.relative_path as filePath, f.language
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      WHERE s.name IN (?, ?) AND s.type IN (${METHOD_KINDS.map(() => '?').join(',')})
+      ORDER BY f.relative_path, s.line_start
+    `).all(method, `${typeName}.${method}`, ...METHOD_KINDS) as (MethodTarget & { signature: string | null; language: string | null })[];
+
+    const receiver = new RegExp(`^func\\s*\\(\\s*(?:\\w+\\s+)?\\*?${typeName}\\b`);
+    const ranges = this.typeRanges(typeName);
+    const found = new Map<string, MethodTarget>();
+    for (const candidate of candidates) {
+      const matches = candidate.language === 'go'
+        ? receiver.test(candidate.signature || '')
+        : ranges.some(range => range.fileId === candidate.fileId && candidate.line > range.lineStart && candidate.line <= range.lineEnd);
+      // Extractors can report a method both as a function and a method
+      const key = `${candidate.fileId}:${candidate.line}`;
+      if (matches && !found.has(key)) {
+        found.set(key, { symbolId: candidate.symbolId, fileId: candidate.fileId, name: method, filePath: candidate.filePath, line: candidate.line });
+      }
+    }
+    if (found.size > 0) {
+      return [...found.values()];
+    }
+
+    const parents = database.prepare(`
+      SELECT DISTINCT related_name as name FROM type_relations WHERE type_name = ? AND kind IN ('extends', 'embeds')
+    `).all(typeName) as { name: string }[];
+    return parents.flatMap(parent => this.methodTargets(parent.name, method, seen));
+  }
+
+  // Declarations of a type, plus the blocks its supertype edges were declared in (Rust `impl Trait for Type`)
+  private typeRanges(name: string): TypeRange[] {
+    const database = this.db.getDatabase();
+    const declared = database.prepare(`
+      SELECT s.file_id as fileId, f.relative_path as filePath, s.line_start as lineStart, s.line_end as lineEnd, f.language
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      WHERE s.name = ? AND s.type IN (${TYPE_KINDS.map(() => '?').join(',')})
+    `).all(name, ...TYPE_KINDS) as TypeRange[];
+    const blocks = database.prepare(`
+      SELECT s.file_id as fileId, f.relative_path as filePath, s.line_start as lineStart, s.line_end as lineEnd, f.language
+      FROM type_relations r
+      JOIN files f ON r.file_id = f.id
+      JOIN symbols s ON s.id = (
+        SELECT id FROM symbols
+        WHERE file_id = r.file_id AND line_start <= r.line_number AND line_end >= r.line_number
+          AND type IN (${TYPE_KINDS.map(() => '?').join(',')})
+        ORDER BY line_end - line_start LIMIT 1
+      )
+      WHERE r.type_name = ? AND r.source = 'declared'
+    `).all(...TYPE_KINDS, name) as TypeRange[];
+
+    const unique = new Map<string, TypeRange>();
+    for (const range of [...declared, ...blocks]) {
+      unique.set(`${range.fileId}:${range.lineStart}`, range);
+    }
+    return [...unique.values()];
+  }
+
+  private fileLines(fileId: number): string[] {
+    let lines = this.lines.get(fileId);
+    if (!lines) {
+      const file = this.db.getDatabase().prepare(
+        'SELECT content FROM files_with_content WHERE id = ?'
+      ).get(fileId) as { content: string } | undefined;
+      lines = file ? file.content.split('\n') : [];
+      this.lines.set(fileId, lines);
+    }
+    return lines;
+  }
+}
+
+// A type's own methods plus everything promoted from the types it embeds
+function collectMethods(name: string, declarations: GoDeclarations, seen: Set<string>): Set<string> {
+  const methods = new Set<string>();
+  if (seen.has(name)) {
+    return methods;
+  }
+  seen.add(name);
+  declarations.interfaces.get(name)?.forEach(method => methods.add(method));
+  declarations.methods.get(name)?.forEach(method => methods.add(method));
+  for (const embedded of declarations.embeds.get(name) ?? []) {
+    collectMethods(embedded, declarations, seen).forEach(method => methods.add(method));
+  }
+  return methods;
+}
+
+/**
+ * Method names declared directly in the body that opens at or after
+ * `startIndex`: one per line at the body's top level, so nested blocks
+ * and default method bodies don't contribute.
+ */
+function memberNames(lines: string[], startIndex: number, language: string | null): string[] {
+  const names: string[] = [];
+  let depth = 0;
+  let opened = false;
+  for (let index = startIndex; index < lines.length; index++) {
+    const line = lines[index].replace(/\/\/.*$/, '').replace(/\/\*.*?\*\//g, '');
+    if (opened && depth === 1 && !/^\s*[*@#]/.test(line)) {
+      const match = language === 'go'
+        ? line.match(/^\s*(\w+)\s*\(/)
+        : line.match(/\b(\w+)\s*\??\s*(?:<[^>()]*>)?\s*\(/) || line.match(/^\s*(?:readonly\s+)?(\w+)\??\s*:\s*\(/);
+      if (match && !NOT_METHODS.has(match[1])) {
+        names.push(match[1]);
+      }
+    }
+    for (const char of line) {
+      if (char === '{') {
+        depth++;
+        opened = true;
+      } else if (char === '}') {
+        depth--;
+      }
+    }
+    if (opened && depth <= 0) {
+      break;
+    }
+  }
+  return names;
+}
+
+/**
+ * The type `name` is declared with on the first of `lines` that declares it:
+ * `name Type` in Go, `Type name` in C-style languages, `name: Type` elsewhere.
+ */
+function declaredType(lines: string[], name: string, language: string | null): string | null {
+  const variable = escapeIdentifier(name);
+  let pattern: RegExp;
+  if (language === 'go') {
+    pattern = new RegExp(`(?:^|[\\s(,])${variable}\\s+\\*?([A-Za-z_][\\w.]*)`);
+  } else if (language && PREFIX_DECLARATIONS.has(language)) {
+    pattern = new RegExp(`([A-Za-z_][\\w.:]*)(?:\\s*<[^<>]*>)?[\\s*&]+${variable}\\s*[,;=)]`);
+  } else {
+    pattern = new RegExp(`(?:^|[^\\w$.])${variable}\\s*[?!]?\\s*:\\s*(?:&\\s*(?:mut\\s+)?|(?:dyn|impl)\\s+)?([A-Za-z_$][\\w$.:]*)`);
+  }
+  for (const line of lines) {
+    const type = line.replace(/\/\/.*$/, '').match(pattern)?.[1];
+    if (type && !NOT_TYPES.has(type)) {
+      return baseTypeName(type);
+    }
+  }
+  return null;
+}
+
+function escapeIdentifier(name: string): string {
+  return name.replace(/\$/g, '\\$');
+}
+
+function lookup(map: Map<string, Set<string>>, key: string): Set<string> {
+  let found = map.get(key);
+  if (!found) {
+    found = new Set();
+    map.set(key, found);
+  }
+  return found;
+}
\ No newline at end of file
@@ -0,0 +1,146 @@
+import { PrimordynDB } from '../database/index.js';
+import { baseTypeName } from '../extractors/type-relations.js';
+import type { HierarchyNode, Implementor, TypeDefinition, TypeHierarchy, TypeRelationKind } from '../types/index.js';
+
+export interface TypeHierarchyOptions {
+  // Levels to follow up and down from the queried type
+  maxDepth?: number;
+}
+
+interface RelationRow {
+  typeName: string;
+  relatedName: string;
+  kind: TypeRelationKind;
+  source: 'declared' | 'structural';
+  filePath: string;
+  line: number;
+}
+
+// Symbol kinds a type name can resolve to
+export const TYPE_KINDS = ['class', 'interface', 'struct', 'trait', 'enum', 'type', 'protocol', 'module'];
+
+/**
+ * Supertypes, subtypes and implementors of a type, followed transitively
+ * through the `type_relations` edges recorded at index time.
+ */
+export class TypeHierarchyBuilder {
+  private db: PrimordynDB;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+  }
+
+  /** Null when the index knows nothing about the type. */
+  public build(typeName: string, options: TypeHierarchyOptions = {}): TypeHierarchy | null {
+    const name = baseTypeName(typeName);
+    const maxDepth = options.maxDepth ?? 10;
+    const definitions = this.definitions(name);
+    const supertypes = this.supertypes(name, maxDepth, new Set([name]));
+    const subtypes = this.subtypes(name, maxDepth, new Set([name]));
+    const implementors = this.implementors(name, maxDepth);
+
+    if (definitions.length === 0 && supertypes.length === 0 && subtypes.length === 0 && implementors.length === 0) {
+      return null;
+    }
+    return { type: name, definitions, supertypes, subtypes, implementors };
+  }
+
+  public definitions(name: string): TypeDefinition[] {
+    return this.db.getDatabase().prepare(`
+      SELECT f.relative_path as filePath, s.line_start as line, s.type
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      WHERE s.name = ? AND s.type IN (${TYPE_KINDS.map(() => '?').join(',')})
+      ORDER BY f.relative_path, s.line_start
+    `).all(name, ...TYPE_KINDS) as TypeDefinition[];
+  }
+
+  private supertypes(name: string, depth: number, seen: Set<string>): HierarchyNode[] {
+    if (depth === 0) {
+      return [];
+    }
+    return this.edges('r.type_name = ?', name).map(edge => {
+      const definition = this.definitions(edge.relatedName)[0];
+      const node: HierarchyNode = { name: edge.relatedName, kind: edge.kind, filePath: definition?.filePath, line: definition?.line, children: [] };
+      if (seen.has(edge.relatedName)) {
+        node.repeated = true;
+      } else {
+        seen.add(edge.relatedName);
+        node.children = this.supertypes(edge.relatedName, depth - 1, seen);
+      }
+      return node;
+    });
+  }
+
+  // Types that extend or embed `name`; implementations are listed separately
+  private subtypes(name: string, depth: number, seen: Set<string>): HierarchyNode[] {
+    if (depth === 0) {
+      return [];
+    }
+    return this.edges("r.related_name = ? AND r.kind != 'implements'", name).map(edge => {
+      const node: HierarchyNode = { name: edge.typeName, kind: edge.kind, filePath: edge.filePath, line: edge.line, children: [] };
+      if (seen.has(edge.typeName)) {
+        node.repeated = true;
+      } else {
+        seen.add(edge.typeName);
+        node.children = this.subtypes(edge.typeName, depth - 1, seen);
+      }
+      return node;
+    });
+  }
+
+  /**
+   * Types implementing `name` directly, through an interface that extends it,
+   * or by extending a class that implements it. Go types count when their
+   * method set satisfies the interface.
+   */
+  public implementors(name: string, maxDepth = 10): Implementor[] {
+    const found = new Map<string, Implementor>();
+    const add = (edge: RelationRow, via: string[]) => {
+      const key = `${edge.typeName}@${edge.filePath}`;
+      if (!found.has(key)) {
+        const implementor: Implementor = { name: edge.typeName, filePath: edge.filePath, line: edge.line, via };
+        if (edge.source === 'structural') {
+          implementor.structural = true;
+        }
+        found.set(key, implementor);
+        return true;
+      }
+      return false;
+    };
+
+    // Breadth-first over sub-interfaces, then over each implementor's subclasses
+    const queue: { name: string; via: string[]; implementing: boolean }[] = [{ name, via: [], implementing: false }];
+    const visited = new Set<string>();
+    while (queue.length > 0) {
+      const current = queue.shift()!;
+      const visitKey = `${current.name}:${current.implementing}`;
+      if (visited.has(visitKey) || current.via.length > maxDepth) {
+        continue;
+      }
+      visited.add(visitKey);
+
+      for (const edge of this.edges('r.related_name = ?', current.name)) {
+        const via = current.name === name ? [] : [...current.via, current.name];
+        if (current.implementing || edge.kind === 'implements') {
+          // Subclasses of an implementor implement it too
+          add(edge, via);
+          queue.push({ name: edge.typeName, via, implementing: true });
+        } else {
+          queue.push({ name: edge.typeName, via, implementing: false });
+        }
+      }
+    }
+    return [...found.values()].sort((a, b) => a.via.length - b.via.length || a.name.localeCompare(b.name));
+  }
+
+  private edges(where: string, name: string): RelationRow[] {
+    return this.db.getDatabase().prepare(`
+      SELECT DISTINCT r.type_name as typeName, r.related_name as relatedName, r.kind, r.source, f.relative_path as filePath, r.line_number as line
+      FROM type_relations r
+      JOIN files f ON r.file_id = f.id
+      WHERE ${where}
+      ORDER BY r.type_name, f.relative_path
+    `).all(name) as RelationRow[];
+  }
+}
\ No newline at end of file
@@ -0,0 +1,34 @@
+import { PrimordynDB } from '../../database/index.js';
+import { Indexer } from '../index.js';
+import { createTestDb, removeTestDir } from '../../__tests__/helpers/db-fixtures.js';
+import { mkdirSync, writeFileSync } from 'fs';
+import { join } from 'path';
+
+describe('Indexer', () => {
+  const testDir = join(process.cwd(), '.test-indexer');
+  let db: PrimordynDB;
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+    mkdirSync(join(testDir, 'src'), { recursive: true });
+    writeFileSync(join(testDir, 'src/user.ts'), 'export function load(id: string) {\n  return id;\n}\n');
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('keeps the storage mode the index was built with when none is given', async () => {
+    const indexer = new Indexer(db);
+    await indexer.index({ projectRoot: testDir, storage: 'none', verbose: false });
+
+    writeFileSync(join(testDir, 'src/user.ts'), 'export function load(id: string) {\n  return id.trim();\n}\n');
+    const stats = await indexer.index({ projectRoot: testDir, verbose: false });
+
+    expect(stats.filesIndexed).toBe(1);
+    expect(db.getStorageMode()).toBe('none');
+    const row = db.getDatabase().prepare('SELECT content FROM files').get() as { content: string };
+    expect(row.content).toBe('');
+  });
+});
\ No newline at end of file
@@ -1,26 +1,42 @@
 import { PrimordynDB } from '../database/index.js';
 import { FileScanner } from '../scanner/index.js';
 import { ExtractorManager } from '../extractors/extractor-manager.js';
+import { encodeContent } from '../database/content.js';
+import { loadConfig } from '../config/index.js';
+import { SemanticIndex } from '../search/semantic-index.js';
+import { ImplementationIndex } from '../hierarchy/implementations.js';
+import { EmbeddingIndex, createEmbeddingProvider } from '../embeddings/index.js';
 import ora from 'ora';
 import chalk from 'chalk';
 import { encodingForModel, Tiktoken } from 'js-tiktoken';
-import type { FileInfo, ScanOptions, IndexOptions, IndexStats } from '../types/index.js';
+import type { FileInfo, ScanOptions, IndexOptions, IndexStats, StorageMode } from '../types/index.js';
 
 export class Indexer {
   private db: PrimordynDB;
   private tokenEncoder: Tiktoken;
   private extractorManager: ExtractorManager;
+  private semanticIndex: SemanticIndex;
+  private storageMode: StorageMode = 'full';
+  // Languages of the files (re)indexed in the current run
+  private indexedLanguages = new Set<string>();
 
   constructor(db: PrimordynDB) {
     this.db = db;
     // Use GPT-4 encoder as it's similar to Claude's tokenization
     this.tokenEncoder = encodingForModel('gpt-4');
     this.extractorManager = new ExtractorManager();
+    this.semanticIndex = new SemanticIndex(db);
   }
 
   public async index(options: IndexOptions = {}): Promise<IndexStats> {
     const startTime = Date.now();
     const projectRoot = options.projectRoot || process.cwd();
+    const database = this.db.getDatabase();
+    this.indexedLanguages.clear();
+
+    // Only one writer at a time; throws IndexLockError if another run holds the lock
+    const lock = this.db.createIndexLock();
+    lock.acquire('index');
     
     const spinner = options.verbose !== false 
       ? ora('Scanning project files...').start()
@@ -35,6 +51,9 @@ export class Indexer {
     };
 
     try {
+      const config = loadConfig(projectRoot);
+      this.storageMode = options.storage || config.storage.mode || this.db.getStorageMode();
+
       // Configure scanner
       const scanOptions: ScanOptions = {
         rootPath: projectRoot,
@@ -45,20 +64,66 @@ export class Indexer {
       };
 
       const scanner = new FileScanner(scanOptions);
-      const files = await scanner.scan();
+      // Only the given files when re-indexing on save, otherwise the whole project
+      const files = options.paths ? await scanner.scanPaths(options.paths) : await scanner.scan();
 
       if (spinner) {
         spinner.text = `Found ${files.length} files to index`;
       }
+      lock.update(0, files.length);
+
+      // The whole run is one transaction so concurrent readers keep seeing the
+      // previous index until it commits; each file gets its own savepoint
+      database.exec('BEGIN IMMEDIATE');
+
+      try {
+        // Throws if the existing index uses a different mode and needs --clear first
+        this.db.setStorageMode(this.storageMode);
+
+        // Process files in batches for better performance
+        const batchSize = 10;
+        for (let i = 0; i < files.length; i += batchSize) {
+          const batch = files.slice(i, i + batchSize);
+          await Promise.all(batch.map(file => this.indexFile(file, stats, options)));
+
+          const processed = Math.min(i + batchSize, files.length);
+          lock.update(processed, files.length);
+          if (spinner) {
+            spinner.text = `Indexing files... ${processed}/${files.length}`;
+          }
+        }
+
+        if (stats.filesIndexed > 0) {
+          this.semanticIndex.refresh();
+          // Go interface satisfaction and interface call fan-out span files, so they're derived last
+          new ImplementationIndex(this.db).refresh({ languages: [...this.indexedLanguages] });
+        }
 
-      // Process files in batches for better performance
-      const batchSize = 10;
-      for (let i = 0; i < files.length; i += batchSize) {
-        const batch = files.slice(i, i + batchSize);
-        await Promise.all(batch.map(file => this.indexFile(file, stats, options)));
+        // Invalidate cached graphs/impact results computed against the old index
+        this.db.bumpIndexGeneration();
 
+        database.exec('COMMIT');
+      } catch (error) {
+        database.exec('ROLLBACK');
+        throw error;
+      }
+
+      // Embedding may go over the network, so it runs after the index is committed;
+      // a failure leaves semantic search on the local vectors until the next run
+      const provider = options.embeddings === false ? null : createEmbeddingProvider(config.embeddings);
+      if (provider) {
         if (spinner) {
-          spinner.text = `Indexing files... ${Math.min(i + batchSize, files.length)}/${files.length}`;
+          spinner.text = 'Embedding new and changed code...';
+        }
+        try {
+          const embedded = await new EmbeddingIndex(this.db, provider, config.embeddings, config.redaction).update((done, total) => {
+            if (spinner && total > 0) {
+              spinner.text = `Embedding chunks... ${done}/${total}`;
+            }
+          });
+          stats.chunksEmbedded = embedded.embedded;
+        } catch (error) {
+          stats.embeddingError = error instanceof Error ? error.message : String(error);
         }
       }
 
@@ -74,11 +139,17 @@ export class Indexer {
         console.log(chalk.yellow(`⚠ ${stats.errors} files could not be indexed`));
       }
 
+      if (stats.embeddingError && spinner) {
+        console.log(chalk.yellow(`⚠ Embeddings not updated: ${stats.embeddingError}`));
+      }
+
     } catch (error) {
       if (spinner) {
         spinner.fail(chalk.red('Failed to index project'));
       }
       throw error;
+    } finally {
+      lock.release();
     }
 
     return stats;
@@ -103,20 +174,22 @@ export class Indexer {
       // Extract context using the appropriate language extractor
       const context = await this.extractorManager.extract(fileInfo);
 
-      // Begin transaction
-      database.prepare('BEGIN').run();
+      // Savepoint within the run's transaction so a bad file doesn't abort the rest
+      database.exec('SAVEPOINT index_file');
 
       try {
         let fileId: number;
+        const stored = encodeContent(fileInfo.content, this.storageMode);
 
         if (existing) {
           // Update existing file
           database.prepare(`
             UPDATE files 
-            SET content = ?, hash = ?, size = ?, language = ?, last_modified = ?, indexed_at = CURRENT_TIMESTAMP, metadata = ?
+            SET content = ?, content_blob = ?, hash = ?, size = ?, language = ?, last_modified = ?, indexed_at = CURRENT_TIMESTAMP, metadata = ?
             WHERE id = ?
           `).run(
-            fileInfo.content,
+            stored.content,
+            stored.blob,
             fileInfo.hash,
             fileInfo.size,
             fileInfo.language,
@@ -126,17 +199,21 @@ export class Indexer {
           );
           fileId = existing.id;
 
-          // Delete old symbols
+          // Delete old symbols, type edges and references
+          database.prepare('DELETE FROM type_relations WHERE file_id = ?').run(fileId);
+          database.prepare('DELETE FROM symbol_references WHERE file_id = ?').run(fileId);
+          database.prepare('DELETE FROM occurrences WHERE file_id = ?').run(fileId);
           database.prepare('DELETE FROM symbols WHERE file_id = ?').run(fileId);
         } else {
           // Insert new file
           const result = database.prepare(`
-            INSERT INTO files (path, relative_path, content, hash, size, language, last_modified, metadata)
-            VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+            INSERT INTO files (path, relative_path, content, content_blob, hash, size, language, last_modified, metadata)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
           `).run(
             fileInfo.path,
             fileInfo.relativePath,
-            fileInfo.content,
+            stored.content,
+            stored.blob,
             fileInfo.hash,
             fileInfo.size,
             fileInfo.language,
@@ -146,14 +223,24 @@ export class Indexer {
           fileId = result.lastInsertRowid as number;
         }
 
+        if (this.storageMode !== 'full') {
+          // The contentless search index isn't fed by triggers since files.content is empty
+          database.prepare('DELETE FROM files_fts WHERE rowid = ?').run(fileId);
+          database.prepare(`
+            INSERT INTO files_fts (rowid, relative_path, content, language) VALUES (?, ?, ?, ?)
+          `).run(fileId, fileInfo.relativePath, fileInfo.content, fileInfo.language);
+        }
+
         // Insert symbols
         const insertSymbol = database.prepare(`
           INSERT INTO symbols (file_id, name, type, line_start, line_end, signature, documentation, metadata)
           VALUES (?, ?, ?, ?, ?, ?, ?, ?)
         `);
 
+        const lines = fileInfo.content.split('\n');
+        const storedSymbols: Array<{ id: number; lineStart: number; lineEnd: number }> = [];
         for (const symbol of context.symbols) {
-          insertSymbol.run(
+          const inserted = insertSymbol.run(
             fileId,
             symbol.name,
             symbol.type,
@@ -163,6 +250,13 @@ export class Indexer {
             symbol.documentation || null,
             JSON.stringify(symbol.metadata || {})
           );
+          storedSymbols.push({ id: inserted.lastInsertRowid as number, lineStart: symbol.lineStart, lineEnd: symbol.lineEnd });
+          this.semanticIndex.addSymbol(inserted.lastInsertRowid as number, {
+            name: symbol.name,
+            signature: symbol.signature,
+            documentation: symbol.documentation,
+            body: lines.slice(symbol.lineStart - 1, symbol.lineEnd).join('\n')
+          });
           stats.symbolsExtracted++;
         }
 
@@ -234,6 +328,51 @@ export class Indexer {
           }
         }
 
+        // Store supertype edges, attached to the declaring symbol when it's in this file
+        if (context.typeRelations.length > 0) {
+          const insertRelation = database.prepare(`
+            INSERT INTO type_relations (file_id, symbol_id, type_name, related_name, kind, line_number)
+            VALUES (?, ?, ?, ?, ?, ?)
+          `);
+          const findType = database.prepare(
+            'SELECT id FROM symbols WHERE file_id = ? AND name = ? ORDER BY ABS(line_start - ?) LIMIT 1'
+          );
+          for (const relation of context.typeRelations) {
+            const declaring = findType.get(fileId, relation.typeName, relation.line) as { id: number } | undefined;
+            insertRelation.run(fileId, declaring?.id ?? null, relation.typeName, relation.relatedName, relation.kind, relation.line);
+          }
+        }
+
+        // Store type references, counting supertype clauses as references of their own kind
+        const references = [
+          ...context.typeReferences,
+          ...context.typeRelations.map(relation => ({ name: relation.relatedName, kind: relation.kind, line: relation.line }))
+        ];
+        if (references.length > 0) {
+          const insertReference = database.prepare(`
+            INSERT INTO symbol_references (file_id, symbol_id, name, kind, line_number) VALUES (?, ?, ?, ?, ?)
+          `);
+          const findEnclosing = database.prepare(
+            'SELECT id FROM symbols WHERE file_id = ? AND line_start <= ? AND line_end >= ? ORDER BY line_end - line_start LIMIT 1'
+          );
+          for (const reference of references) {
+            const enclosing = findEnclosing.get(fileId, reference.line, reference.line) as { id: number } | undefined;
+            insertReference.run(fileId, enclosing?.id ?? null, reference.name, reference.kind, reference.line);
+          }
+        }
+
+        // Store identifier occurrences, attached to the innermost symbol they're in
+        if (context.occurrences.length > 0) {
+          const insertOccurrence = database.prepare(`
+            INSERT INTO occurrences (file_id, symbol_id, name, role, line_number, column_number) VALUES (?, ?, ?, ?, ?, ?)
+          `);
+          const innermostFirst = [...storedSymbols].sort((a, b) => (a.lineEnd - a.lineStart) - (b.lineEnd - b.lineStart));
+          for (const occurrence of context.occurrences) {
+            const enclosing = innermostFirst.find(symbol => symbol.lineStart <= occurrence.line && symbol.lineEnd >= occurrence.line);
+            insertOccurrence.run(fileId, enclosing?.id ?? null, occurrence.name, occurrence.role, occurrence.line, occurrence.column);
+          }
+        }
+
         // Store imports/exports in metadata
         if (context.imports.length > 0 || context.exports.length > 0) {
           database.prepare(`
@@ -246,11 +385,15 @@ export class Indexer {
           );
         }
 
-        database.prepare('COMMIT').run();
+        database.exec('RELEASE index_file');
         stats.filesIndexed++;
+        if (fileInfo.language) {
+          this.indexedLanguages.add(fileInfo.language);
+        }
 
       } catch (error) {
-        database.prepare('ROLLBACK').run();
+        database.exec('ROLLBACK TO index_file');
+        database.exec('RELEASE index_file');
         throw error;
       }
 
@@ -316,9 +459,22 @@ export class Indexer {
 
   public async clearIndex(): Promise<void> {
     const database = this.db.getDatabase();
-    database.prepare('DELETE FROM call_graph').run();
-    database.prepare('DELETE FROM symbols').run();
-    database.prepare('DELETE FROM files').run();
-    database.prepare('DELETE FROM context_cache').run();
+    const lock = this.db.createIndexLock();
+    lock.acquire('clear');
+
+    try {
+      database.transaction(() => {
+        database.prepare('DELETE FROM call_graph').run();
+        database.prepare('DELETE FROM type_relations').run();
+        database.prepare('DELETE FROM symbol_references').run();
+        database.prepare('DELETE FROM occurrences').run();
+        database.prepare('DELETE FROM symbols').run();
+        database.prepare('DELETE FROM files').run();
+        database.prepare('DELETE FROM semantic_terms').run();
+        this.db.bumpIndexGeneration();
+      })();
+    } finally {
+      lock.release();
+    }
   }
 }
\ No newline at end of file
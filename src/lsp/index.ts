@@ -0,0 +1,239 @@
+import { fileURLToPath } from 'url';
+import { PrimordynDB } from '../database/index.js';
+import { Indexer } from '../indexer/index.js';
+import { IndexNavigator } from './navigation.js';
+import { LspError, INTERNAL_ERROR, INVALID_PARAMS, METHOD_NOT_FOUND, PARSE_ERROR, SERVER_NOT_INITIALIZED } from './protocol.js';
+import { VERSION } from '../version.js';
+import type { LspCallHierarchyItem, LspMessage, LspPosition } from '../types/index.js';
+
+export { MessageReader, encodeMessage, LspError } from './protocol.js';
+
+// TextDocumentSyncKind.Full; open documents are only used to find the word under the cursor
+const FULL_SYNC = 1;
+// MessageType for window/logMessage and window/showMessage
+const ERROR = 1;
+const WARNING = 2;
+const INFO = 3;
+
+interface TextDocumentPositionParams {
+  textDocument: { uri: string };
+  position: LspPosition;
+}
+
+export interface LanguageServerOptions {
+  // Used when the client sends no workspace root
+  projectRoot?: string;
+  onExit?: (code: number) => void;
+}
+
+/**
+ * A language server over the index, for navigation in languages whose own
+ * servers aren't running: workspace and document symbols, definitions,
+ * references, hover and call hierarchy. Saved files are re-indexed
+ * incrementally, one at a time.
+ */
+export class LanguageServer {
+  private send: (message: LspMessage) => void;
+  private options: LanguageServerOptions;
+  private db: PrimordynDB | null = null;
+  private navigator: IndexNavigator | null = null;
+  private indexer: Indexer | null = null;
+  private root: string;
+  private documents = new Map<string, string>();
+  private reindexing: Promise<void> = Promise.resolve();
+  private shutdownRequested = false;
+
+  constructor(send: (message: LspMessage) => void, options: LanguageServerOptions = {}) {
+    this.send = send;
+    this.options = options;
+    this.root = options.projectRoot ?? process.cwd();
+  }
+
+  /** Handle one incoming message; null is a body that didn't parse. */
+  public async handle(message: LspMessage | null): Promise<void> {
+    if (!message) {
+      this.send({ jsonrpc: '2.0', id: null, error: { code: PARSE_ERROR, message: 'Invalid JSON' } });
+      return;
+    }
+    if (!message.method) {
+      // A response to a request we never send
+      return;
+    }
+
+    if (message.id === undefined) {
+      try {
+        await this.notify(message.method, message.params);
+      } catch (error) {
+        this.log(ERROR, `${message.method} failed: ${error instanceof Error ? error.message : error}`);
+      }
+      return;
+    }
+
+    try {
+      const result = await this.request(message.method, message.params);
+      this.send({ jsonrpc: '2.0', id: message.id, result: result ?? null });
+    } catch (error) {
+      const code = error instanceof LspError ? error.code : INTERNAL_ERROR;
+      this.send({ jsonrpc: '2.0', id: message.id, error: { code, message: error instanceof Error ? error.message : String(error) } });
+    }
+  }
+
+  public close(): void {
+    this.db?.close();
+    this.db = null;
+    this.navigator = null;
+    this.indexer = null;
+  }
+
+  private async request(method: string, params: unknown): Promise<unknown> {
+    if (method === 'initialize') {
+      return this.initialize(params as { rootUri?: string | null; rootPath?: string | null });
+    }
+    const navigator = this.navigator;
+    if (!navigator) {
+      throw new LspError(SERVER_NOT_INITIALIZED, 'Server not initialized');
+    }
+
+    switch (method) {
+      case 'shutdown':
+        this.shutdownRequested = true;
+        return null;
+      case 'workspace/symbol':
+        return navigator.workspaceSymbols((params as { query?: string }).query ?? '');
+      case 'textDocument/documentSymbol':
+        return navigator.documentSymbols(documentUri(params));
+      case 'textDocument/definition': {
+        const { textDocument, position } = positionParams(params);
+        return navigator.definition(textDocument.uri, position);
+      }
+      case 'textDocument/references': {
+        const { textDocument, position } = positionParams(params);
+        const includeDeclaration = (params as { context?: { includeDeclaration?: boolean } }).context?.includeDeclaration ?? false;
+        return navigator.references(textDocument.uri, position, includeDeclaration);
+      }
+      case 'textDocument/hover': {
+        const { textDocument, position } = positionParams(params);
+        return navigator.hover(textDocument.uri, position);
+      }
+      case 'textDocument/prepareCallHierarchy': {
+        const { textDocument, position } = positionParams(params);
+        return navigator.prepareCallHierarchy(textDocument.uri, position);
+      }
+      case 'callHierarchy/incomingCalls':
+        return navigator.incomingCalls(callHierarchyItem(params));
+      case 'callHierarchy/outgoingCalls':
+        return navigator.outgoingCalls(callHierarchyItem(params));
+      default:
+        throw new LspError(METHOD_NOT_FOUND, `Unhandled method ${method}`);
+    }
+  }
+
+  private async notify(method: string, params: unknown): Promise<void> {
+    switch (method) {
+      case 'initialized':
+        if (this.db && (await this.db.getDatabaseInfo()).fileCount === 0) {
+          this.show(WARNING, `The primordyn index in ${this.root} is empty; run "primordyn index" first.`);
+        }
+        break;
+      case 'exit':
+        this.close();
+        this.options.onExit?.(this.shutdownRequested ? 0 : 1);
+        break;
+      case 'textDocument/didOpen': {
+        const { textDocument } = params as { textDocument: { uri: string; text: string } };
+        this.documents.set(textDocument.uri, textDocument.text);
+        break;
+      }
+      case 'textDocument/didChange': {
+        const { textDocument, contentChanges } = params as { textDocument: { uri: string }; contentChanges: { text: string }[] };
+        if (contentChanges.length > 0) {
+          this.documents.set(textDocument.uri, contentChanges[contentChanges.length - 1].text);
+        }
+        break;
+      }
+      case 'textDocument/didClose':
+        this.documents.delete(documentUri(params));
+        break;
+      case 'textDocument/didSave': {
+        const uri = documentUri(params);
+        this.reindexing = this.reindexing.then(() => this.reindex(uri));
+        await this.reindexing;
+        break;
+      }
+    }
+  }
+
+  private initialize(params: { rootUri?: string | null; rootPath?: string | null }): unknown {
+    if (params?.rootUri?.startsWith('file:')) {
+      this.root = fileURLToPath(params.rootUri);
+    } else if (params?.rootPath) {
+      this.root = params.rootPath;
+    }
+    this.close();
+    this.db = new PrimordynDB(this.root);
+    this.navigator = new IndexNavigator(this.db, this.root, this.documents);
+
+    return {
+      capabilities: {
+        textDocumentSync: { openClose: true, change: FULL_SYNC, save: { includeText: false } },
+        workspaceSymbolProvider: true,
+        documentSymbolProvider: true,
+        definitionProvider: true,
+        referencesProvider: true,
+        hoverProvider: true,
+        callHierarchyProvider: true
+      },
+      serverInfo: { name: 'primordyn', version: VERSION }
+    };
+  }
+
+  // Re-index one saved file; unchanged files are skipped by their hash. Embedding
+  // may run a command or go over the network on every save, so it waits for `index`
+  private async reindex(uri: string): Promise<void> {
+    if (!this.db || !uri.startsWith('file:')) {
+      return;
+    }
+    try {
+      this.indexer ??= new Indexer(this.db);
+      const stats = await this.indexer.index({ projectRoot: this.root, verbose: false, paths: [fileURLToPath(uri)], embeddings: false });
+      this.navigator?.invalidate();
+      if (stats.filesIndexed > 0) {
+        this.log(INFO, `Re-indexed ${uri} (${stats.symbolsExtracted} symbols)`);
+      }
+    } catch (error) {
+      this.log(WARNING, `Re-indexing ${uri} failed: ${error instanceof Error ? error.message : error}`);
+    }
+  }
+
+  private log(type: number, message: string): void {
+    this.send({ jsonrpc: '2.0', method: 'window/logMessage', params: { type, message } });
+  }
+
+  private show(type: number, message: string): void {
+    this.send({ jsonrpc: '2.0', method: 'window/showMessage', params: { type, message } });
+  }
+}
+
+function documentUri(params: unknown): string {
+  const uri = (params as { textDocument?: { uri?: string } } | undefined)?.textDocument?.uri;
+  if (typeof uri !== 'string') {
+    throw new LspError(INVALID_PARAMS, 'Missing textDocument.uri');
+  }
+  return uri;
+}
+
+function positionParams(params: unknown): TextDocumentPositionParams {
+  const position = (params as Partial<TextDocumentPositionParams> | undefined)?.position;
+  if (typeof position?.line !== 'number' || typeof position.character !== 'number') {
+    throw new LspError(INVALID_PARAMS, 'Missing position');
+  }
+  return { textDocument: { uri: documentUri(params) }, position };
+}
+
+function callHierarchyItem(params: unknown): LspCallHierarchyItem {
+  const item = (params as { item?: LspCallHierarchyItem } | undefined)?.item;
+  if (typeof item?.data?.symbolId !== 'number') {
+    throw new LspError(INVALID_PARAMS, 'Missing call hierarchy item');
+  }
+  return item;
+}
\ No newline at end of file
@@ -0,0 +1,392 @@
+import { existsSync, readFileSync } from 'fs';
+import { join, relative } from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+import { PrimordynDB } from '../database/index.js';
+import { loadConfig } from '../config/index.js';
+import { SecretRedactor } from '../security/redactor.js';
+import { CONTAINER_TYPES, lastSegment } from '../utils/symbols.js';
+import type {
+  LspCallHierarchyItem, LspDocumentSymbol, LspHover, LspLocation, LspPosition, LspRange, LspSymbolInformation, Symbol
+} from '../types/index.js';
+
+// Symbol types to LSP SymbolKind
+const SYMBOL_KINDS: Record<Symbol['type'], number> = {
+  module: 2, import: 2, namespace: 3, class: 5, type: 5, method: 6, property: 7, enum: 10, interface: 11,
+  trait: 11, function: 12, variable: 13, export: 13, constant: 14, struct: 23
+};
+const CALLABLES = new Set<Symbol['type']>(['function', 'method']);
+
+const WORKSPACE_SYMBOL_LIMIT = 200;
+const HOVER_LIMIT = 3;
+
+interface NavSymbol {
+  id: number;
+  fileId: number;
+  name: string;
+  type: Symbol['type'];
+  lineStart: number;
+  lineEnd: number;
+  signature: string | null;
+  documentation: string | null;
+  filePath: string;
+  language: string | null;
+}
+
+interface Word {
+  name: string;
+  range: LspRange;
+}
+
+const SYMBOL_COLUMNS = `
+  s.id, s.file_id as fileId, s.name, s.type, s.line_start as lineStart, s.line_end as lineEnd,
+  s.signature, s.documentation, f.relative_path as filePath, f.language
+`;
+
+/**
+ * Answers LSP navigation requests from the index: symbols, definitions,
+ * references and call hierarchy come from the symbols, occurrences and call
+ * graph tables. Names are resolved the way the index does, so it's a
+ * heuristic navigator rather than a type checker.
+ */
+export class IndexNavigator {
+  private db: PrimordynDB;
+  private root: string;
+  private documents: Map<string, string>;
+  private lines = new Map<number, string[]>();
+  private redactor: SecretRedactor;
+
+  // Open documents by URI; their text is used to find the word under the cursor
+  constructor(db: PrimordynDB, root: string, documents: Map<string, string>) {
+    this.db = db;
+    this.root = root;
+    this.documents = documents;
+    this.redactor = new SecretRedactor(loadConfig(db.getProjectRoot()).redaction);
+  }
+
+  /** Drop cached file contents after the index changed. */
+  public invalidate(): void {
+    this.lines.clear();
+  }
+
+  public definition(uri: string, position: LspPosition): LspLocation[] {
+    return this.resolve(uri, position).map(symbol => ({ uri: this.uriFor(symbol.filePath), range: this.selectionRange(symbol) }));
+  }
+
+  public references(uri: string, position: LspPosition, includeDeclaration: boolean): LspLocation[] {
+    const word = this.wordAt(uri, position);
+    if (!word) {
+      return [];
+    }
+    const rows = this.db.getDatabase().prepare(`
+      SELECT f.relative_path as filePath, o.line_number as line, o.column_number as column
+      FROM occurrences o
+      JOIN files f ON o.file_id = f.id
+      WHERE o.name = ? ${includeDeclaration ? '' : "AND o.role != 'definition'"}
+      ORDER BY f.relative_path, o.line_number, o.column_number
+    `).all(word.name) as { filePath: string; line: number; column: number }[];
+    return rows.map(row => ({
+      uri: this.uriFor(row.filePath),
+      range: lineRange(row.line - 1, row.column, row.column + word.name.length)
+    }));
+  }
+
+  public documentSymbols(uri: string): LspDocumentSymbol[] {
+    const file = this.fileFor(uri);
+    if (!file) {
+      return [];
+    }
+    const symbols = this.db.getDatabase().prepare(`
+      SELECT ${SYMBOL_COLUMNS} FROM symbols s JOIN files f ON s.file_id = f.id
+      WHERE s.file_id = ?
+      ORDER BY s.line_start, s.line_end DESC
+    `).all(file.id) as NavSymbol[];
+
+    // Nest each symbol under the innermost one spanning it
+    const roots: LspDocumentSymbol[] = [];
+    const stack: Array<{ symbol: NavSymbol; item: LspDocumentSymbol }> = [];
+    for (const symbol of symbols) {
+      while (stack.length > 0 && !contains(stack[stack.length - 1].symbol, symbol)) {
+        stack.pop();
+      }
+      const parent = stack[stack.length - 1];
+      const item: LspDocumentSymbol = {
+        name: lastSegment(symbol.name),
+        detail: symbol.signature ? this.redactor.redact(symbol.signature) : undefined,
+        kind: symbolKind(symbol, parent?.symbol),
+        range: this.fullRange(symbol),
+        selectionRange: this.selectionRange(symbol),
+        children: []
+      };
+      (parent ? parent.item.children : roots).push(item);
+      stack.push({ symbol, item });
+    }
+    return roots;
+  }
+
+  public workspaceSymbols(query: string): LspSymbolInformation[] {
+    const database = this.db.getDatabase();
+    const symbols = database.prepare(`
+      SELECT ${SYMBOL_COLUMNS} FROM symbols s JOIN files f ON s.file_id = f.id
+      WHERE s.name LIKE ? ESCAPE '\\' AND s.type NOT IN ('import', 'export')
+      ORDER BY s.name = ? DESC, length(s.name), s.name
+      LIMIT ?
+    `).all(`%${query.replace(/[\\%_]/g, match => `\\${match}`)}%`, query, WORKSPACE_SYMBOL_LIMIT) as NavSymbol[];
+
+    const enclosing = database.prepare(`
+      SELECT id, name, type, line_start as lineStart, line_end as lineEnd FROM symbols
+      WHERE file_id = ? AND id != ? AND line_start <= ? AND line_end >= ? AND type IN (${[...CONTAINER_TYPES].map(type => `'${type}'`).join(', ')})
+      ORDER BY line_end - line_start
+      LIMIT 1
+    `);
+    return symbols.map(symbol => {
+      const dot = symbol.name.lastIndexOf('.');
+      const container = dot > 0
+        ? undefined
+        : enclosing.get(symbol.fileId, symbol.id, symbol.lineStart, symbol.lineEnd) as NavSymbol | undefined;
+      return {
+        name: lastSegment(symbol.name),
+        kind: symbolKind(symbol, container),
+        location: { uri: this.uriFor(symbol.filePath), range: this.selectionRange(symbol) },
+        containerName: dot > 0 ? symbol.name.slice(0, dot) : container?.name
+      };
+    });
+  }
+
+  public hover(uri: string, position: LspPosition): LspHover | null {
+    const symbols = this.resolve(uri, position);
+    if (symbols.length === 0) {
+      return null;
+    }
+    const sections = symbols.slice(0, HOVER_LIMIT).map(symbol => {
+      const parts: string[] = [];
+      if (symbol.signature) {
+        parts.push(`\`\`\`${symbol.language ?? ''}\n${this.redactor.redact(symbol.signature)}\n\`\`\``);
+      }
+      if (symbol.documentation) {
+        parts.push(this.redactor.redact(symbol.documentation));
+      }
+      parts.push(`*${symbol.type}* in \`${symbol.filePath}:${symbol.lineStart}\``);
+      return parts.join('\n\n');
+    });
+    if (symbols.length > HOVER_LIMIT) {
+      sections.push(`*${symbols.length - HOVER_LIMIT} more definitions*`);
+    }
+    return {
+      contents: { kind: 'markdown', value: sections.join('\n\n---\n\n') },
+      range: this.wordAt(uri, position)?.range
+    };
+  }
+
+  public prepareCallHierarchy(uri: string, position: LspPosition): LspCallHierarchyItem[] | null {
+    const callables = this.resolve(uri, position).filter(symbol => CALLABLES.has(symbol.type));
+    return callables.length > 0 ? callables.map(symbol => this.callHierarchyItem(symbol)) : null;
+  }
+
+  public incomingCalls(item: LspCallHierarchyItem): Array<{ from: LspCallHierarchyItem; fromRanges: LspRange[] }> {
+    const rows = this.db.getDatabase().prepare(`
+      SELECT cg.caller_symbol_id as symbolId, cg.callee_name as calleeName, cg.line_number as line
+      FROM call_graph cg
+      WHERE cg.callee_symbol_id = ? AND cg.caller_symbol_id IS NOT NULL
+      ORDER BY cg.caller_symbol_id, cg.line_number
+    `).all(item.data.symbolId) as { symbolId: number; calleeName: string; line: number }[];
+    return this.groupCalls(rows, (caller, row) => this.callRange(caller.fileId, row.line, row.calleeName));
+  }
+
+  public outgoingCalls(item: LspCallHierarchyItem): Array<{ to: LspCallHierarchyItem; fromRanges: LspRange[] }> {
+    const caller = this.symbolsById([item.data.symbolId])[0];
+    if (!caller) {
+      return [];
+    }
+    const rows = this.db.getDatabase().prepare(`
+      SELECT cg.callee_symbol_id as symbolId, cg.callee_name as calleeName, cg.line_number as line
+      FROM call_graph cg
+      WHERE cg.caller_symbol_id = ? AND cg.callee_symbol_id IS NOT NULL
+      ORDER BY cg.callee_symbol_id, cg.line_number
+    `).all(item.data.symbolId) as { symbolId: number; calleeName: string; line: number }[];
+    return this.groupCalls(rows, (_callee, row) => this.callRange(caller.fileId, row.line, row.calleeName))
+      .map(call => ({ to: call.from, fromRanges: call.fromRanges }));
+  }
+
+  /**
+   * The symbols the word at a position names: the symbol defined there, the
+   * call graph's resolution for a call on that line, or symbols with that
+   * name, preferring ones in the same file.
+   */
+  private resolve(uri: string, position: LspPosition): NavSymbol[] {
+    const word = this.wordAt(uri, position);
+    if (!word) {
+      return [];
+    }
+    const database = this.db.getDatabase();
+    const file = this.fileFor(uri);
+    const named = (database.prepare(`
+      SELECT ${SYMBOL_COLUMNS} FROM symbols s JOIN files f ON s.file_id = f.id
+      WHERE (s.name = ? OR s.name LIKE ?) AND s.type NOT IN ('import', 'export')
+      ORDER BY f.relative_path, s.line_start
+    `).all(word.name, `%.${word.name}`) as NavSymbol[]).filter(symbol => lastSegment(symbol.name) === word.name);
+
+    if (file) {
+      const defined = named.filter(symbol => symbol.fileId === file.id && symbol.lineStart === position.line + 1);
+      if (defined.length > 0) {
+        return defined;
+      }
+      const calls = database.prepare(`
+        SELECT DISTINCT callee_symbol_id as id, callee_name as calleeName FROM call_graph
+        WHERE caller_file_id = ? AND line_number = ? AND callee_symbol_id IS NOT NULL
+      `).all(file.id, position.line + 1) as { id: number; calleeName: string }[];
+      const called = calls.filter(call => lastSegment(call.calleeName) === word.name).map(call => call.id);
+      if (called.length > 0) {
+        return this.symbolsById(called);
+      }
+      const local = named.filter(symbol => symbol.fileId === file.id);
+      if (local.length > 0) {
+        return local;
+      }
+    }
+    return named;
+  }
+
+  private groupCalls(
+    rows: { symbolId: number; calleeName: string; line: number }[],
+    range: (symbol: NavSymbol, row: { symbolId: number; calleeName: string; line: number }) => LspRange
+  ): Array<{ from: LspCallHierarchyItem; fromRanges: LspRange[] }> {
+    const symbols = new Map(this.symbolsById([...new Set(rows.map(row => row.symbolId))]).map(symbol => [symbol.id, symbol]));
+    const calls = new Map<number, { from: LspCallHierarchyItem; fromRanges: LspRange[] }>();
+    for (const row of rows) {
+      const symbol = symbols.get(row.symbolId);
+      if (!symbol) {
+        continue;
+      }
+      const call = calls.get(symbol.id) ?? { from: this.callHierarchyItem(symbol), fromRanges: [] };
+      call.fromRanges.push(range(symbol, row));
+      calls.set(symbol.id, call);
+    }
+    return [...calls.values()];
+  }
+
+  private callHierarchyItem(symbol: NavSymbol): LspCallHierarchyItem {
+    return {
+      name: lastSegment(symbol.name),
+      kind: SYMBOL_KINDS[symbol.type] ?? 13,
+      detail: symbol.signature ? this.redactor.redact(symbol.signature) : undefined,
+      uri: this.uriFor(symbol.filePath),
+      range: this.fullRange(symbol),
+      selectionRange: this.selectionRange(symbol),
+      data: { symbolId: symbol.id }
+    };
+  }
+
+  // The callee's name on the calling line, or the whole line when it isn't there
+  private callRange(fileId: number, line: number, calleeName: string): LspRange {
+    const text = this.fileLines(fileId)[line - 1] ?? '';
+    const name = lastSegment(calleeName);
+    const column = text.indexOf(name);
+    return column >= 0 ? lineRange(line - 1, column, column + name.length) : lineRange(line - 1, 0, text.length);
+  }
+
+  private symbolsById(ids: number[]): NavSymbol[] {
+    if (ids.length === 0) {
+      return [];
+    }
+    return this.db.getDatabase().prepare(`
+      SELECT ${SYMBOL_COLUMNS} FROM symbols s JOIN files f ON s.file_id = f.id
+      WHERE s.id IN (${ids.map(() => '?').join(', ')})
+      ORDER BY f.relative_path, s.line_start
+    `).all(...ids) as NavSymbol[];
+  }
+
+  // The symbol's name on its first line, from its definition occurrence when there is one
+  private selectionRange(symbol: NavSymbol): LspRange {
+    const name = lastSegment(symbol.name);
+    const occurrence = this.db.getDatabase().prepare(`
+      SELECT column_number as column FROM occurrences
+      WHERE file_id = ? AND line_number = ? AND name = ? AND role = 'definition'
+      LIMIT 1
+    `).get(symbol.fileId, symbol.lineStart, name) as { column: number } | undefined;
+    const column = occurrence?.column ?? Math.max(0, (this.fileLines(symbol.fileId)[symbol.lineStart - 1] ?? '').indexOf(name));
+    return lineRange(symbol.lineStart - 1, column, column + name.length);
+  }
+
+  private fullRange(symbol: NavSymbol): LspRange {
+    const lastLine = this.fileLines(symbol.fileId)[symbol.lineEnd - 1] ?? '';
+    return {
+      start: { line: symbol.lineStart - 1, character: 0 },
+      end: { line: symbol.lineEnd - 1, character: lastLine.length }
+    };
+  }
+
+  // The identifier under the cursor, from the open document or the file on disk
+  private wordAt(uri: string, position: LspPosition): Word | null {
+    const text = this.documents.get(uri) ?? this.diskText(uri);
+    const line = text?.split('\n')[position.line];
+    if (line === undefined) {
+      return null;
+    }
+    const isWordChar = (char: string | undefined) => char !== undefined && /[\w$]/.test(char);
+    let start = Math.min(position.character, line.length);
+    let end = start;
+    while (start > 0 && isWordChar(line[start - 1])) {
+      start--;
+    }
+    while (isWordChar(line[end])) {
+      end++;
+    }
+    const name = line.slice(start, end);
+    if (!name || /^\d/.test(name)) {
+      return null;
+    }
+    return { name, range: lineRange(position.line, start, end) };
+  }
+
+  private diskText(uri: string): string | null {
+    try {
+      const path = fileURLToPath(uri);
+      return existsSync(path) ? readFileSync(path, 'utf8') : null;
+    } catch {
+      return null;
+    }
+  }
+
+  private fileFor(uri: string): { id: number } | null {
+    let relativePath: string;
+    try {
+      relativePath = relative(this.root, fileURLToPath(uri));
+    } catch {
+      return null;
+    }
+    const file = this.db.getDatabase().prepare('SELECT id FROM files WHERE relative_path = ?').get(relativePath) as { id: number } | undefined;
+    return file ?? null;
+  }
+
+  private uriFor(relativePath: string): string {
+    return pathToFileURL(join(this.root, relativePath)).href;
+  }
+
+  // Lines as indexed, so ranges line up with the stored line numbers
+  private fileLines(fileId: number): string[] {
+    let lines = this.lines.get(fileId);
+    if (!lines) {
+      const row = this.db.getDatabase().prepare('SELECT content FROM files_with_content WHERE id = ?').get(fileId) as { content: string } | undefined;
+      lines = (row?.content ?? '').split('\n').map(line => line.replace(/\r$/, ''));
+      this.lines.set(fileId, lines);
+    }
+    return lines;
+  }
+}
+
+function lineRange(line: number, start: number, end: number): LspRange {
+  return { start: { line, character: start }, end: { line, character: end } };
+}
+
+function contains(outer: NavSymbol, inner: NavSymbol): boolean {
+  return outer.lineStart <= inner.lineStart && outer.lineEnd >= inner.lineEnd &&
+    (outer.lineStart < inner.lineStart || outer.lineEnd > inner.lineEnd);
+}
+
+// Functions declared inside a type are its methods
+function symbolKind(symbol: NavSymbol, container: NavSymbol | undefined): number {
+  if (symbol.type === 'function' && (symbol.name.includes('.') || (container && CONTAINER_TYPES.has(container.type)))) {
+    return SYMBOL_KINDS.method;
+  }
+  return SYMBOL_KINDS[symbol.type] ?? SYMBOL_KINDS.variable;
+}
\ No newline at end of file
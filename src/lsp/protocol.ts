@@ -0,0 +1,66 @@
+import type { LspMessage } from '../types/index.js';
+
+// JSON-RPC error codes used by the server
+export const PARSE_ERROR = -32700;
+export const METHOD_NOT_FOUND = -32601;
+export const INVALID_PARAMS = -32602;
+export const INTERNAL_ERROR = -32603;
+export const SERVER_NOT_INITIALIZED = -32002;
+
+export class LspError extends Error {
+  public code: number;
+
+  constructor(code: number, message: string) {
+    super(message);
+    this.name = 'LspError';
+    this.code = code;
+  }
+}
+
+const HEADER_END = '\r\n\r\n';
+
+/**
+ * Splits a byte stream into LSP messages (`Content-Length` framed JSON).
+ * A body that isn't JSON comes out as null so the caller can answer with a
+ * parse error; a header block without a length is dropped.
+ */
+export class MessageReader {
+  private buffer: Buffer = Buffer.alloc(0);
+
+  public push(chunk: Buffer): Array<LspMessage | null> {
+    this.buffer = Buffer.concat([this.buffer, chunk]);
+    const messages: Array<LspMessage | null> = [];
+
+    for (;;) {
+      const headerEnd = this.buffer.indexOf(HEADER_END);
+      if (headerEnd < 0) {
+        break;
+      }
+      const headers = this.buffer.subarray(0, headerEnd).toString('ascii');
+      const length = Number(headers.match(/^content-length:\s*(\d+)\s*$/im)?.[1]);
+      const bodyStart = headerEnd + HEADER_END.length;
+      if (!Number.isFinite(length)) {
+        this.buffer = this.buffer.subarray(bodyStart);
+        continue;
+      }
+      if (this.buffer.length < bodyStart + length) {
+        break;
+      }
+
+      const body = this.buffer.subarray(bodyStart, bodyStart + length).toString('utf8');
+      this.buffer = this.buffer.subarray(bodyStart + length);
+      try {
+        messages.push(JSON.parse(body) as LspMessage);
+      } catch {
+        messages.push(null);
+      }
+    }
+    return messages;
+  }
+}
+
+/** Frame a message for the wire. */
+export function encodeMessage(message: LspMessage): Buffer {
+  const body = Buffer.from(JSON.stringify(message), 'utf8');
+  return Buffer.concat([Buffer.from(`Content-Length: ${body.length}${HEADER_END}`, 'ascii'), body]);
+}
\ No newline at end of file
@@ -0,0 +1,58 @@
+import { PrimordynDB } from '../../database/index.js';
+import { RepoMapBuilder, formatRepoMap } from '../index.js';
+import { createTestDb, removeTestDir, addFile, addSymbol, addCall } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('RepoMapBuilder', () => {
+  const testDir = join(process.cwd(), '.test-map');
+  let db: PrimordynDB;
+
+  const file = (relativePath: string, language: string, content: string, exports: string[] = []) =>
+    addFile(db, relativePath, { language, content, metadata: { tokens: 10, exports } });
+  const call = (callerFileId: number, calleeSymbolId: number, calleeFileId: number, calleeName: string) =>
+    addCall(db, { callerFileId, calleeName, calleeSymbolId, calleeFileId });
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+
+    const main = file('cmd/server/main.go', 'go', 'package main\n\nfunc main() {}');
+    const store = file('internal/store/store.go', 'go', 'package store');
+    const api = file('internal/api/handler.go', 'go', 'package api');
+    addSymbol(db, main, 'main');
+    const open = addSymbol(db, store, 'Open');
+    addSymbol(db, store, 'helper');
+    call(main, open, store, 'Open');
+    call(api, open, store, 'Open');
+    file('src/index.ts', 'typescript', 'export const x = 1;', ['x']);
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('maps layout, entry points, central symbols and public APIs', () => {
+    const map = new RepoMapBuilder(db).build({ maxTokens: 4000 });
+
+    expect(map.files).toBe(4);
+    expect(map.entryPoints.map(entry => entry.filePath)).toContain('cmd/server/main.go');
+    expect(map.entryPoints.map(entry => entry.filePath)).toContain('src/index.ts');
+    expect(map.directories.map(directory => directory.path)).toContain('internal/store');
+    expect(map.centralSymbols[0].name).toBe('Open');
+    expect(map.centralSymbols[0].callerFiles).toBe(2);
+
+    // Go exports are the capitalized names; explicit export lists win elsewhere
+    const store = map.modules.find(module => module.filePath === 'internal/store/store.go');
+    expect(store?.exports).toEqual(['Open']);
+    expect(map.modules[0].filePath).toBe('internal/store/store.go');
+    expect(map.modules.find(module => module.filePath === 'src/index.ts')?.exports).toEqual(['x']);
+    expect(map.truncated).toBe(false);
+  });
+
+  test('stays within the token budget', () => {
+    const map = new RepoMapBuilder(db).build({ maxTokens: 40 });
+    expect(map.truncated).toBe(true);
+    expect(map.totalTokens).toBeLessThan(41);
+    expect(formatRepoMap(map)).toContain('raise --tokens');
+  });
+});
\ No newline at end of file
@@ -0,0 +1,315 @@
+import { basename, dirname } from 'path';
+import { encodingForModel, Tiktoken } from 'js-tiktoken';
+import { PrimordynDB } from '../database/index.js';
+import type { CentralSymbol, DirectorySummary, EntryPoint, ModuleExports, RepoMap } from '../types/index.js';
+
+export interface RepoMapOptions {
+  maxTokens?: number;
+  // Directories deeper than this are folded into their parent
+  maxDepth?: number;
+}
+
+interface FileRow {
+  id: number;
+  relativePath: string;
+  language: string | null;
+  size: number;
+  metadata: string | null;
+}
+
+// Share of the budget each section may use; what one leaves over goes to the next
+const SECTION_SHARES = { entryPoints: 0.1, directories: 0.3, centralSymbols: 0.25, modules: 0.35 };
+const MAX_EXPORTS_PER_MODULE = 12;
+// Symbol kinds that make up a module's surface when it has no explicit export list
+const SURFACE_TYPES = new Set(['function', 'class', 'interface', 'type', 'struct', 'enum', 'trait', 'constant']);
+
+// File names that conventionally start a program
+const ENTRY_FILE_NAMES: Record<string, string> = {
+  'main.go': 'Go main package',
+  'main.rs': 'Rust binary crate root',
+  'lib.rs': 'Rust library crate root',
+  '__main__.py': 'Python package entry point',
+  'manage.py': 'Django management script',
+  'main.py': 'Python main module',
+  'app.py': 'application module',
+  'cli.ts': 'CLI entry', 'cli.js': 'CLI entry', 'cli.py': 'CLI entry',
+  'index.ts': 'package index', 'index.js': 'package index',
+  'main.ts': 'main module', 'main.js': 'main module',
+  'server.ts': 'server entry', 'server.js': 'server entry', 'server.go': 'server entry',
+  'Main.java': 'Java main class', 'Program.cs': 'C# program entry'
+};
+
+/**
+ * A token-budgeted overview of an indexed repository: the directory tree,
+ * entry points, the most-called symbols and each module's public surface.
+ * Meant as the first thing to read in an unfamiliar codebase.
+ */
+export class RepoMapBuilder {
+  private db: PrimordynDB;
+  private tokenEncoder: Tiktoken;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+    this.tokenEncoder = encodingForModel('gpt-4');
+  }
+
+  public build(options: RepoMapOptions = {}): RepoMap {
+    const maxTokens = options.maxTokens || 4000;
+    const database = this.db.getDatabase();
+    const files = database.prepare(`
+      SELECT id, relative_path as relativePath, language, size, metadata FROM files ORDER BY relative_path
+    `).all() as FileRow[];
+    const languages = database.prepare(`
+      SELECT language, COUNT(*) as count FROM files WHERE language IS NOT NULL GROUP BY language ORDER BY count DESC
+    `).all() as { language: string; count: number }[];
+    const symbolCount = (database.prepare('SELECT COUNT(*) as count FROM symbols').get() as { count: number }).count;
+
+    const map: RepoMap = {
+      files: files.length,
+      symbols: symbolCount,
+      languages,
+      entryPoints: [],
+      directories: [],
+      centralSymbols: [],
+      modules: [],
+      totalTokens: 0,
+      maxTokens,
+      truncated: false
+    };
+    map.totalTokens = this.countTokens(formatHeader(map));
+
+    // Each section takes what fits of its share, best items first
+    let carry = 0;
+    const fill = <T>(items: T[], share: number, format: (item: T) => string): T[] => {
+      const budget = Math.max(0, Math.floor((maxTokens - this.countTokens(formatHeader(map))) * share) + carry);
+      const taken: T[] = [];
+      let used = 0;
+      for (const item of items) {
+        const tokens = this.countTokens(format(item)) + 1;
+        if (used + tokens > budget) {
+          map.truncated = true;
+          continue;
+        }
+        taken.push(item);
+        used += tokens;
+      }
+      carry = budget - used;
+      map.totalTokens += used;
+      return taken;
+    };
+
+    map.entryPoints = fill(this.entryPoints(files), SECTION_SHARES.entryPoints, formatEntryPoint);
+    // Shallow directories first so a tight budget still shows the top of the tree
+    map.directories = fill(
+      this.directories(files, options.maxDepth ?? 3).sort((a, b) => a.depth - b.depth || b.tokens - a.tokens),
+      SECTION_SHARES.directories,
+      formatDirectory
+    ).sort((a, b) => a.path.localeCompare(b.path));
+    map.centralSymbols = fill(this.centralSymbols(), SECTION_SHARES.centralSymbols, formatCentralSymbol);
+    map.modules = fill(this.modules(files), SECTION_SHARES.modules, formatModule);
+
+    return map;
+  }
+
+  private directories(files: FileRow[], maxDepth: number): DirectorySummary[] {
+    const summaries = new Map<string, DirectorySummary & { languageCounts: Map<string, number> }>();
+    for (const file of files) {
+      const parts = dirname(file.relativePath).split('/').filter(part => part !== '.');
+      const tokens = file.metadata ? (JSON.parse(file.metadata).tokens as number) || 0 : 0;
+      // A file counts toward every enclosing directory down to maxDepth
+      for (let depth = 0; depth <= Math.min(parts.length, maxDepth); depth++) {
+        const path = depth === 0 ? '.' : parts.slice(0, depth).join('/');
+        let summary = summaries.get(path);
+        if (!summary) {
+          summary = { path, depth, files: 0, size: 0, tokens: 0, languages: [], languageCounts: new Map() };
+          summaries.set(path, summary);
+        }
+        summary.files++;
+        summary.size += file.size;
+        summary.tokens += tokens;
+        if (file.language) {
+          summary.languageCounts.set(file.language, (summary.languageCounts.get(file.language) || 0) + 1);
+        }
+      }
+    }
+
+    return [...summaries.values()].map(({ languageCounts, ...summary }) => ({
+      ...summary,
+      languages: [...languageCounts.entries()].sort((a, b) => b[1] - a[1]).slice(0, 3).map(([language]) => language)
+    }));
+  }
+
+  private entryPoints(files: FileRow[]): EntryPoint[] {
+    const entries = new Map<string, EntryPoint>();
+    const add = (filePath: string, reason: string) => {
+      if (!entries.has(filePath)) {
+        entries.set(filePath, { filePath, reason });
+      }
+    };
+    const indexed = new Set(files.map(file => file.relativePath));
+
+    // Manifests name their entry points outright
+    const manifest = this.db.getDatabase().prepare(`
+      SELECT relative_path as relativePath, content FROM files_with_content WHERE relative_path = 'package.json'
+    `).get() as { relativePath: string; content: string } | undefined;
+    if (manifest) {
+      try {
+        const pkg = JSON.parse(manifest.content) as { main?: string; bin?: string | Record<string, string> };
+        const targets = [pkg.main, ...(typeof pkg.bin === 'string' ? [pkg.bin] : Object.values(pkg.bin || {}))];
+        for (const target of targets.filter((value): value is string => Boolean(value))) {
+          const path = target.replace(/^\.\//, '');
+          // Built output usually maps back to a source file of the same name
+          const source = [path, path.replace(/^dist\//, 'src/').replace(/\.js$/, '.ts')].find(candidate => indexed.has(candidate));
+          add(source || path, `package.json ${target === pkg.main ? 'main' : 'bin'}`);
+        }
+      } catch {
+        // Not valid JSON; fall through to the other heuristics
+      }
+    }
+
+    const mains = this.db.getDatabase().prepare(`
+      SELECT DISTINCT f.relative_path as relativePath, f.language
+      FROM symbols s JOIN files f ON s.file_id = f.id
+      WHERE s.name = 'main' AND s.type IN ('function', 'method')
+    `).all() as { relativePath: string; language: string | null }[];
+    for (const main of mains) {
+      add(main.relativePath, `defines main()${main.language ? ` (${main.language})` : ''}`);
+    }
+
+    const scripts = this.db.getDatabase().prepare(`
+      SELECT relative_path as relativePath FROM files_with_content
+      WHERE language = 'python' AND (content LIKE '%__name__ == "__main__"%' OR content LIKE '%__name__ == ''__main__''%')
+    `).all() as { relativePath: string }[];
+    for (const script of scripts) {
+      add(script.relativePath, 'runs as a script (__main__ guard)');
+    }
+
+    for (const file of files) {
+      const reason = ENTRY_FILE_NAMES[basename(file.relativePath)];
+      // Only shallow index files; every directory has one of those
+      const depth = file.relativePath.split('/').length - 1;
+      if (reason && (!/^index\./.test(basename(file.relativePath)) || depth <= 1)) {
+        add(file.relativePath, reason);
+      }
+    }
+
+    return [...entries.values()];
+  }
+
+  private centralSymbols(): CentralSymbol[] {
+    return this.db.getDatabase().prepare(`
+      SELECT
+        s.name,
+        s.type,
+        f.relative_path as filePath,
+        s.line_start as line,
+        COUNT(*) as callers,
+        COUNT(DISTINCT c.caller_file_id) as callerFiles
+      FROM call_graph c
+      JOIN symbols s ON c.callee_symbol_id = s.id
+      JOIN files f ON s.file_id = f.id
+      GROUP BY s.id
+      ORDER BY callerFiles DESC, callers DESC, s.name
+      LIMIT 50
+    `).all() as CentralSymbol[];
+  }
+
+  private modules(files: FileRow[]): ModuleExports[] {
+    const database = this.db.getDatabase();
+    const importers = database.prepare(`
+      SELECT COUNT(DISTINCT caller_file_id) as count FROM call_graph WHERE callee_file_id = ? AND caller_file_id != ?
+    `);
+    const surface = database.prepare(`
+      SELECT name, type FROM symbols WHERE file_id = ? ORDER BY line_start
+    `);
+
+    const modules: (ModuleExports & { importers: number })[] = [];
+    for (const file of files) {
+      const declared: string[] = file.metadata ? JSON.parse(file.metadata).exports || [] : [];
+      const exports = declared.length > 0
+        ? declared
+        : (surface.all(file.id) as { name: string; type: string }[])
+          .filter(symbol => SURFACE_TYPES.has(symbol.type) && isPublicName(symbol.name, file.language))
+          .map(symbol => symbol.name);
+      if (exports.length === 0) {
+        continue;
+      }
+      modules.push({
+        filePath: file.relativePath,
+        exports: [...new Set(exports)],
+        importers: (importers.get(file.id, file.id) as { count: number }).count
+      });
+    }
+
+    // Modules the rest of the code leans on come first
+    return modules
+      .sort((a, b) => b.importers - a.importers || b.exports.length - a.exports.length)
+      .map(({ importers: _importers, ...module }) => module);
+  }
+
+  private countTokens(text: string): number {
+    try {
+      return this.tokenEncoder.encode(text).length;
+    } catch {
+      return Math.ceil(text.length / 4);
+    }
+  }
+}
+
+function plural(count: number, noun: string): string {
+  return `${count} ${noun}${count === 1 ? '' : 's'}`;
+}
+
+// Language conventions for what's visible outside a module
+export function isPublicName(name: string, language: string | null): boolean {
+  if (language === 'go') {
+    return /^[A-Z]/.test(name);
+  }
+  return !name.startsWith('_') && !name.startsWith('#');
+}
+
+export function formatHeader(map: RepoMap): string {
+  const languages = map.languages.slice(0, 6).map(entry => `${entry.language} (${entry.count})`).join(', ');
+  return `# Repository map\n\n${map.files} files, ${map.symbols} symbols` + (languages ? `; ${languages}` : '');
+}
+
+export function formatEntryPoint(entry: EntryPoint): string {
+  return `- ${entry.filePath} - ${entry.reason}`;
+}
+
+export function formatDirectory(directory: DirectorySummary): string {
+  const name = directory.path === '.' ? './' : `${basename(directory.path)}/`;
+  const languages = directory.languages.length ? ` [${directory.languages.join(', ')}]` : '';
+  return `${'  '.repeat(directory.depth)}${name} ${directory.files} files, ${directory.tokens.toLocaleString()} tokens${languages}`;
+}
+
+export function formatCentralSymbol(symbol: CentralSymbol): string {
+  return `- ${symbol.name} (${symbol.type}) ${symbol.filePath}:${symbol.line} - ${plural(symbol.callers, 'call')} from ${plural(symbol.callerFiles, 'file')}`;
+}
+
+export function formatModule(module: ModuleExports): string {
+  const shown = module.exports.slice(0, MAX_EXPORTS_PER_MODULE);
+  const more = module.exports.length - shown.length;
+  return `- ${module.filePath}: ${shown.join(', ')}${more > 0 ? ` (+${more} more)` : ''}`;
+}
+
+/** The map as markdown, in the shape `primordyn map` prints for agents. */
+export function formatRepoMap(map: RepoMap): string {
+  const sections = [formatHeader(map)];
+  if (map.entryPoints.length > 0) {
+    sections.push(`## Entry points\n${map.entryPoints.map(formatEntryPoint).join('\n')}`);
+  }
+  if (map.directories.length > 0) {
+    sections.push(`## Layout\n\`\`\`\n${map.directories.map(formatDirectory).join('\n')}\n\`\`\``);
+  }
+  if (map.centralSymbols.length > 0) {
+    sections.push(`## Most-called symbols\n${map.centralSymbols.map(formatCentralSymbol).join('\n')}`);
+  }
+  if (map.modules.length > 0) {
+    sections.push(`## Public API by module\n${map.modules.map(formatModule).join('\n')}`);
+  }
+  if (map.truncated) {
+    sections.push(`_Trimmed to ${map.maxTokens} tokens; raise --tokens for more._`);
+  }
+  return sections.join('\n\n');
+}
\ No newline at end of file
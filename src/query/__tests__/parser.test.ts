@@ -0,0 +1,67 @@
+import { parseQuery, isStructuredQuery, QuerySyntaxError } from '../parser.js';
+import { compileQuery } from '../compiler.js';
+import { globToRegExp } from '../match.js';
+
+describe('Query parser', () => {
+  test('should detect filter syntax', () => {
+    expect(isStructuredQuery('type:function')).toBe(true);
+    expect(isStructuredQuery('-path:*_test.go Handler')).toBe(true);
+    expect(isStructuredQuery('UserService')).toBe(false);
+    expect(isStructuredQuery('std::vector')).toBe(false);
+    expect(isStructuredQuery('https://example.com')).toBe(false);
+  });
+
+  test('should combine terms with implicit AND, OR, negation and parentheses', () => {
+    const node = parseQuery('type:function (name:Handle* OR calls:Save) -path:*_test.go');
+
+    expect(node).toMatchObject({
+      kind: 'and',
+      children: [
+        { kind: 'term', field: 'type', mode: 'default', value: 'function' },
+        {
+          kind: 'or',
+          children: [
+            { kind: 'term', field: 'name', mode: 'prefix', value: 'Handle' },
+            { kind: 'term', field: 'calls', mode: 'default', value: 'Save' }
+          ]
+        },
+        { kind: 'not', child: { kind: 'term', field: 'path', mode: 'glob', value: '*_test.go' } }
+      ]
+    });
+  });
+
+  test('should parse exact and fuzzy operators', () => {
+    expect(parseQuery('name:=Save')).toMatchObject({ field: 'name', mode: 'exact', value: 'Save' });
+    expect(parseQuery('name:~Handel')).toMatchObject({ field: 'name', mode: 'fuzzy', value: 'Handel' });
+    expect(parseQuery('name:"two words"')).toMatchObject({ field: 'name', mode: 'default', value: 'two words' });
+  });
+
+  test('should report helpful errors with positions', () => {
+    expect.assertions(6);
+    expect(() => parseQuery('nmae:Save')).toThrow('did you mean "name"');
+    expect(() => parseQuery('type:function (name:Save')).toThrow('Missing closing parenthesis');
+    expect(() => parseQuery('name:Save OR')).toThrow('Expected a filter after OR');
+    expect(() => parseQuery('path:~src')).toThrow('not supported for path');
+
+    try {
+      parseQuery('lang:go name:');
+    } catch (error) {
+      expect(error).toBeInstanceOf(QuerySyntaxError);
+      expect((error as QuerySyntaxError).position).toBe(13);
+    }
+  });
+
+  test('should reject unknown symbol types when compiling', () => {
+    expect(() => compileQuery(parseQuery('type:fucntion'), 'type:fucntion')).toThrow('did you mean "function"');
+  });
+
+  test('should translate path globs', () => {
+    const internal = new RegExp(globToRegExp('internal/**'));
+    expect(internal.test('internal/store/db.go')).toBe(true);
+    expect(internal.test('cmd/internal/db.go')).toBe(false);
+
+    const tests = new RegExp(globToRegExp('*_test.go'));
+    expect(tests.test('pkg/api/server_test.go')).toBe(true);
+    expect(tests.test('pkg/api/server.go')).toBe(false);
+  });
+});
\ No newline at end of file
@@ -0,0 +1,159 @@
+import { QuerySyntaxError } from './parser.js';
+import { fuzzyDistance, globToRegExp, levenshtein } from './match.js';
+import type { QueryNode, QueryTerm } from './parser.js';
+
+export interface CompiledQuery {
+  // Condition over `symbols s JOIN files f ON f.id = s.file_id`
+  where: string;
+  params: unknown[];
+}
+
+const SYMBOL_TYPES = [
+  'function', 'class', 'interface', 'type', 'variable', 'constant', 'export', 'import',
+  'method', 'property', 'namespace', 'module', 'struct', 'enum', 'trait'
+];
+
+const TYPE_ALIASES: Record<string, string> = {
+  fn: 'function',
+  func: 'function',
+  const: 'constant',
+  var: 'variable'
+};
+
+const LANGUAGE_ALIASES: Record<string, string> = {
+  ts: 'typescript',
+  tsx: 'typescript',
+  js: 'javascript',
+  jsx: 'javascript',
+  py: 'python',
+  golang: 'go',
+  rs: 'rust',
+  rb: 'ruby',
+  kt: 'kotlin',
+  cs: 'csharp',
+  'c#': 'csharp',
+  'c++': 'cpp'
+};
+
+/**
+ * Compile a parsed query into a SQL condition over symbols and their files.
+ * Relies on the `regexp` and `levenshtein` functions registered by PrimordynDB.
+ */
+export function compileQuery(node: QueryNode, input: string): CompiledQuery {
+  const params: unknown[] = [];
+  const where = compileNode(node, input, params);
+  return { where, params };
+}
+
+function compileNode(node: QueryNode, input: string, params: unknown[]): string {
+  switch (node.kind) {
+    case 'and':
+      return `(${node.children.map(child => compileNode(child, input, params)).join(' AND ')})`;
+    case 'or':
+      return `(${node.children.map(child => compileNode(child, input, params)).join(' OR ')})`;
+    case 'not':
+      return `NOT ${compileNode(node.child, input, params)}`;
+    default:
+      return compileTerm(node, input, params);
+  }
+}
+
+function compileTerm(term: QueryTerm, input: string, params: unknown[]): string {
+  switch (term.field) {
+    case 'name':
+      return matchText('s.name', term, params);
+
+    case 'type': {
+      const value = term.value.toLowerCase();
+      const type = TYPE_ALIASES[value] || value;
+      if ((term.mode === 'default' || term.mode === 'exact') && !SYMBOL_TYPES.includes(type)) {
+        throw new QuerySyntaxError(`Unknown symbol type "${term.value}"${suggest(type, SYMBOL_TYPES)}`, input, term.position);
+      }
+      return matchText('s.type', { ...term, value: type }, params);
+    }
+
+    case 'lang': {
+      const value = term.value.toLowerCase();
+      return matchText('f.language', { ...term, value: LANGUAGE_ALIASES[value] || value }, params);
+    }
+
+    case 'path': {
+      const value = term.value.replace(/^\.\//, '').replace(/\/+$/, '');
+      if (term.mode === 'exact') {
+        params.push(value);
+        return 'f.relative_path = ?';
+      }
+      if (term.mode === 'default') {
+        // A plain path matches that file or anything beneath that directory
+        params.push(globToRegExp(value), `${escapeLike(value)}/%`);
+        return "(f.relative_path REGEXP ? OR f.relative_path LIKE ? ESCAPE '\\')";
+      }
+      params.push(globToRegExp(term.mode === 'prefix' ? `${value}*` : value));
+      return 'f.relative_path REGEXP ?';
+    }
+
+    case 'calls': {
+      const callee = matchText(lastSegment('cg.callee_name'), term, params);
+      return `s.id IN (SELECT cg.caller_symbol_id FROM call_graph cg WHERE cg.caller_symbol_id IS NOT NULL AND ${callee})`;
+    }
+
+    case 'calledby': {
+      const caller = matchText('caller.name', term, params);
+      return `EXISTS (
+        SELECT 1 FROM call_graph cg
+        JOIN symbols caller ON caller.id = cg.caller_symbol_id
+        WHERE ${caller}
+          AND (cg.callee_symbol_id = s.id OR ${lastSegment('cg.callee_name')} = s.name)
+      )`;
+    }
+
+    default:
+      if (term.mode !== 'default') {
+        return matchText('s.name', term, params);
+      }
+      // Bare words match names loosely and anything in signatures or docs
+      params.push(`%${escapeLike(term.value)}%`, `"${term.value.replace(/"/g, '""')}"`);
+      return "(s.name LIKE ? ESCAPE '\\' OR s.id IN (SELECT rowid FROM symbols_fts WHERE symbols_fts MATCH ?))";
+  }
+}
+
+function matchText(column: string, term: QueryTerm, params: unknown[]): string {
+  switch (term.mode) {
+    case 'exact':
+      params.push(term.value);
+      return `${column} = ?`;
+    case 'prefix':
+      params.push(`${escapeLike(term.value)}%`);
+      return `${column} LIKE ? ESCAPE '\\'`;
+    case 'glob': {
+      const regex = term.value.toLowerCase()
+        .replace(/[.+^${}()|[\]\\]/g, '\\$&')
+        .replace(/\*/g, '.*')
+        .replace(/\?/g, '.');
+      params.push(`^${regex}$`);
+      return `lower(${column}) REGEXP ?`;
+    }
+    case 'fuzzy':
+      params.push(term.value.toLowerCase());
+      return `levenshtein(lower(${column}), ?) <= ${fuzzyDistance(term.value)}`;
+    default:
+      params.push(term.value);
+      return `${column} = ? COLLATE NOCASE`;
+  }
+}
+
+// `a.b.save` -> `save`: strip everything up to the last dot
+function lastSegment(column: string): string {
+  return `replace(${column}, rtrim(${column}, replace(${column}, '.', '')), '')`;
+}
+
+function escapeLike(value: string): string {
+  return value.replace(/[\\%_]/g, '\\$&');
+}
+
+function suggest(value: string, candidates: string[]): string {
+  const best = candidates
+    .map(candidate => ({ candidate, distance: levenshtein(value, candidate) }))
+    .sort((a, b) => a.distance - b.distance)[0];
+  return best && best.distance <= 2 ? ` (did you mean "${best.candidate}"?)` : `. Types: ${candidates.join(', ')}`;
+}
\ No newline at end of file
@@ -0,0 +1,61 @@
+export function levenshtein(a: string, b: string): number {
+  if (a === b) {
+    return 0;
+  }
+  if (!a.length || !b.length) {
+    return a.length || b.length;
+  }
+
+  let previous = Array.from({ length: b.length + 1 }, (_, i) => i);
+  for (let i = 1; i <= a.length; i++) {
+    const current = [i];
+    for (let j = 1; j <= b.length; j++) {
+      current[j] = Math.min(
+        previous[j] + 1,
+        current[j - 1] + 1,
+        previous[j - 1] + (a[i - 1] === b[j - 1] ? 0 : 1)
+      );
+    }
+    previous = current;
+  }
+  return previous[b.length];
+}
+
+/** Typos tolerated by fuzzy (~) matches: none for very short words, then 1-2 edits. */
+export function fuzzyDistance(value: string): number {
+  if (value.length <= 3) {
+    return 0;
+  }
+  return value.length <= 6 ? 1 : 2;
+}
+
+/**
+ * Translate a path glob into an anchored regular expression. `*` and `?` stay
+ * within one path segment, `**` spans directories, and patterns without a `/`
+ * match the file name at any depth (like .gitignore).
+ */
+export function globToRegExp(glob: string): string {
+  let pattern = glob.includes('/') ? glob.replace(/^\.?\//, '') : `**/${glob}`;
+  let regex = '';
+
+  while (pattern.length > 0) {
+    if (pattern.startsWith('**/')) {
+      regex += '(?:.*/)?';
+      pattern = pattern.slice(3);
+    } else if (pattern.startsWith('**')) {
+      regex += '.*';
+      pattern = pattern.slice(2);
+    } else if (pattern[0] === '*') {
+      regex += '[^/]*';
+      pattern = pattern.slice(1);
+    } else if (pattern[0] === '?') {
+      regex += '[^/]';
+      pattern = pattern.slice(1);
+    } else {
+      regex += pattern[0].replace(/[.+^${}()|[\]\\]/g, '\\$&');
+      pattern = pattern.slice(1);
+    }
+  }
+
+  return `^${regex}$`;
+}
\ No newline at end of file
@@ -0,0 +1,267 @@
+import { levenshtein } from './match.js';
+
+export type QueryField = 'type' | 'lang' | 'path' | 'name' | 'calls' | 'calledby';
+
+export type MatchMode = 'default' | 'exact' | 'prefix' | 'glob' | 'fuzzy';
+
+export type QueryNode =
+  | { kind: 'and'; children: QueryNode[] }
+  | { kind: 'or'; children: QueryNode[] }
+  | { kind: 'not'; child: QueryNode }
+  | QueryTerm;
+
+export interface QueryTerm {
+  kind: 'term';
+  // null for bare words, which search names, signatures and docs
+  field: QueryField | null;
+  mode: MatchMode;
+  value: string;
+  position: number;
+}
+
+export const QUERY_FIELDS: QueryField[] = ['type', 'lang', 'path', 'name', 'calls', 'calledby'];
+
+const FIELD_ALIASES: Record<string, QueryField> = {
+  language: 'lang',
+  file: 'path',
+  caller: 'calledby',
+  callee: 'calls'
+};
+
+export class QuerySyntaxError extends Error {
+  public readonly position: number;
+  public readonly input: string;
+
+  constructor(message: string, input: string, position: number) {
+    super(message);
+    this.name = 'QuerySyntaxError';
+    this.input = input;
+    this.position = position;
+  }
+
+  /** The query with a caret under the offending position. */
+  public excerpt(): string {
+    return `  ${this.input}\n  ${' '.repeat(this.position)}^`;
+  }
+}
+
+type Token =
+  | { type: 'lparen' | 'rparen' | 'or' | 'and' | 'not'; position: number }
+  | { type: 'word'; text: string; quoted: boolean; position: number };
+
+/**
+ * True when the text uses filter syntax (`field:value`) rather than being a
+ * plain search term. `::` (C++/Rust paths) and URLs are not fields.
+ */
+export function isStructuredQuery(input: string): boolean {
+  return /(?:^|[\s(])-?[A-Za-z]+:(?!:|\/\/)\S/.test(input);
+}
+
+export function parseQuery(input: string): QueryNode {
+  const tokens = tokenize(input);
+  if (tokens.length === 0) {
+    throw new QuerySyntaxError('Query is empty', input, 0);
+  }
+
+  let index = 0;
+  const peek = () => tokens[index];
+
+  const parseOr = (): QueryNode => {
+    const children = [parseAnd()];
+    while (peek()?.type === 'or') {
+      const orToken = tokens[index++];
+      if (!peek() || peek().type === 'rparen' || peek().type === 'or') {
+        throw new QuerySyntaxError('Expected a filter after OR', input, orToken.position);
+      }
+      children.push(parseAnd());
+    }
+    return children.length === 1 ? children[0] : { kind: 'or', children };
+  };
+
+  const parseAnd = (): QueryNode => {
+    const children = [parseUnary()];
+    while (peek() && peek().type !== 'or' && peek().type !== 'rparen') {
+      if (peek().type === 'and') {
+        const andToken = tokens[index++];
+        if (!peek() || peek().type === 'rparen' || peek().type === 'or') {
+          throw new QuerySyntaxError('Expected a filter after AND', input, andToken.position);
+        }
+      }
+      children.push(parseUnary());
+    }
+    return children.length === 1 ? children[0] : { kind: 'and', children };
+  };
+
+  const parseUnary = (): QueryNode => {
+    const token = peek();
+    if (token?.type === 'not') {
+      index++;
+      if (!peek() || peek().type === 'rparen' || peek().type === 'or') {
+        throw new QuerySyntaxError('Expected a filter after negation', input, token.position);
+      }
+      return { kind: 'not', child: parseUnary() };
+    }
+    return parsePrimary();
+  };
+
+  const parsePrimary = (): QueryNode => {
+    const token = tokens[index++];
+    if (!token) {
+      throw new QuerySyntaxError('Unexpected end of query', input, input.length);
+    }
+
+    if (token.type === 'lparen') {
+      if (peek()?.type === 'rparen') {
+        throw new QuerySyntaxError('Empty parentheses', input, token.position);
+      }
+      const node = parseOr();
+      if (peek()?.type !== 'rparen') {
+        throw new QuerySyntaxError('Missing closing parenthesis', input, token.position);
+      }
+      index++;
+      return node;
+    }
+
+    if (token.type === 'word') {
+      return parseTerm(token, input);
+    }
+
+    const label = token.type === 'rparen' ? "')'" : token.type.toUpperCase();
+    throw new QuerySyntaxError(`Unexpected ${label}`, input, token.position);
+  };
+
+  const node = parseOr();
+  const trailing = peek();
+  if (trailing) {
+    throw new QuerySyntaxError(
+      trailing.type === 'rparen' ? "Unmatched ')'" : 'Unexpected input',
+      input,
+      trailing.position
+    );
+  }
+  return node;
+}
+
+function parseTerm(token: Extract<Token, { type: 'word' }>, input: string): QueryTerm {
+  const separator = token.quoted ? -1 : token.text.indexOf(':');
+  if (separator <= 0) {
+    return { kind: 'term', field: null, ...parseValue(token.text, token.quoted), position: token.position };
+  }
+
+  const rawField = token.text.slice(0, separator).toLowerCase();
+  const field = (QUERY_FIELDS as string[]).includes(rawField) ? rawField as QueryField : FIELD_ALIASES[rawField];
+  if (!field) {
+    const suggestion = [...QUERY_FIELDS, ...Object.keys(FIELD_ALIASES)]
+      .map(name => ({ name, distance: levenshtein(rawField, name) }))
+      .sort((a, b) => a.distance - b.distance)[0];
+    const hint = suggestion && suggestion.distance <= 2 ? ` (did you mean "${suggestion.name}"?)` : '';
+    throw new QuerySyntaxError(
+      `Unknown field "${rawField}"${hint}. Fields: ${QUERY_FIELDS.join(', ')}`,
+      input,
+      token.position
+    );
+  }
+
+  let rawValue = token.text.slice(separator + 1);
+  let quoted = false;
+  if (rawValue.length >= 2 && rawValue.startsWith('"') && rawValue.endsWith('"')) {
+    rawValue = rawValue.slice(1, -1);
+    quoted = true;
+  }
+  if (!rawValue) {
+    throw new QuerySyntaxError(`Missing value after "${rawField}:"`, input, token.position + separator + 1);
+  }
+
+  const value = parseValue(rawValue, quoted);
+  if (!value.value) {
+    throw new QuerySyntaxError(`Missing value after "${rawField}:${rawValue}"`, input, token.position + separator + 1);
+  }
+  if (value.mode === 'fuzzy' && field === 'path') {
+    throw new QuerySyntaxError('Fuzzy matching (~) is not supported for path; use a glob like path:src/**/api*', input, token.position);
+  }
+
+  return { kind: 'term', field, ...value, position: token.position };
+}
+
+function parseValue(raw: string, quoted: boolean): { mode: MatchMode; value: string } {
+  if (quoted) {
+    return { mode: 'default', value: raw };
+  }
+  if (raw.startsWith('=')) {
+    return { mode: 'exact', value: raw.slice(1) };
+  }
+  if (raw.startsWith('~')) {
+    return { mode: 'fuzzy', value: raw.slice(1) };
+  }
+  if (/^[^*?]+\*$/.test(raw)) {
+    return { mode: 'prefix', value: raw.slice(0, -1) };
+  }
+  if (/[*?]/.test(raw)) {
+    return { mode: 'glob', value: raw };
+  }
+  return { mode: 'default', value: raw };
+}
+
+function tokenize(input: string): Token[] {
+  const tokens: Token[] = [];
+  let i = 0;
+
+  while (i < input.length) {
+    const char = input[i];
+
+    if (/\s/.test(char)) {
+      i++;
+      continue;
+    }
+    if (char === '(') {
+      tokens.push({ type: 'lparen', position: i++ });
+      continue;
+    }
+    if (char === ')') {
+      tokens.push({ type: 'rparen', position: i++ });
+      continue;
+    }
+    if (char === '-' && i + 1 < input.length && !/[\s)]/.test(input[i + 1])) {
+      tokens.push({ type: 'not', position: i++ });
+      continue;
+    }
+
+    const start = i;
+    if (char === '"') {
+      const end = input.indexOf('"', i + 1);
+      if (end === -1) {
+        throw new QuerySyntaxError('Unterminated quote', input, i);
+      }
+      tokens.push({ type: 'word', text: input.slice(i + 1, end), quoted: true, position: start });
+      i = end + 1;
+      continue;
+    }
+
+    // A word runs to whitespace or a closing paren; quoted values may contain spaces
+    let text = '';
+    while (i < input.length && !/[\s)]/.test(input[i])) {
+      if (input[i] === '"') {
+        const end = input.indexOf('"', i + 1);
+        if (end === -1) {
+          throw new QuerySyntaxError('Unterminated quote', input, i);
+        }
+        text += input.slice(i, end + 1);
+        i = end + 1;
+        continue;
+      }
+      text += input[i++];
+    }
+
+    if (text === 'OR' || text === '|') {
+      tokens.push({ type: 'or', position: start });
+    } else if (text === 'AND' || text === '&') {
+      tokens.push({ type: 'and', position: start });
+    } else if (text === 'NOT') {
+      tokens.push({ type: 'not', position: start });
+    } else {
+      tokens.push({ type: 'word', text, quoted: false, position: start });
+    }
+  }
+
+  return tokens;
+}
\ No newline at end of file
@@ -1,24 +1,77 @@
 import { PrimordynDB } from '../database/index.js';
 import { encodingForModel, Tiktoken } from 'js-tiktoken';
 import { GitAnalyzer } from '../git/analyzer.js';
+import { loadConfig } from '../config/index.js';
+import { SecretRedactor } from '../security/redactor.js';
+import { parseQuery } from '../query/parser.js';
+import { compileQuery } from '../query/compiler.js';
+import { SymbolSearch } from '../search/symbol-search.js';
+import { SemanticIndex } from '../search/semantic-index.js';
+import { ContextPacker, buildExcerpt, mergeWindows } from '../context/packer.js';
+import { SkeletonRenderer } from '../context/skeleton.js';
+import type { SkeletonSymbol } from '../context/skeleton.js';
+import type { PackCandidate, PackOption } from '../context/packer.js';
+import type { SemanticMatch } from '../search/semantic-index.js';
+import { EmbeddingIndex, createEmbeddingProvider } from '../embeddings/index.js';
+import { querySubwords, ftsTerm } from '../search/identifiers.js';
+import type { CacheConfig } from '../config/index.js';
 import type { 
   QueryOptions, QueryResult, FileResult, SymbolResult, 
   DependencyGraph, CallGraphNode, CallGraphEdge, ImpactAnalysis, GitHistory, 
-  FileQueryRow, SymbolQueryRow, RecentFileChanges, FileReferenceRow,
+  FileQueryRow, SymbolQueryRow, RecentFileChanges, FileReferences,
   MetadataResult, SymbolWithFileContent, CallGraphResult,
-  CallerResult, SymbolLookupResult, FilePathResult
+  CallerResult, SymbolLookupResult, FilePathResult, OutlineResult, UsageSnippet, DocTags
 } from '../types/index.js';
 
+export interface ContextRetrieverOptions {
+  cache?: Partial<CacheConfig>;
+}
+
+// What the packer chooses between in query results
+interface PackedResult {
+  file?: FileResult;
+  symbol?: SymbolResult;
+}
+
+interface MatchLocation {
+  fileId: number;
+  lineStart?: number;
+  lineEnd?: number;
+}
+
+// Earlier search results are worth more, but later ones still count
+function rankScore(index: number): number {
+  return 1 / (1 + index * 0.5);
+}
+
 export class ContextRetriever {
   private db: PrimordynDB;
   private tokenEncoder: Tiktoken;
   private gitAnalyzer: GitAnalyzer;
-
-  constructor(db: PrimordynDB) {
+  private cacheConfig: CacheConfig;
+  private redactor: SecretRedactor;
+  private symbolSearch: SymbolSearch;
+  private semanticIndex: SemanticIndex;
+  private skeletonRenderer: SkeletonRenderer;
+  private embeddingIndex: EmbeddingIndex | null;
+
+  constructor(db: PrimordynDB, options: ContextRetrieverOptions = {}) {
     this.db = db;
     // Use GPT-4 encoder as it's similar to Claude's tokenization
     this.tokenEncoder = encodingForModel('gpt-4');
     this.gitAnalyzer = new GitAnalyzer();
+    const config = loadConfig(db.getProjectRoot(), { cache: options.cache });
+    this.cacheConfig = config.cache;
+    this.redactor = new SecretRedactor(config.redaction);
+    this.symbolSearch = new SymbolSearch(db);
+    this.semanticIndex = new SemanticIndex(db);
+    this.skeletonRenderer = new SkeletonRenderer();
+    const provider = createEmbeddingProvider(config.embeddings);
+    this.embeddingIndex = provider ? new EmbeddingIndex(db, provider, config.embeddings, config.redaction) : null;
+  }
+
+  public getRedactor(): SecretRedactor {
+    return this.redactor;
   }
 
   public async query(searchTerm: string, options: QueryOptions = {}): Promise<QueryResult> {
@@ -44,9 +97,8 @@ export class ContextRetriever {
       const fileQuery = this.buildFileQuery(escapedTerm, options);
       files = database.prepare(fileQuery).all({ searchTerm: escapedTerm }) as FileQueryRow[];
 
-      // Search in symbols using FTS
-      const symbolQuery = this.buildSymbolQuery(escapedTerm, options);
-      symbols = database.prepare(symbolQuery).all({ searchTerm: escapedTerm }) as SymbolQueryRow[];
+      // Identifier-aware symbol search (subwords, substrings, typos)
+      symbols = this.symbolSearch.search(searchTerm, { fileTypes: options.fileTypes, limit: 15 });
     } else {
       // Fall back to LIKE queries for special characters
       const fileQuery = this.buildFileLikeQuery(searchTerm, options);
@@ -57,40 +109,37 @@ export class ContextRetriever {
       files = database.prepare(fileQuery).all(...params) as FileQueryRow[];
       
       const symbolQuery = this.buildSymbolLikeQuery(searchTerm, options);
-      const symbolParams = [`%${searchTerm}%`, `%${searchTerm}%`];
+      const symbolParams = [`%${searchTerm}%`, `%${searchTerm}%`, `%${searchTerm}%`];
       if (options.fileTypes?.length) {
         symbolParams.push(...options.fileTypes);
       }
       symbols = database.prepare(symbolQuery).all(...symbolParams) as SymbolQueryRow[];
     }
 
-    // Process results with token limit
-    for (const file of files) {
-      const fileResult = await this.processFileResult(file, options);
-      const fileTokens = this.estimateTokens(fileResult);
-
-      if (result.totalTokens + fileTokens > maxTokens) {
-        result.truncated = true;
-        break;
-      }
-
-      result.files.push(fileResult);
-      result.totalTokens += fileTokens;
+    // Pick the best mix for the budget, cutting large files down instead of dropping everything after them
+    const candidates: PackCandidate<PackedResult>[] = [];
+    for (const [index, file] of files.entries()) {
+      const representations = await this.fileRepresentations(file, options, { searchTerm });
+      candidates.push({
+        key: `file:${file.id}`,
+        label: file.relativePath || file.relative_path,
+        kind: 'file',
+        score: rankScore(index),
+        options: representations.map(option => ({ ...option, value: { file: option.value } }))
+      });
     }
-
-    for (const symbol of symbols) {
+    symbols.forEach((symbol, index) => {
       const symbolResult = this.processSymbolResult(symbol);
-      const symbolTokens = this.estimateTokens(symbolResult);
-
-      if (result.totalTokens + symbolTokens > maxTokens) {
-        result.truncated = true;
-        break;
-      }
-
-      result.symbols.push(symbolResult);
-      result.totalTokens += symbolTokens;
-    }
+      candidates.push({
+        key: `symbol:${symbol.id}`,
+        label: symbol.name,
+        kind: 'symbol',
+        score: rankScore(index),
+        options: [{ representation: 'full', tokens: this.estimateTokens(symbolResult), value: { symbol: symbolResult } }]
+      });
+    });
 
+    this.addPacked(result, candidates, maxTokens);
     return result;
   }
 
@@ -99,7 +148,7 @@ export class ContextRetriever {
     
     const file = database.prepare(`
       SELECT id, path, relative_path as relativePath, content, language, metadata
-      FROM files
+      FROM files_with_content
       WHERE path = ? OR relative_path = ?
     `).get(filePath, filePath) as FileQueryRow | undefined;
 
@@ -110,6 +159,60 @@ export class ContextRetriever {
     return this.processFileResult(file, options);
   }
 
+  /**
+   * Skeletons of a file, or of every file under a directory, in path order
+   * until the token budget runs out.
+   */
+  public getOutlines(target: string, options: QueryOptions = {}): OutlineResult {
+    const maxTokens = options.maxTokens || 8000;
+    const prefix = target.replace(/^\.\//, '').replace(/\/+$/, '');
+    const all = prefix === '' || prefix === '.';
+
+    const params: string[] = all ? [] : [prefix, target, `${prefix}/%`];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+    const files = this.db.getDatabase().prepare(`
+      SELECT id, relative_path as relativePath, content, language
+      FROM files_with_content
+      WHERE ${all ? '1 = 1' : '(relative_path = ? OR path = ? OR relative_path LIKE ?)'}
+      ${options.fileTypes?.length ? `AND language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY relative_path
+    `).all(...params) as { id: number; relativePath: string; content: string; language: string | null }[];
+
+    const result: OutlineResult = { target, files: [], totalTokens: 0, truncated: false, omitted: 0 };
+    for (const file of files) {
+      const skeleton = this.skeletonOf(file.id, file.content) ?? this.redactor.redact(file.content);
+      const tokens = this.estimateTokens(skeleton);
+      if (result.totalTokens + tokens > maxTokens) {
+        result.truncated = true;
+        result.omitted++;
+        continue;
+      }
+      result.files.push({
+        relativePath: file.relativePath,
+        language: file.language,
+        skeleton,
+        tokens,
+        fullTokens: this.estimateTokens(file.content)
+      });
+      result.totalTokens += tokens;
+    }
+    return result;
+  }
+
+  // Null when the file has no symbols to outline it by
+  private skeletonOf(fileId: number, content: string): string | null {
+    const symbols = this.db.getDatabase().prepare(`
+      SELECT name, type, line_start as lineStart, line_end as lineEnd FROM symbols WHERE file_id = ?
+    `).all(fileId) as SkeletonSymbol[];
+    if (symbols.length === 0) {
+      return null;
+    }
+    // Redacted before rendering: eliding a body can separate a secret's first and last lines
+    return this.skeletonRenderer.render(this.redactor.redact(content), symbols);
+  }
+
   public async getRelatedFiles(filePath: string, options: QueryOptions = {}): Promise<FileResult[]> {
     const maxTokens = options.maxTokens || 4000;
     const database = this.db.getDatabase();
@@ -132,7 +235,7 @@ export class ContextRetriever {
     for (const importPath of imports) {
       const relatedFiles = database.prepare(`
         SELECT id, path, relative_path as relativePath, content, language, metadata, hash, size, last_modified, indexed_at
-        FROM files
+        FROM files_with_content
         WHERE relative_path LIKE ?
         LIMIT 5
       `).all(`%${importPath}%`) as FileQueryRow[];
@@ -157,10 +260,39 @@ export class ContextRetriever {
     const database = this.db.getDatabase();
     const maxTokens = options.maxTokens || 4000;
     
-    // Search for files that contain references to this symbol
-    // This includes imports, function calls, class instantiations, etc.
+    // Every occurrence of the name outside its declarations, in the defining file too;
+    // comments, strings and longer identifiers don't match
+    const references = database.prepare(`
+      SELECT file_id as fileId, line_number as line
+      FROM occurrences
+      WHERE name = ? AND role != 'definition'
+    `).all(symbolName.split('.').pop()) as { fileId: number; line: number }[];
+
+    // Type references say how a type is named on each line: parameter, return, implements...
+    const typeReferences = database.prepare(`
+      SELECT file_id as fileId, line_number as line, kind
+      FROM symbol_references
+      WHERE name = ?
+    `).all(symbolName.split('.').pop()) as { fileId: number; line: number; kind: string }[];
+
+    const referenceLines = new Map<number, Set<number>>();
+    for (const reference of [...references, ...typeReferences]) {
+      const lines = referenceLines.get(reference.fileId) ?? new Set<number>();
+      lines.add(reference.line);
+      referenceLines.set(reference.fileId, lines);
+    }
+    const referenceKinds = new Map<string, Set<string>>();
+    for (const reference of typeReferences) {
+      const key = `${reference.fileId}:${reference.line}`;
+      referenceKinds.set(key, (referenceKinds.get(key) ?? new Set<string>()).add(reference.kind));
+    }
+    if (referenceLines.size === 0) {
+      return [];
+    }
+    
+    const fileIds = [...referenceLines.keys()];
     const query = `
-      SELECT DISTINCT
+      SELECT
         f.id,
         f.path,
         f.relative_path as relativePath,
@@ -169,15 +301,8 @@ export class ContextRetriever {
         f.metadata,
         f.size,
         f.last_modified as lastModified
-      FROM files f
-      WHERE 
-        -- Check in file content (excluding the definition file)
-        f.content LIKE '%' || ? || '%'
-        AND f.id NOT IN (
-          SELECT DISTINCT file_id 
-          FROM symbols 
-          WHERE name = ?
-        )
+      FROM files_with_content f
+      WHERE f.id IN (${fileIds.map(() => '?').join(',')})
       ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
       ORDER BY 
         CASE 
@@ -189,7 +314,7 @@ export class ContextRetriever {
       LIMIT 20
     `;
     
-    const params = [symbolName, symbolName];
+    const params: (string | number)[] = [...fileIds];
     if (options.fileTypes?.length) {
       params.push(...options.fileTypes);
     }
@@ -199,30 +324,13 @@ export class ContextRetriever {
     let totalTokens = 0;
     
     for (const file of files) {
-      // Check if the file actually uses the symbol (not just mentions in comments)
-      const lines = file.content.split('\n');
-      const usageLines: number[] = [];
-      
-      lines.forEach((line: string, index: number) => {
-        // Look for actual usage patterns
-        if (
-          line.includes(`new ${symbolName}`) ||  // Class instantiation
-          line.includes(`${symbolName}(`) ||      // Function call
-          line.includes(`${symbolName}.`) ||      // Static method/property
-          line.includes(`<${symbolName}`) ||      // JSX/Type usage
-          line.includes(`: ${symbolName}`) ||     // Type annotation
-          line.includes(`extends ${symbolName}`) || // Inheritance
-          line.includes(`implements ${symbolName}`) || // Interface implementation
-          line.includes(`from '.*${symbolName}`) || // Import
-          line.includes(`import.*${symbolName}`)    // Import
-        ) {
-          usageLines.push(index + 1);
-        }
-      });
+      // Windows are cut from the redacted text so a secret they only partly cover is still masked
+      const lines = this.redactor.redact(file.content).split('\n');
+      const usageLines = [...referenceLines.get(file.id)!].sort((a, b) => a - b);
       
       if (usageLines.length > 0) {
-        const fileResult = await this.processFileResult(file, options);
-        fileResult.metadata = { usageLines };
+        const kinds = (line: number) => [...referenceKinds.get(`${file.id}:${line}`) ?? []];
+        const fileResult = this.usageResult(file, lines, usageLines, kinds, options.contextLines ?? 3);
         
         const fileTokens = this.estimateTokens(fileResult);
         if (totalTokens + fileTokens > maxTokens) {
@@ -237,45 +345,50 @@ export class ContextRetriever {
     return results;
   }
   
+  // The file with only the windows around its usages, each named after the symbol it's in
+  private usageResult(
+    file: FileQueryRow, lines: string[], usageLines: number[], kinds: (line: number) => string[], contextLines: number
+  ): FileResult {
+    const symbols = this.db.getDatabase().prepare(`
+      SELECT name, line_start as lineStart, line_end as lineEnd FROM symbols WHERE file_id = ?
+    `).all(file.id) as { name: string; lineStart: number; lineEnd: number }[];
+    const metadata = file.metadata ? JSON.parse(file.metadata) : {};
+
+    const windows = mergeWindows(usageLines.map(line => ({ start: line, end: line })), contextLines, lines.length);
+    const snippets: UsageSnippet[] = windows.map(window => {
+      const inWindow = usageLines.filter(line => line >= window.start && line <= window.end);
+      const enclosing = symbols
+        .filter(symbol => symbol.lineStart <= inWindow[0] && symbol.lineEnd >= inWindow[0])
+        .sort((a, b) => (a.lineEnd - a.lineStart) - (b.lineEnd - b.lineStart))[0];
+      const referenceKinds = [...new Set(inWindow.flatMap(kinds))];
+      return {
+        lineStart: window.start,
+        lineEnd: window.end,
+        usageLines: inWindow,
+        enclosingSymbol: enclosing?.name,
+        referenceKinds: referenceKinds.length > 0 ? referenceKinds : undefined,
+        content: lines.slice(window.start - 1, window.end).join('\n')
+      };
+    });
+
+    return {
+      id: file.id,
+      path: file.path,
+      relativePath: file.relativePath || file.relative_path,
+      language: file.language,
+      tokens: metadata.tokens || 0,
+      snippets,
+      metadata: { usageLines }
+    };
+  }
+
   public async findSymbol(symbolName: string, options: QueryOptions = {}): Promise<SymbolResult[]> {
     const database = this.db.getDatabase();
-
-    // Check if we can use FTS5 or need to fall back to LIKE
-    const escapedName = this.escapeFTS5(symbolName);
-    const useFTS = escapedName.length > 0 && escapedName !== '';
-    
     let symbols: SymbolQueryRow[];
     
-    if (useFTS) {
-      // Use FTS for symbol search
-      const query = `
-        SELECT 
-          s.id,
-          s.name,
-          s.type,
-          s.line_start as lineStart,
-          s.line_end as lineEnd,
-          s.signature,
-          f.relative_path as filePath,
-          f.content as fileContent
-        FROM symbols_fts fts
-        JOIN symbols s ON fts.rowid = s.id
-        JOIN files f ON s.file_id = f.id
-        WHERE symbols_fts MATCH ?
-        ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
-        ORDER BY 
-          bm25(symbols_fts),
-          CASE WHEN s.name = ? THEN 0 ELSE 1 END,
-          LENGTH(s.name)
-        LIMIT 20
-      `;
-
-      const params = [escapedName, escapedName];
-      if (options.fileTypes?.length) {
-        params.push(...options.fileTypes);
-      }
-
-      symbols = database.prepare(query).all(...params) as SymbolQueryRow[];
+    if (querySubwords(symbolName).length > 0) {
+      // Matches subwords ("user id" finds getUserById), substrings and near-misses, exact names first
+      symbols = this.symbolSearch.search(symbolName, { fileTypes: options.fileTypes, limit: 20 });
     } else {
       // Fall back to LIKE query for special characters
       const query = `
@@ -286,10 +399,12 @@ export class ContextRetriever {
           s.line_start as lineStart,
           s.line_end as lineEnd,
           s.signature,
+          s.documentation,
+          s.metadata,
           f.relative_path as filePath,
           f.content as fileContent
         FROM symbols s
-        JOIN files f ON s.file_id = f.id
+        JOIN files_with_content f ON s.file_id = f.id
         WHERE s.name LIKE ?
         ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
         ORDER BY 
@@ -309,6 +424,191 @@ export class ContextRetriever {
     return symbols.map(symbol => this.processSymbolResult(symbol));
   }
 
+  /**
+   * Run a filter query such as `type:function lang:go calls:Save -path:*_test.go`.
+   * Throws QuerySyntaxError for malformed queries.
+   */
+  public async queryStructured(input: string, options: QueryOptions = {}): Promise<QueryResult> {
+    const maxTokens = options.maxTokens || 4000;
+    const database = this.db.getDatabase();
+    const compiled = compileQuery(parseQuery(input), input);
+
+    const params = [...compiled.params];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+
+    const symbols = database.prepare(`
+      SELECT 
+        s.id,
+        s.name,
+        s.type,
+        s.line_start as lineStart,
+        s.line_end as lineEnd,
+        s.signature,
+        s.documentation,
+        s.metadata,
+        s.file_id as fileId,
+        f.relative_path as filePath
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      WHERE ${compiled.where}
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY f.relative_path, s.line_start
+      LIMIT 50
+    `).all(...params) as (SymbolQueryRow & { fileId: number })[];
+
+    const result: QueryResult = {
+      files: [],
+      symbols: symbols.map(symbol => this.processSymbolResult(symbol)),
+      totalTokens: 0,
+      truncated: false
+    };
+    result.totalTokens = this.estimateTokens(result.symbols);
+
+    await this.addMatchingFiles(result, symbols, options, maxTokens);
+    return result;
+  }
+
+  /**
+   * Natural-language search over symbols ("where do we retry failed HTTP
+   * requests"), ranked by vector similarity blended with BM25.
+   */
+  public async querySemantic(input: string, options: QueryOptions = {}): Promise<QueryResult> {
+    const maxTokens = options.maxTokens || 4000;
+    const { symbols: matches, fileIds } = await this.searchSemantic(input, { fileTypes: options.fileTypes, limit: 20 });
+
+    const result: QueryResult = {
+      files: [],
+      symbols: matches.map(match => ({ ...this.processSymbolResult(match), score: Number(match.score.toFixed(3)) })),
+      totalTokens: 0,
+      truncated: false
+    };
+    result.totalTokens = this.estimateTokens(result.symbols);
+
+    await this.addMatchingFiles(result, [...matches, ...fileIds.map(fileId => ({ fileId }))], options, maxTokens);
+    return result;
+  }
+
+  /**
+   * Semantic matches without loading content. Uses the configured embedding
+   * provider once it has embedded the index, and the local TF-IDF vectors
+   * otherwise.
+   */
+  public async searchSemantic(input: string, options: { fileTypes?: string[]; limit?: number } = {}): Promise<{ symbols: SemanticMatch[]; fileIds: number[] }> {
+    if (this.embeddingIndex?.hasEmbeddings()) {
+      try {
+        return await this.embeddingIndex.search(input, options);
+      } catch {
+        // Provider unreachable; the local vectors still give a useful answer
+      }
+    }
+    return { symbols: this.semanticIndex.search(input, options), fileIds: [] };
+  }
+
+  // Files holding the matches, best first, packed into the remaining budget
+  private async addMatchingFiles(result: QueryResult, matches: MatchLocation[], options: QueryOptions, maxTokens: number): Promise<void> {
+    const selectFile = this.db.getDatabase().prepare(`
+      SELECT id, path, relative_path as relativePath, content, language, metadata
+      FROM files_with_content
+      WHERE id = ?
+    `);
+
+    const candidates: PackCandidate<PackedResult>[] = [];
+    const fileIds = [...new Set(matches.map(match => match.fileId))];
+    for (const [index, fileId] of fileIds.entries()) {
+      const file = selectFile.get(fileId) as FileQueryRow | undefined;
+      if (!file) {
+        continue;
+      }
+
+      // Excerpts center on the matched symbols in this file
+      const ranges = matches
+        .filter(match => match.fileId === fileId && match.lineStart)
+        .map(match => ({ start: match.lineStart!, end: match.lineEnd || match.lineStart! }));
+      const representations = await this.fileRepresentations(file, options, { ranges });
+      candidates.push({
+        key: `file:${file.id}`,
+        label: file.relativePath || file.relative_path,
+        kind: 'file',
+        score: rankScore(index),
+        options: representations.map(option => ({ ...option, value: { file: option.value } }))
+      });
+    }
+
+    this.addPacked(result, candidates, maxTokens);
+  }
+
+  private addPacked(result: QueryResult, candidates: PackCandidate<PackedResult>[], maxTokens: number): void {
+    const packed = new ContextPacker().pack(candidates, Math.max(0, maxTokens - result.totalTokens));
+    for (const item of packed.items) {
+      if (item.value.file) {
+        result.files.push(item.representation === 'full' ? item.value.file : { ...item.value.file, representation: item.representation });
+      }
+      if (item.value.symbol) {
+        result.symbols.push(item.value.symbol);
+      }
+    }
+    result.totalTokens += packed.totalTokens;
+    if (packed.degraded.length > 0) {
+      result.truncated = true;
+      result.degraded = [...(result.degraded || []), ...packed.degraded];
+    }
+  }
+
+  /**
+   * A file at each level of detail the packer may choose from: full text, an
+   * excerpt around the given lines (or lines mentioning the search term), its
+   * skeleton with bodies elided, and just the path.
+   */
+  private async fileRepresentations(
+    file: FileQueryRow,
+    options: QueryOptions,
+    focus: { searchTerm?: string; ranges?: { start: number; end: number }[] }
+  ): Promise<PackOption<FileResult>[]> {
+    const full = await this.processFileResult(file, options);
+    const representations: PackOption<FileResult>[] = [
+      { representation: 'full', tokens: this.estimateTokens(full), value: full }
+    ];
+
+    if (full.content !== undefined) {
+      const ranges = focus.ranges?.length ? focus.ranges : this.matchingLines(file.content, focus.searchTerm);
+      // Cut from the redacted text so a secret the excerpt only partly covers is still masked
+      const excerpt = buildExcerpt(full.content, ranges);
+      if (excerpt.length < full.content.length) {
+        const value = { ...full, content: excerpt };
+        representations.push({ representation: 'excerpt', tokens: this.estimateTokens(value), value });
+      }
+    }
+
+    const skeleton = this.skeletonOf(file.id, file.content);
+    if (skeleton) {
+      const value: FileResult = { ...full, content: skeleton, preview: undefined, symbols: undefined };
+      representations.push({ representation: 'signature', tokens: this.estimateTokens(value), value });
+    }
+
+    const path: FileResult = { id: full.id, path: full.path, relativePath: full.relativePath, language: full.language, tokens: full.tokens };
+    representations.push({ representation: 'path', tokens: this.estimateTokens(path), value: path });
+    // A fallback is only worth offering if it actually saves tokens
+    return representations.filter((option, index) => index === 0 || option.tokens < representations[0].tokens);
+  }
+
+  // Lines mentioning any word of the search term
+  private matchingLines(content: string, searchTerm?: string): { start: number; end: number }[] {
+    const words = (searchTerm || '').toLowerCase().split(/[^a-z0-9_]+/).filter(word => word.length >= 2);
+    if (words.length === 0) {
+      return [];
+    }
+    const ranges: { start: number; end: number }[] = [];
+    content.split('\n').forEach((line, index) => {
+      const lower = line.toLowerCase();
+      if (ranges.length < 20 && words.some(word => lower.includes(word))) {
+        ranges.push({ start: index + 1, end: index + 1 });
+      }
+    });
+    return ranges;
+  }
+
   public async searchFullText(query: string, options: QueryOptions = {}): Promise<QueryResult> {
     const maxTokens = options.maxTokens || 4000;
     const database = this.db.getDatabase();
@@ -341,7 +641,7 @@ export class ContextRetriever {
           f.last_modified as lastModified,
           snippet(files_fts, 1, '<mark>', '</mark>', '...', 32) as snippet
         FROM files_fts fts
-        JOIN files f ON fts.rowid = f.id
+        JOIN files_with_content f ON fts.rowid = f.id
         WHERE files_fts MATCH ?
         ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(t => `'${t}'`).join(',')})` : ''}
         ORDER BY bm25(files_fts)
@@ -359,12 +659,14 @@ export class ContextRetriever {
           s.line_start as lineStart,
           s.line_end as lineEnd,
           s.signature,
+          s.documentation,
+          s.metadata,
           f.relative_path as filePath,
           f.content as fileContent,
           snippet(symbols_fts, 0, '<mark>', '</mark>', '...', 16) as snippet
         FROM symbols_fts fts
         JOIN symbols s ON fts.rowid = s.id
-        JOIN files f ON s.file_id = f.id
+        JOIN files_with_content f ON s.file_id = f.id
         WHERE symbols_fts MATCH ?
         ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(t => `'${t}'`).join(',')})` : ''}
         ORDER BY bm25(symbols_fts)
@@ -384,7 +686,7 @@ export class ContextRetriever {
           f.metadata,
           f.size,
           f.last_modified as lastModified
-        FROM files f
+        FROM files_with_content f
         WHERE f.content LIKE ?
         ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(t => `'${t}'`).join(',')})` : ''}
         ORDER BY f.relative_path
@@ -402,17 +704,19 @@ export class ContextRetriever {
           s.line_start as lineStart,
           s.line_end as lineEnd,
           s.signature,
+          s.documentation,
+          s.metadata,
           f.relative_path as filePath,
           f.content as fileContent
         FROM symbols s
-        JOIN files f ON s.file_id = f.id
-        WHERE (s.name LIKE ? OR s.signature LIKE ?)
+        JOIN files_with_content f ON s.file_id = f.id
+        WHERE (s.name LIKE ? OR s.signature LIKE ? OR s.documentation LIKE ?)
         ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(t => `'${t}'`).join(',')})` : ''}
         ORDER BY LENGTH(s.name)
         LIMIT 20
       `;
 
-      symbols = database.prepare(symbolQuery).all(`%${query}%`, `%${query}%`) as SymbolQueryRow[];
+      symbols = database.prepare(symbolQuery).all(`%${query}%`, `%${query}%`, `%${query}%`) as SymbolQueryRow[];
     }
 
     // Process results
@@ -457,7 +761,7 @@ export class ContextRetriever {
         f.size,
         f.last_modified as lastModified
       FROM files_fts fts
-      JOIN files f ON fts.rowid = f.id
+      JOIN files_with_content f ON fts.rowid = f.id
       WHERE files_fts MATCH :searchTerm
     `;
 
@@ -483,31 +787,6 @@ export class ContextRetriever {
     return query;
   }
 
-  private buildSymbolQuery(_searchTerm: string, options: QueryOptions): string {
-    let query = `
-      SELECT 
-        s.id,
-        s.name,
-        s.type,
-        s.line_start as lineStart,
-        s.line_end as lineEnd,
-        s.signature,
-        f.relative_path as filePath,
-        f.content as fileContent
-      FROM symbols_fts fts
-      JOIN symbols s ON fts.rowid = s.id
-      JOIN files f ON s.file_id = f.id
-      WHERE symbols_fts MATCH :searchTerm
-    `;
-
-    if (options.fileTypes?.length) {
-      query += ` AND f.language IN (${options.fileTypes.map(t => `'${t}'`).join(',')})`;
-    }
-
-    query += ' ORDER BY bm25(symbols_fts) LIMIT 15';
-    return query;
-  }
-
   private async processFileResult(file: FileQueryRow, options: QueryOptions): Promise<FileResult> {
     const metadata = file.metadata ? JSON.parse(file.metadata) : {};
     const result: FileResult = {
@@ -518,12 +797,14 @@ export class ContextRetriever {
       tokens: metadata.tokens || 0
     };
 
+    // File text is what ends up in prompts, so secrets are masked before it leaves the retriever.
+    // Redacting before slicing catches secrets that span lines; newlines are kept so lines don't shift
+    const content = this.redactor.redact(file.content);
     if (options.includeContent) {
-      result.content = file.content;
+      result.content = content;
     } else {
       // Include a preview (first 10 lines)
-      const lines = file.content.split('\n').slice(0, 10);
-      result.preview = lines.join('\n');
+      result.preview = content.split('\n').slice(0, 10).join('\n');
     }
 
     if (options.includeImports) {
@@ -547,7 +828,7 @@ export class ContextRetriever {
         filePath: file.relativePath || file.relative_path,
         lineStart: s.line_start,
         lineEnd: s.line_end,
-        signature: s.signature === null ? undefined : s.signature
+        signature: s.signature === null ? undefined : this.redactor.redact(s.signature)
       }));
     }
 
@@ -562,9 +843,17 @@ export class ContextRetriever {
       filePath: symbol.filePath || '',
       lineStart: symbol.lineStart || symbol.line_start,
       lineEnd: symbol.lineEnd || symbol.line_end,
-      signature: symbol.signature || undefined
+      signature: symbol.signature ? this.redactor.redact(symbol.signature) : undefined
     };
 
+    if (symbol.documentation) {
+      result.documentation = this.redactor.redact(symbol.documentation);
+    }
+    const docTags = symbol.metadata ? (JSON.parse(symbol.metadata) as { docTags?: DocTags }).docTags : undefined;
+    if (docTags) {
+      result.docTags = docTags;
+    }
+
     // Note: fileContent would need to be added to SymbolQueryRow if needed
     // For now, we don't extract content in processSymbolResult
 
@@ -596,7 +885,7 @@ export class ContextRetriever {
     const database = this.db.getDatabase();
     
     // Check cache first
-    const cacheKey = `dep_graph_${symbolName}`;
+    const cacheKey = this.getCacheKey(`dep_graph_${symbolName}`);
     const cached = this.getFromCache(cacheKey);
     if (cached) {
       return cached as DependencyGraph;
@@ -747,7 +1036,7 @@ export class ContextRetriever {
     const database = this.db.getDatabase();
     
     // Check cache first
-    const cacheKey = `impact_${symbolName}`;
+    const cacheKey = this.getCacheKey(`impact_${symbolName}`);
     const cached = this.getFromCache(cacheKey);
     if (cached) {
       return cached as ImpactAnalysis;
@@ -760,6 +1049,7 @@ export class ContextRetriever {
         s.name,
         s.type,
         s.line_start as line,
+        s.line_end as lineEnd,
         s.file_id as fileId,
         f.relative_path as filePath
       FROM symbols s
@@ -796,23 +1086,28 @@ export class ContextRetriever {
       ORDER BY f.relative_path, cg.line_number
     `).all(symbolName, symbol.symbolId) as CallerResult[];
     
-    // Get text-based references (catches things AST might miss)
-    const textReferences = database.prepare(`
+    // Every other occurrence of the name: reads, writes, types and imports as well
+    // as calls; occurrences inside the symbol's own definition don't count
+    const occurrences = database.prepare(`
       SELECT 
-        f.id as fileId,
         f.relative_path as filePath,
-        f.content,
-        f.language
-      FROM files f
-      WHERE f.content LIKE '%' || ? || '%'
-        AND f.id != ?
-    `).all(symbolName, symbol.fileId) as Array<{
-      fileId: number;
+        o.line_number as line,
+        s.name as symbolName
+      FROM occurrences o
+      JOIN files f ON o.file_id = f.id
+      LEFT JOIN symbols s ON o.symbol_id = s.id
+      WHERE o.name = ? AND o.role != 'definition'
+        AND NOT (o.file_id = ? AND o.line_number BETWEEN ? AND ?)
+      ORDER BY f.relative_path, o.line_number
+    `).all(symbol.name.split('.').pop(), symbol.fileId, symbol.line, symbol.lineEnd ?? symbol.line) as Array<{
       filePath: string;
-      content: string;
-      language: string | null;
+      line: number;
+      symbolName: string | null;
     }>;
     
+    // How the type is named elsewhere; supertype clauses mean subtypes that must change with it
+    const referenceKinds = this.countReferenceKinds(symbol.name, symbol);
+    
     // Analyze each file for actual references
     const affectedFiles = new Map<string, {
       path: string;
@@ -837,32 +1132,21 @@ export class ContextRetriever {
       file.lines.push(ref.callLine);
     });
     
-    // Process text references to find additional occurrences
-    textReferences.forEach(file => {
-      const lines = file.content.split('\n');
-      const matches: number[] = [];
-      
-      lines.forEach((line: string, index: number) => {
-        if (line.includes(symbolName)) {
-          matches.push(index + 1);
-        }
-      });
-      
-      if (matches.length > 0) {
-        const key = file.filePath || '';
-        if (!affectedFiles.has(key)) {
-          affectedFiles.set(key, {
-            path: file.filePath || '',
-            referenceCount: matches.length,
-            isTest: this.isTestFile(file.filePath || ''),
-            lines: matches
-          });
-        } else {
-          // Merge with existing data
-          const existing = affectedFiles.get(key)!;
-          existing.lines = [...new Set([...existing.lines, ...matches])].sort((a, b) => a - b);
-          existing.referenceCount = existing.lines.length;
-        }
+    // Process occurrences, merging lines already seen as calls
+    occurrences.forEach(ref => {
+      if (!affectedFiles.has(ref.filePath)) {
+        affectedFiles.set(ref.filePath, {
+          path: ref.filePath,
+          referenceCount: 0,
+          isTest: this.isTestFile(ref.filePath),
+          lines: []
+        });
+      }
+      const file = affectedFiles.get(ref.filePath)!;
+      if (!file.lines.includes(ref.line)) {
+        file.lines.push(ref.line);
+        file.lines.sort((a, b) => a - b);
+        file.referenceCount++;
       }
     });
     
@@ -875,7 +1159,10 @@ export class ContextRetriever {
     const totalReferences = affectedFilesList.reduce((sum, f) => sum + f.referenceCount, 0);
     
     // Get unique symbols that reference this one
-    const affectedSymbols = new Set(directReferences.map(r => r.callerName).filter(Boolean));
+    const affectedSymbols = new Set([
+      ...directReferences.map(r => r.callerName),
+      ...occurrences.map(r => r.symbolName)
+    ].filter(Boolean));
     
     // Categorize files
     const impactByType = {
@@ -931,6 +1218,12 @@ export class ContextRetriever {
       riskScore += 1;
     }
     
+    const subtypes = (referenceKinds.implements ?? 0) + (referenceKinds.extends ?? 0) + (referenceKinds.embeds ?? 0);
+    if (subtypes > 0) {
+      riskFactors.push(`Implemented or extended by ${subtypes} type${subtypes === 1 ? '' : 's'}`);
+      riskScore += 2;
+    }
+    
     if (symbol.name.toLowerCase().includes('api') || symbol.name.toLowerCase().includes('public')) {
       riskFactors.push('Appears to be a public API');
       riskScore += 2;
@@ -969,6 +1262,10 @@ export class ContextRetriever {
       suggestions.push('Type changes will require recompilation of dependent code');
     }
     
+    if (subtypes > 0) {
+      suggestions.push('Update every implementation and subtype along with this type');
+    }
+    
     const impact: ImpactAnalysis = {
       symbol: symbol.name,
       type: symbol.type,
@@ -977,6 +1274,7 @@ export class ContextRetriever {
       directReferences: totalReferences,
       filesAffected: affectedFilesList.length,
       symbolsAffected: affectedSymbols.size,
+      referenceKinds,
       
       testsAffected: testFiles.length,
       testFiles: testFiles.map(f => f.path),
@@ -1010,43 +1308,53 @@ export class ContextRetriever {
     return testPatterns.some(pattern => lowerPath.includes(pattern));
   }
   
-  private findAllReferences(symbolName: string): FileReferenceRow[] {
+  private findAllReferences(symbolName: string): FileReferences[] {
     const database = this.db.getDatabase();
     
-    // Find all files that mention this symbol
-    return database.prepare(`
+    // Find every occurrence of the name, grouped by file
+    const rows = database.prepare(`
       SELECT 
-        f.id as fileId,
         f.relative_path as filePath,
-        f.content,
-        f.language
-      FROM files f
-      WHERE f.content LIKE '%' || ? || '%'
-    `).all(symbolName) as FileReferenceRow[];
+        o.line_number as line
+      FROM occurrences o
+      JOIN files f ON o.file_id = f.id
+      WHERE o.name = ?
+      ORDER BY f.relative_path, o.line_number
+    `).all(symbolName.split('.').pop()) as Array<{ filePath: string; line: number }>;
+
+    const files = new Map<string, number[]>();
+    for (const row of rows) {
+      const lines = files.get(row.filePath) ?? [];
+      if (!lines.includes(row.line)) {
+        lines.push(row.line);
+      }
+      files.set(row.filePath, lines);
+    }
+    return Array.from(files, ([filePath, lines]) => ({ filePath, lines }));
   }
   
-  private createImpactAnalysisFromReferences(symbolName: string, references: FileReferenceRow[]): ImpactAnalysis {
-    const affectedFiles: ImpactAnalysis['affectedFiles'] = [];
-    
-    references.forEach(file => {
-      const lines = file.content.split('\n');
-      const matches: number[] = [];
-      
-      lines.forEach((line: string, index: number) => {
-        if (line.includes(symbolName)) {
-          matches.push(index + 1);
-        }
-      });
-      
-      if (matches.length > 0) {
-        affectedFiles.push({
-          path: file.filePath || file.path || '',
-          referenceCount: matches.length,
-          isTest: this.isTestFile(file.filePath || file.path || ''),
-          lines: matches
-        });
-      }
-    });
+  // Type references to the name by kind, leaving out those inside its own definition
+  private countReferenceKinds(symbolName: string, definition?: SymbolLookupResult): Record<string, number> {
+    const rows = this.db.getDatabase().prepare(`
+      SELECT kind, COUNT(*) as count
+      FROM symbol_references
+      WHERE name = ?
+        AND NOT (file_id = ? AND line_number BETWEEN ? AND ?)
+      GROUP BY kind
+      ORDER BY count DESC, kind
+    `).all(
+      symbolName.split('.').pop(), definition?.fileId ?? -1, definition?.line ?? 0, definition?.lineEnd ?? definition?.line ?? 0
+    ) as Array<{ kind: string; count: number }>;
+    return Object.fromEntries(rows.map(row => [row.kind, row.count]));
+  }
+  
+  private createImpactAnalysisFromReferences(symbolName: string, references: FileReferences[]): ImpactAnalysis {
+    const affectedFiles: ImpactAnalysis['affectedFiles'] = references.map(file => ({
+      path: file.filePath,
+      referenceCount: file.lines.length,
+      isTest: this.isTestFile(file.filePath),
+      lines: file.lines
+    }));
     
     const testFiles = affectedFiles.filter(f => f.isTest);
     const totalReferences = affectedFiles.reduce((sum, f) => sum + f.referenceCount, 0);
@@ -1059,6 +1367,7 @@ export class ContextRetriever {
       directReferences: totalReferences,
       filesAffected: affectedFiles.length,
       symbolsAffected: 0,
+      referenceKinds: this.countReferenceKinds(symbolName),
       
       testsAffected: testFiles.length,
       testFiles: testFiles.map(f => f.path),
@@ -1071,7 +1380,7 @@ export class ContextRetriever {
       },
       
       riskLevel: totalReferences > 10 ? 'HIGH' : totalReferences > 5 ? 'MEDIUM' : 'LOW',
-      riskFactors: [`Symbol not indexed but found ${totalReferences} text references`],
+      riskFactors: [`Symbol not indexed but found ${totalReferences} references`],
       
       affectedFiles: affectedFiles.sort((a, b) => b.referenceCount - a.referenceCount),
       
@@ -1099,7 +1408,7 @@ export class ContextRetriever {
       // Try to find the file that contains this text
       const fileResult = database.prepare(`
         SELECT relative_path as filePath
-        FROM files
+        FROM files_with_content
         WHERE content LIKE '%' || ? || '%'
         LIMIT 1
       `).get(symbolName) as FilePathResult | undefined;
@@ -1166,7 +1475,16 @@ export class ContextRetriever {
     return summary;
   }
   
+  private getCacheKey(key: string): string {
+    // Keys carry the index generation so results never outlive the index they came from
+    return `${key}@${this.db.getIndexGeneration()}`;
+  }
+
   private getFromCache(key: string): unknown | null {
+    if (!this.cacheConfig.enabled) {
+      return null;
+    }
+
     const database = this.db.getDatabase();
     const cached = database.prepare(`
       SELECT result FROM context_cache 
@@ -1175,41 +1493,73 @@ export class ContextRetriever {
     
     if (cached) {
       try {
-        return JSON.parse(cached.result);
+        const parsed = JSON.parse(cached.result);
+        this.recordCacheAccess('cache_hits');
+        return parsed;
       } catch {
         // Invalid cache entry, ignore
       }
     }
+    this.recordCacheAccess('cache_misses');
     return null;
   }
   
   private saveToCache(key: string, data: unknown, expirationMinutes: number = 15): void {
+    if (!this.cacheConfig.enabled || this.cacheConfig.maxEntries === 0 || !this.canWriteCache()) {
+      return;
+    }
+
     const database = this.db.getDatabase();
     const result = JSON.stringify(data);
     
-    // Delete existing cache entry if exists
-    database.prepare('DELETE FROM context_cache WHERE query_hash = ?').run(key);
-    
-    // Insert new cache entry
-    database.prepare(`
-      INSERT INTO context_cache (query_hash, result, expires_at)
-      VALUES (?, ?, datetime('now', '+' || ? || ' minutes'))
-    `).run(key, result, expirationMinutes);
+    try {
+      database.transaction(() => {
+        // Delete existing cache entry if exists
+        database.prepare('DELETE FROM context_cache WHERE query_hash = ?').run(key);
+        
+        // Insert new cache entry
+        database.prepare(`
+          INSERT INTO context_cache (query_hash, result, expires_at)
+          VALUES (?, ?, datetime('now', '+' || ? || ' minutes'))
+        `).run(key, result, expirationMinutes);
+
+        // Keep the cache bounded, evicting the oldest entries first
+        database.prepare(`
+          DELETE FROM context_cache
+          WHERE id NOT IN (
+            SELECT id FROM context_cache ORDER BY id DESC LIMIT ?
+          )
+        `).run(this.cacheConfig.maxEntries);
+      })();
+    } catch {
+      // Caching is an optimization; a busy database shouldn't fail the query
+    }
+  }
+
+  private recordCacheAccess(counter: 'cache_hits' | 'cache_misses'): void {
+    if (!this.canWriteCache()) {
+      return;
+    }
+    try {
+      this.db.incrementMeta(counter);
+    } catch {
+      // Statistics are best effort
+    }
+  }
+
+  private canWriteCache(): boolean {
+    // While an index run holds the write lock any write would just wait out the busy timeout
+    return this.db.getActiveIndexLock() === null;
   }
   
   private escapeFTS5(term: string): string {
-    // FTS5 special characters that need escaping or removal
-    // If the term contains only special characters, return empty to trigger LIKE fallback
-    const cleaned = term.replace(/[^a-zA-Z0-9\s_-]/g, '');
+    // Punctuation like `.` and `::` separates words rather than being dropped, so
+    // `UserService.getUser` searches for both parts; each word is quoted so
+    // FTS5 never sees operators or special characters
+    const words = term.split(/[^a-zA-Z0-9_]+/).filter(Boolean);
     
-    // If nothing remains after cleaning, we can't use FTS5
-    if (cleaned.trim().length === 0) {
-      return '';
-    }
-    
-    // For FTS5, we need to wrap terms with special chars in quotes
-    // But since we're removing them, just return the cleaned version
-    return cleaned.trim();
+    // If the term contains only special characters, return empty to trigger LIKE fallback
+    return words.map(word => ftsTerm(word)).join(' ');
   }
   
   private buildFileLikeQuery(_searchTerm: string, options: QueryOptions): string {
@@ -1217,7 +1567,7 @@ export class ContextRetriever {
       SELECT 
         f.id, f.path, f.relative_path, f.content, 
         f.language, f.metadata, f.size
-      FROM files f
+      FROM files_with_content f
       WHERE f.content LIKE ?
     `;
     
@@ -1235,7 +1585,7 @@ export class ContextRetriever {
         s.*, f.relative_path as filePath, f.language
       FROM symbols s
       JOIN files f ON s.file_id = f.id
-      WHERE (s.name LIKE ? OR s.signature LIKE ?)
+      WHERE (s.name LIKE ? OR s.signature LIKE ? OR s.documentation LIKE ?)
     `;
     
     if (options.fileTypes && options.fileTypes.length > 0) {
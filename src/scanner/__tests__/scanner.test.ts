@@ -109,4 +109,16 @@ describe('FileScanner', () => {
     const paths = files.map(f => f.relativePath);
     expect(paths).not.toContain('large.txt');
   });
+
+  test('should scan only the given paths', async () => {
+    const scanner = new FileScanner({ rootPath: testDir });
+    const files = await scanner.scanPaths([
+      join(testDir, 'src', 'main.ts'),
+      'index.ts',
+      join(testDir, 'node_modules', 'package.json'),
+      join(testDir, '..', 'outside.ts')
+    ]);
+
+    expect(files.map(f => f.relativePath)).toEqual([join('src', 'main.ts'), 'index.ts']);
+  });
 });
\ No newline at end of file
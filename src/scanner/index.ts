@@ -1,5 +1,5 @@
 import { readdir, stat, readFile } from 'fs/promises';
-import { join, relative, extname, basename } from 'path';
+import { join, relative, extname, basename, isAbsolute } from 'path';
 import { createHash } from 'crypto';
 import ignore from 'ignore';
 import { existsSync, readFileSync } from 'fs';
@@ -221,6 +221,23 @@ export class FileScanner {
     return files;
   }
 
+  /** Scan just these files, with the same ignore rules and limits as a full scan. */
+  public async scanPaths(paths: string[]): Promise<FileInfo[]> {
+    const files: FileInfo[] = [];
+    for (const path of paths) {
+      const fullPath = isAbsolute(path) ? path : join(this.options.rootPath, path);
+      const relativePath = relative(this.options.rootPath, fullPath);
+      if (!relativePath || relativePath.startsWith('..') || isAbsolute(relativePath) || this.ignorer.ignores(relativePath)) {
+        continue;
+      }
+      const fileInfo = await this.processFile(fullPath, relativePath);
+      if (fileInfo) {
+        files.push(fileInfo);
+      }
+    }
+    return files;
+  }
+
   private async scanDirectory(dirPath: string, files: FileInfo[]): Promise<void> {
     const entries = await readdir(dirPath, { withFileTypes: true });
 
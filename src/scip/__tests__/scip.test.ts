@@ -0,0 +1,189 @@
+import { PrimordynDB } from '../../database/index.js';
+import { ScipImporter } from '../index.js';
+import { decodeScip, parseSymbol } from '../decode.js';
+import { readLsif } from '../lsif.js';
+import { createTestDb, removeTestDir, addFile, addSymbol, addCall, addOccurrence } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+// Protobuf encoding, enough to build SCIP fixtures
+const varint = (value: number): number[] => {
+  const bytes: number[] = [];
+  while (value > 127) {
+    bytes.push((value % 128) | 0x80);
+    value = Math.floor(value / 128);
+  }
+  return [...bytes, value];
+};
+const message = (field: number, data: number[]) => [...varint(field * 8 + 2), ...varint(data.length), ...data];
+const text = (field: number, value: string) => message(field, [...Buffer.from(value)]);
+const integer = (field: number, value: number) => [...varint(field * 8), ...varint(value)];
+const packed = (field: number, values: number[]) => message(field, values.flatMap(varint));
+
+const PACKAGE = 'scip-typescript npm app 1.0.0 src/`user.ts`/';
+
+const occurrence = (symbol: string, range: number[], roles: number, enclosing?: number[]) => message(2, [
+  ...packed(1, range),
+  ...text(2, symbol),
+  ...(roles ? integer(3, roles) : []),
+  ...(enclosing ? packed(7, enclosing) : [])
+]);
+
+const information = (symbol: string, documentation: string[], kind = 0) => message(3, [
+  ...text(1, symbol),
+  ...documentation.flatMap(part => text(3, part)),
+  ...(kind ? integer(5, kind) : [])
+]);
+
+describe('parseSymbol', () => {
+  test('reads descriptors, escaped names and method disambiguators', () => {
+    expect(parseSymbol(`${PACKAGE}UserStore#find(+1).`)).toEqual([
+      { name: 'src', suffix: 'namespace' },
+      { name: 'user.ts', suffix: 'namespace' },
+      { name: 'UserStore', suffix: 'type' },
+      { name: 'find', suffix: 'method' }
+    ]);
+    expect(parseSymbol('scip-go gomod example.com/my  pkg v1 Store#[T](key)')).toEqual([
+      { name: 'Store', suffix: 'type' },
+      { name: 'T', suffix: 'typeParameter' },
+      { name: 'key', suffix: 'parameter' }
+    ]);
+    expect(parseSymbol('local 4')).toBeNull();
+  });
+});
+
+describe('readLsif', () => {
+  test('reads definitions, references and hover docs through result sets', () => {
+    const dump = [
+      { id: 1, type: 'vertex', label: 'metaData', projectRoot: 'file:///repo', toolInfo: { name: 'lsif-node' } },
+      { id: 2, type: 'vertex', label: 'document', uri: 'file:///repo/src/a.ts', languageId: 'typescript' },
+      { id: 3, type: 'vertex', label: 'range', start: { line: 0, character: 16 }, end: { line: 0, character: 20 } },
+      { id: 4, type: 'vertex', label: 'resultSet' },
+      { id: 5, type: 'vertex', label: 'definitionResult' },
+      { id: 6, type: 'vertex', label: 'range', start: { line: 2, character: 2 }, end: { line: 2, character: 6 } },
+      { id: 7, type: 'vertex', label: 'hoverResult', result: { contents: [{ language: 'typescript', value: 'function load(): void' }, 'Loads.'] } },
+      { id: 8, type: 'edge', label: 'next', outV: 3, inV: 4 },
+      { id: 9, type: 'edge', label: 'next', outV: 6, inV: 4 },
+      { id: 10, type: 'edge', label: 'textDocument/definition', outV: 4, inV: 5 },
+      { id: 11, type: 'edge', label: 'item', outV: 5, inVs: [3], document: 2 },
+      { id: 12, type: 'edge', label: 'textDocument/hover', outV: 4, inV: 7 },
+      { id: 13, type: 'edge', label: 'contains', outV: 2, inVs: [3, 6] }
+    ].map(element => JSON.stringify(element)).join('\n');
+
+    const index = readLsif(dump);
+    expect(index.toolName).toBe('lsif-node');
+    expect(index.documents).toEqual([{
+      relativePath: 'src/a.ts',
+      language: 'typescript',
+      occurrences: [
+        { symbol: 'lsif 4', range: { startLine: 0, startColumn: 16, endLine: 0, endColumn: 20 }, roles: 1, enclosingRange: undefined },
+        { symbol: 'lsif 4', range: { startLine: 2, startColumn: 2, endLine: 2, endColumn: 6 }, roles: 0, enclosingRange: undefined }
+      ],
+      symbols: [{
+        symbol: 'lsif 4',
+        documentation: ['```typescript\nfunction load(): void\n```', 'Loads.'],
+        kind: 0,
+        displayName: undefined
+      }]
+    }]);
+  });
+});
+
+describe('ScipImporter', () => {
+  const testDir = join(process.cwd(), '.test-scip');
+  let db: PrimordynDB;
+  let fileId: number;
+  let storeId: number;
+
+  const source = [
+    'export class UserStore {',
+    '  find(id: string) {',
+    '    return load(id);',
+    '  }',
+    '}',
+    'export function load(id: string) {',
+    '  return id;',
+    '}'
+  ].join('\n');
+
+  const index = () => decodeScip(Uint8Array.from([
+    ...message(1, message(2, text(1, 'scip-typescript'))),
+    ...message(2, [
+      ...text(1, 'user.ts'),
+      ...text(4, 'TypeScript'),
+      ...occurrence(`${PACKAGE}UserStore#`, [0, 13, 22], 1),
+      ...occurrence(`${PACKAGE}UserStore#find().`, [1, 2, 6], 1),
+      ...occurrence('local 0', [1, 7, 9], 1),
+      ...occurrence(`${PACKAGE}load().`, [2, 11, 15], 8),
+      ...occurrence('local 0', [2, 16, 18], 8),
+      ...occurrence(`${PACKAGE}load().`, [5, 16, 20], 1, [5, 0, 7, 1]),
+      ...information(`${PACKAGE}UserStore#`, ['```ts\nclass UserStore\n```', 'Stores users.']),
+      ...information(`${PACKAGE}load().`, ['```ts\nfunction load(id: string): string\n```'], 17)
+    ]),
+    ...message(2, text(1, 'missing.ts'))
+  ]));
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+
+    fileId = addFile(db, 'src/user.ts', { content: source, hash: 'hash' });
+    storeId = addSymbol(db, fileId, 'UserStore', { type: 'class', lines: [1, 5], signature: 'export class UserStore' });
+    const findId = addSymbol(db, fileId, 'UserStore.find', { type: 'method', lines: [2, 4], signature: 'find(id: string)' });
+    addCall(db, { callerSymbolId: findId, callerFileId: fileId, calleeName: 'load', line: 3 });
+    addOccurrence(db, { fileId, symbolId: findId, name: 'load', role: 'call', line: 3, column: 11 });
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('updates and adds symbols from definitions', () => {
+    const result = new ScipImporter(db).import(index(), { prefix: 'src' });
+
+    expect(result).toMatchObject({
+      toolName: 'scip-typescript',
+      documentsImported: 1,
+      documentsSkipped: 1,
+      symbolsUpdated: 2,
+      symbolsAdded: 1
+    });
+    const symbols = db.getDatabase().prepare(`
+      SELECT name, type, line_start as lineStart, line_end as lineEnd, signature, documentation FROM symbols ORDER BY line_start
+    `).all();
+    expect(symbols).toEqual([
+      { name: 'UserStore', type: 'class', lineStart: 1, lineEnd: 5, signature: 'class UserStore', documentation: 'Stores users.' },
+      { name: 'UserStore.find', type: 'method', lineStart: 2, lineEnd: 4, signature: 'find(id: string)', documentation: null },
+      { name: 'load', type: 'function', lineStart: 6, lineEnd: 8, signature: 'function load(id: string): string', documentation: null }
+    ]);
+    const metadata = db.getDatabase().prepare('SELECT metadata FROM symbols WHERE id = ?').get(storeId) as { metadata: string };
+    expect(JSON.parse(metadata.metadata).scip).toBe(`${PACKAGE}UserStore#`);
+  });
+
+  test('replaces occurrences and resolves calls to their definitions', () => {
+    const result = new ScipImporter(db).import(index(), { prefix: 'src' });
+    const database = db.getDatabase();
+
+    expect(result.callsResolved).toBe(1);
+    const call = database.prepare(`
+      SELECT s.name, s.line_start as line FROM call_graph cg JOIN symbols s ON s.id = cg.callee_symbol_id WHERE cg.callee_name = 'load'
+    `).all();
+    expect(call).toEqual([{ name: 'load', line: 6 }]);
+
+    const occurrences = database.prepare(`
+      SELECT name, role, line_number as line, column_number as column FROM occurrences ORDER BY line_number, column_number
+    `).all();
+    expect(occurrences).toEqual([
+      { name: 'UserStore', role: 'definition', line: 1, column: 13 },
+      { name: 'find', role: 'definition', line: 2, column: 2 },
+      { name: 'id', role: 'definition', line: 2, column: 7 },
+      { name: 'load', role: 'call', line: 3, column: 11 },
+      { name: 'id', role: 'read', line: 3, column: 16 },
+      { name: 'load', role: 'definition', line: 6, column: 16 }
+    ]);
+
+    // Importing again is idempotent
+    new ScipImporter(db).import(index(), { prefix: 'src' });
+    expect((database.prepare('SELECT COUNT(*) as count FROM call_graph').get() as { count: number }).count).toBe(1);
+    expect((database.prepare('SELECT COUNT(*) as count FROM symbols').get() as { count: number }).count).toBe(3);
+  });
+});
\ No newline at end of file
@@ -0,0 +1,335 @@
+import type { ScipDocument, ScipIndex, ScipOccurrence, ScipRange, ScipRelationship, ScipSymbolInformation } from '../types/index.js';
+
+export class ScipError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'ScipError';
+  }
+}
+
+// SymbolRole bits, keyed by the occurrence role each one marks
+export const SYMBOL_ROLES = { definition: 1, import: 2, write: 4, read: 8 };
+
+export interface ScipDescriptor {
+  name: string;
+  suffix: 'namespace' | 'type' | 'term' | 'method' | 'meta' | 'macro' | 'parameter' | 'typeParameter';
+}
+
+const SUFFIXES: Record<string, ScipDescriptor['suffix']> = {
+  '/': 'namespace',
+  '#': 'type',
+  '.': 'term',
+  ':': 'meta',
+  '!': 'macro'
+};
+
+/**
+ * Protobuf wire format reader; just enough to walk SCIP messages without a
+ * generated schema. Unknown fields are skipped.
+ */
+class ProtoReader {
+  private buffer: Uint8Array;
+  private position: number;
+  private end: number;
+
+  constructor(buffer: Uint8Array, start = 0, end = buffer.length) {
+    this.buffer = buffer;
+    this.position = start;
+    this.end = end;
+  }
+
+  public done(): boolean {
+    return this.position >= this.end;
+  }
+
+  public tag(): { field: number; wireType: number } {
+    const key = this.varint();
+    return { field: Math.floor(key / 8), wireType: key % 8 };
+  }
+
+  public varint(): number {
+    let result = 0;
+    let shift = 0;
+    let byte: number;
+    do {
+      if (this.position >= this.end) {
+        throw new ScipError('Truncated SCIP index');
+      }
+      byte = this.buffer[this.position++];
+      result += (byte & 0x7f) * 2 ** shift;
+      shift += 7;
+    } while (byte & 0x80);
+    return result;
+  }
+
+  // A nested message or string, as a reader over its bytes
+  public message(): ProtoReader {
+    const length = this.varint();
+    if (this.position + length > this.end) {
+      throw new ScipError('Truncated SCIP index');
+    }
+    const reader = new ProtoReader(this.buffer, this.position, this.position + length);
+    this.position += length;
+    return reader;
+  }
+
+  public string(): string {
+    const reader = this.message();
+    return Buffer.from(reader.buffer.subarray(reader.position, reader.end)).toString('utf8');
+  }
+
+  // Repeated integers, packed or not
+  public integers(wireType: number, values: number[]): void {
+    if (wireType !== 2) {
+      values.push(this.varint());
+      return;
+    }
+    const reader = this.message();
+    while (!reader.done()) {
+      values.push(reader.varint());
+    }
+  }
+
+  public skip(wireType: number): void {
+    switch (wireType) {
+      case 0:
+        this.varint();
+        break;
+      case 1:
+        this.position += 8;
+        break;
+      case 2:
+        this.message();
+        break;
+      case 5:
+        this.position += 4;
+        break;
+      default:
+        throw new ScipError(`Not a SCIP index (unexpected wire type ${wireType})`);
+    }
+  }
+}
+
+/** Decode a binary SCIP index (scip.proto `Index`). */
+export function decodeScip(buffer: Uint8Array): ScipIndex {
+  const index: ScipIndex = { toolName: '', documents: [], externalSymbols: [] };
+  const reader = new ProtoReader(buffer);
+  while (!reader.done()) {
+    const { field, wireType } = reader.tag();
+    if (field === 1 && wireType === 2) {
+      index.toolName = readToolName(reader.message());
+    } else if (field === 2 && wireType === 2) {
+      index.documents.push(readDocument(reader.message()));
+    } else if (field === 3 && wireType === 2) {
+      index.externalSymbols.push(readSymbolInformation(reader.message()));
+    } else {
+      reader.skip(wireType);
+    }
+  }
+  return index;
+}
+
+function readToolName(metadata: ProtoReader): string {
+  let name = '';
+  while (!metadata.done()) {
+    const { field, wireType } = metadata.tag();
+    if (field === 2 && wireType === 2) {
+      const toolInfo = metadata.message();
+      while (!toolInfo.done()) {
+        const tag = toolInfo.tag();
+        if (tag.field === 1 && tag.wireType === 2) {
+          name = toolInfo.string();
+        } else {
+          toolInfo.skip(tag.wireType);
+        }
+      }
+    } else {
+      metadata.skip(wireType);
+    }
+  }
+  return name;
+}
+
+function readDocument(reader: ProtoReader): ScipDocument {
+  const document: ScipDocument = { relativePath: '', language: '', occurrences: [], symbols: [] };
+  while (!reader.done()) {
+    const { field, wireType } = reader.tag();
+    if (field === 1 && wireType === 2) {
+      document.relativePath = reader.string();
+    } else if (field === 2 && wireType === 2) {
+      const occurrence = readOccurrence(reader.message());
+      if (occurrence) {
+        document.occurrences.push(occurrence);
+      }
+    } else if (field === 3 && wireType === 2) {
+      document.symbols.push(readSymbolInformation(reader.message()));
+    } else if (field === 4 && wireType === 2) {
+      document.language = reader.string().toLowerCase();
+    } else {
+      reader.skip(wireType);
+    }
+  }
+  return document;
+}
+
+function readOccurrence(reader: ProtoReader): ScipOccurrence | null {
+  const range: number[] = [];
+  const enclosing: number[] = [];
+  let symbol = '';
+  let roles = 0;
+  while (!reader.done()) {
+    const { field, wireType } = reader.tag();
+    if (field === 1) {
+      reader.integers(wireType, range);
+    } else if (field === 2 && wireType === 2) {
+      symbol = reader.string();
+    } else if (field === 3 && wireType === 0) {
+      roles = reader.varint();
+    } else if (field === 7) {
+      reader.integers(wireType, enclosing);
+    } else {
+      reader.skip(wireType);
+    }
+  }
+  const parsed = toRange(range);
+  if (!symbol || !parsed) {
+    return null;
+  }
+  return { symbol, range: parsed, roles, enclosingRange: toRange(enclosing) ?? undefined };
+}
+
+function readSymbolInformation(reader: ProtoReader): ScipSymbolInformation {
+  const information: ScipSymbolInformation = { symbol: '', documentation: [], kind: 0 };
+  while (!reader.done()) {
+    const { field, wireType } = reader.tag();
+    if (field === 1 && wireType === 2) {
+      information.symbol = reader.string();
+    } else if (field === 3 && wireType === 2) {
+      information.documentation.push(reader.string());
+    } else if (field === 4 && wireType === 2) {
+      const relationship = readRelationship(reader.message());
+      if (relationship) {
+        information.relationships = [...(information.relationships ?? []), relationship];
+      }
+    } else if (field === 5 && wireType === 0) {
+      information.kind = reader.varint();
+    } else if (field === 6 && wireType === 2) {
+      information.displayName = reader.string();
+    } else if (field === 7 && wireType === 2) {
+      // signature_documentation is a Document; its text (field 5) is the signature
+      const signature = reader.message();
+      while (!signature.done()) {
+        const tag = signature.tag();
+        if (tag.field === 5 && tag.wireType === 2) {
+          information.signature = signature.string();
+        } else {
+          signature.skip(tag.wireType);
+        }
+      }
+    } else {
+      reader.skip(wireType);
+    }
+  }
+  return information;
+}
+
+function readRelationship(reader: ProtoReader): ScipRelationship | null {
+  const relationship: ScipRelationship = { symbol: '', isImplementation: false };
+  while (!reader.done()) {
+    const { field, wireType } = reader.tag();
+    if (field === 1 && wireType === 2) {
+      relationship.symbol = reader.string();
+    } else if (field === 3 && wireType === 0) {
+      relationship.isImplementation = reader.varint() !== 0;
+    } else {
+      reader.skip(wireType);
+    }
+  }
+  return relationship.symbol ? relationship : null;
+}
+
+// [line, startColumn, endColumn] or [startLine, startColumn, endLine, endColumn]
+function toRange(values: number[]): ScipRange | null {
+  if (values.length === 3) {
+    return { startLine: values[0], startColumn: values[1], endLine: values[0], endColumn: values[2] };
+  }
+  if (values.length === 4) {
+    return { startLine: values[0], startColumn: values[1], endLine: values[2], endColumn: values[3] };
+  }
+  return null;
+}
+
+/**
+ * The descriptors of a global SCIP symbol (`scheme manager package version
+ * descriptors`), outermost first; null for local symbols and anything that
+ * doesn't parse.
+ */
+export function parseSymbol(symbol: string): ScipDescriptor[] | null {
+  if (symbol.startsWith('local ')) {
+    return null;
+  }
+
+  // Scheme, manager, package name and version; a doubled space is a literal space
+  let index = 0;
+  for (let field = 0; field < 4; field++) {
+    while (index < symbol.length && !(symbol[index] === ' ' && symbol[index + 1] !== ' ')) {
+      index += symbol[index] === ' ' ? 2 : 1;
+    }
+    if (index >= symbol.length) {
+      return null;
+    }
+    index++;
+  }
+
+  const descriptors: ScipDescriptor[] = [];
+  while (index < symbol.length) {
+    const char = symbol[index];
+    if (char === '[' || char === '(') {
+      const close = symbol.indexOf(char === '[' ? ']' : ')', index);
+      if (close < 0) {
+        return null;
+      }
+      descriptors.push({ name: symbol.slice(index + 1, close), suffix: char === '[' ? 'typeParameter' : 'parameter' });
+      index = close + 1;
+      continue;
+    }
+
+    let name = '';
+    if (char === '`') {
+      index++;
+      while (index < symbol.length) {
+        if (symbol[index] === '`') {
+          if (symbol[index + 1] !== '`') {
+            index++;
+            break;
+          }
+          index++;
+        }
+        name += symbol[index++];
+      }
+    } else {
+      name = symbol.slice(index).match(/^[\w+$-]+/)?.[0] ?? '';
+      index += name.length;
+    }
+    if (!name) {
+      return null;
+    }
+
+    const suffix = symbol[index];
+    if (suffix === '(') {
+      // Method disambiguator, e.g. `run().` or `run(+1).`
+      const close = symbol.indexOf(')', index);
+      if (close < 0 || symbol[close + 1] !== '.') {
+        return null;
+      }
+      descriptors.push({ name, suffix: 'method' });
+      index = close + 2;
+    } else if (SUFFIXES[suffix]) {
+      descriptors.push({ name, suffix: SUFFIXES[suffix] });
+      index++;
+    } else {
+      return null;
+    }
+  }
+  return descriptors.length > 0 ? descriptors : null;
+}
\ No newline at end of file
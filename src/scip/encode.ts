@@ -0,0 +1,101 @@
+import type { ScipIndex, ScipOccurrence, ScipRange, ScipSymbolInformation } from '../types/index.js';
+
+// Metadata.text_document_encoding UTF8 and Document.position_encoding
+// UTF16CodeUnitOffsetFromLineStart; columns are JavaScript string offsets
+const UTF8 = 1;
+const UTF16_OFFSETS = 2;
+
+/** Protobuf wire format writer; default values are omitted as in proto3. */
+class ProtoWriter {
+  private parts: Buffer[] = [];
+
+  public varint(field: number, value: number): this {
+    if (value) {
+      this.parts.push(varint(field * 8), varint(value));
+    }
+    return this;
+  }
+
+  public string(field: number, value: string | undefined): this {
+    return value ? this.bytes(field, Buffer.from(value, 'utf8')) : this;
+  }
+
+  public message(field: number, writer: ProtoWriter): this {
+    return this.bytes(field, writer.finish());
+  }
+
+  public packed(field: number, values: number[]): this {
+    return values.length > 0 ? this.bytes(field, Buffer.concat(values.map(varint))) : this;
+  }
+
+  public finish(): Buffer {
+    return Buffer.concat(this.parts);
+  }
+
+  private bytes(field: number, data: Buffer): this {
+    this.parts.push(varint(field * 8 + 2), varint(data.length), data);
+    return this;
+  }
+}
+
+function varint(value: number): Buffer {
+  const bytes: number[] = [];
+  while (value > 127) {
+    bytes.push((value % 128) | 0x80);
+    value = Math.floor(value / 128);
+  }
+  bytes.push(value);
+  return Buffer.from(bytes);
+}
+
+/** Encode an index as a binary SCIP `Index` message. */
+export function encodeScip(index: ScipIndex): Buffer {
+  const toolInfo = new ProtoWriter().string(1, index.toolName).string(2, index.toolVersion);
+  const metadata = new ProtoWriter()
+    .message(2, toolInfo)
+    .string(3, index.projectRoot)
+    .varint(4, UTF8);
+
+  const writer = new ProtoWriter().message(1, metadata);
+  for (const document of index.documents) {
+    const message = new ProtoWriter().string(1, document.relativePath);
+    for (const occurrence of document.occurrences) {
+      message.message(2, encodeOccurrence(occurrence));
+    }
+    for (const symbol of document.symbols) {
+      message.message(3, encodeSymbolInformation(symbol));
+    }
+    message.string(4, document.language).varint(6, UTF16_OFFSETS);
+    writer.message(2, message);
+  }
+  for (const symbol of index.externalSymbols) {
+    writer.message(3, encodeSymbolInformation(symbol));
+  }
+  return writer.finish();
+}
+
+function encodeOccurrence(occurrence: ScipOccurrence): ProtoWriter {
+  return new ProtoWriter()
+    .packed(1, rangeValues(occurrence.range))
+    .string(2, occurrence.symbol)
+    .varint(3, occurrence.roles)
+    .packed(7, occurrence.enclosingRange ? rangeValues(occurrence.enclosingRange) : []);
+}
+
+function encodeSymbolInformation(information: ScipSymbolInformation): ProtoWriter {
+  const writer = new ProtoWriter().string(1, information.symbol);
+  for (const part of information.documentation) {
+    writer.string(3, part);
+  }
+  for (const relationship of information.relationships ?? []) {
+    writer.message(4, new ProtoWriter().string(1, relationship.symbol).varint(3, relationship.isImplementation ? 1 : 0));
+  }
+  return writer.varint(5, information.kind).string(6, information.displayName);
+}
+
+// Three values when the range is on one line
+function rangeValues(range: ScipRange): number[] {
+  return range.startLine === range.endLine
+    ? [range.startLine, range.startColumn, range.endColumn]
+    : [range.startLine, range.startColumn, range.endLine, range.endColumn];
+}
\ No newline at end of file
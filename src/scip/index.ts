@@ -0,0 +1,307 @@
+import { existsSync, readFileSync } from 'fs';
+import { posix } from 'path';
+import { PrimordynDB } from '../database/index.js';
+import { SemanticIndex } from '../search/semantic-index.js';
+import { ImplementationIndex } from '../hierarchy/implementations.js';
+import { decodeScip, parseSymbol, ScipError, SYMBOL_ROLES, type ScipDescriptor } from './decode.js';
+import { readLsif } from './lsif.js';
+import type { OccurrenceRole, ScipDocument, ScipImportResult, ScipIndex, ScipOccurrence, ScipSymbolInformation, Symbol } from '../types/index.js';
+
+export { ScipError } from './decode.js';
+
+// SCIP SymbolInformation.Kind to symbol types; anything else is typed by its descriptor
+const SYMBOL_KINDS: Record<number, Symbol['type']> = {
+  7: 'class', 8: 'constant', 9: 'method', 11: 'enum', 12: 'constant', 15: 'property', 17: 'function',
+  21: 'interface', 26: 'method', 29: 'module', 30: 'namespace', 35: 'module', 41: 'property', 42: 'interface',
+  49: 'struct', 53: 'trait', 54: 'type', 55: 'type', 61: 'variable', 66: 'method', 67: 'method', 68: 'method',
+  69: 'method', 70: 'method', 79: 'property', 80: 'method', 81: 'property', 82: 'variable'
+};
+
+interface StoredSymbol {
+  id: number;
+  name: string;
+  lineStart: number;
+  lineEnd: number;
+  signature: string | null;
+  documentation: string | null;
+}
+
+interface ImportedDocument {
+  document: ScipDocument;
+  fileId: number;
+  lines: string[];
+}
+
+/**
+ * Merges SCIP and LSIF indexes from compiler-backed indexers (scip-typescript,
+ * scip-go, lsif-node, ...) into the local index. Per file, definitions update
+ * or add symbols, occurrences replace the heuristic ones, and calls resolve to
+ * the exact symbol they target. Files the indexers didn't cover keep their
+ * extractor output, and re-indexing a changed file replaces its imported data.
+ */
+export class ScipImporter {
+  private db: PrimordynDB;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+  }
+
+  /** Read a binary SCIP index, or an LSIF dump if the file is JSON. */
+  public static read(indexPath: string): ScipIndex {
+    if (!existsSync(indexPath)) {
+      throw new ScipError(`Index not found: ${indexPath}`);
+    }
+    const data = readFileSync(indexPath);
+    // LSIF is JSON objects; a SCIP index starts with protobuf tags that never look like `{"`
+    const isJson = /^\s*(?:\[\s*)?\{\s*"/.test(data.subarray(0, 64).toString('latin1'));
+    return isJson ? readLsif(data.toString('utf8')) : decodeScip(data);
+  }
+
+  /**
+   * Merge an index whose paths are relative to `prefix` in this project (the
+   * project root by default). Files must already be indexed.
+   */
+  public import(index: ScipIndex, options: { prefix?: string } = {}): ScipImportResult {
+    const database = this.db.getDatabase();
+    const result: ScipImportResult = {
+      toolName: index.toolName,
+      documentsImported: 0,
+      documentsSkipped: 0,
+      symbolsUpdated: 0,
+      symbolsAdded: 0,
+      occurrences: 0,
+      callsResolved: 0
+    };
+
+    const lock = this.db.createIndexLock();
+    lock.acquire('import-scip');
+    try {
+      database.transaction(() => {
+        const findFile = database.prepare('SELECT id, content FROM files_with_content WHERE relative_path = ?');
+        const documents: ImportedDocument[] = [];
+        for (const document of index.documents) {
+          const relativePath = posix.normalize(posix.join(options.prefix ?? '', document.relativePath));
+          const file = findFile.get(relativePath) as { id: number; content: string } | undefined;
+          if (file) {
+            documents.push({ document, fileId: file.id, lines: file.content.split('\n') });
+          } else {
+            result.documentsSkipped++;
+          }
+        }
+
+        const information = new Map<string, ScipSymbolInformation>();
+        for (const symbol of [...index.externalSymbols, ...index.documents.flatMap(document => document.symbols)]) {
+          information.set(symbol.symbol, symbol);
+        }
+
+        // Definitions first, so references in any file can resolve to them
+        const semanticIndex = new SemanticIndex(this.db);
+        const definitions = new Map<string, { id: number; fileId: number }>();
+        for (const imported of documents) {
+          this.mergeSymbols(imported, information, definitions, semanticIndex, result);
+        }
+        for (const imported of documents) {
+          this.mergeOccurrences(imported, definitions, result);
+          result.documentsImported++;
+        }
+
+        if (documents.length > 0) {
+          semanticIndex.refresh();
+          new ImplementationIndex(this.db).refresh();
+        }
+        this.db.bumpIndexGeneration();
+      })();
+    } finally {
+      lock.release();
+    }
+    return result;
+  }
+
+  // Update the extractor's symbol at each definition, or add one it missed
+  private mergeSymbols(
+    { document, fileId, lines }: ImportedDocument,
+    information: Map<string, ScipSymbolInformation>,
+    definitions: Map<string, { id: number; fileId: number }>,
+    semanticIndex: SemanticIndex,
+    result: ScipImportResult
+  ): void {
+    const database = this.db.getDatabase();
+    const symbols = this.fileSymbols(fileId);
+    const update = database.prepare(`
+      UPDATE symbols SET signature = ?, documentation = ?, metadata = json_set(COALESCE(metadata, '{}'), '$.scip', ?) WHERE id = ?
+    `);
+    const insert = database.prepare(`
+      INSERT INTO symbols (file_id, name, type, line_start, line_end, signature, documentation, metadata)
+      VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `);
+    const clearPostings = database.prepare('DELETE FROM semantic_postings WHERE symbol_id = ?');
+
+    for (const occurrence of document.occurrences) {
+      if (!(occurrence.roles & SYMBOL_ROLES.definition) || occurrence.symbol.startsWith('local ')) {
+        continue;
+      }
+      const descriptors = parseSymbol(occurrence.symbol);
+      const descriptor = descriptors?.[descriptors.length - 1];
+      if (descriptor && ['parameter', 'typeParameter', 'meta'].includes(descriptor.suffix)) {
+        continue;
+      }
+      const name = descriptor?.name ?? sourceText(lines, occurrence);
+      if (!name) {
+        continue;
+      }
+
+      const line = occurrence.range.startLine + 1;
+      const info = information.get(occurrence.symbol);
+      const { signature, documentation } = describe(info);
+      const existing = symbols.find(symbol =>
+        symbol.name.split('.').pop() === name && symbol.lineStart <= line && symbol.lineEnd >= line
+      );
+
+      let symbolId: number;
+      if (existing) {
+        update.run(signature ?? existing.signature, documentation ?? existing.documentation, occurrence.symbol, existing.id);
+        symbolId = existing.id;
+        result.symbolsUpdated++;
+      } else {
+        const type = SYMBOL_KINDS[info?.kind ?? 0] ?? descriptorType(descriptors);
+        if (!type) {
+          continue;
+        }
+        const lineEnd = occurrence.enclosingRange ? occurrence.enclosingRange.endLine + 1 : line;
+        const declaration = signature ?? lines[line - 1]?.trim() ?? null;
+        const inserted = insert.run(fileId, name, type, line, lineEnd, declaration, documentation, JSON.stringify({ scip: occurrence.symbol }));
+        symbolId = inserted.lastInsertRowid as number;
+        symbols.push({ id: symbolId, name, lineStart: line, lineEnd, signature: declaration, documentation });
+        result.symbolsAdded++;
+      }
+
+      clearPostings.run(symbolId);
+      const stored = symbols.find(symbol => symbol.id === symbolId)!;
+      semanticIndex.addSymbol(symbolId, {
+        name,
+        signature: signature ?? stored.signature,
+        documentation: documentation ?? stored.documentation,
+        body: lines.slice(stored.lineStart - 1, stored.lineEnd).join('\n')
+      });
+      definitions.set(occurrence.symbol, { id: symbolId, fileId });
+    }
+  }
+
+  // Replace the file's heuristic occurrences and point its calls at their definitions
+  private mergeOccurrences(
+    { document, fileId, lines }: ImportedDocument,
+    definitions: Map<string, { id: number; fileId: number }>,
+    result: ScipImportResult
+  ): void {
+    const database = this.db.getDatabase();
+    // Calls and type positions are syntax SCIP doesn't record; keep what extraction saw
+    const heuristicRoles = new Map(
+      (database.prepare('SELECT line_number as line, column_number as column, role FROM occurrences WHERE file_id = ?')
+        .all(fileId) as { line: number; column: number; role: OccurrenceRole }[])
+        .map(row => [`${row.line}:${row.column}`, row.role])
+    );
+    const calls = database.prepare('SELECT id, callee_name as calleeName, line_number as line FROM call_graph WHERE caller_file_id = ?')
+      .all(fileId) as { id: number; calleeName: string; line: number }[];
+    const innermostFirst = this.fileSymbols(fileId).sort((a, b) => (a.lineEnd - a.lineStart) - (b.lineEnd - b.lineStart));
+
+    database.prepare('DELETE FROM occurrences WHERE file_id = ?').run(fileId);
+    const insertOccurrence = database.prepare(`
+      INSERT INTO occurrences (file_id, symbol_id, name, role, line_number, column_number) VALUES (?, ?, ?, ?, ?, ?)
+    `);
+    const resolveCall = database.prepare('UPDATE call_graph SET callee_symbol_id = ?, callee_file_id = ? WHERE id = ?');
+    const insertCall = database.prepare(`
+      INSERT INTO call_graph (caller_symbol_id, caller_file_id, callee_name, callee_symbol_id, callee_file_id, call_type, line_number, column_number)
+      VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `);
+
+    for (const occurrence of document.occurrences) {
+      const descriptors = parseSymbol(occurrence.symbol);
+      const name = descriptors?.[descriptors.length - 1].name ?? sourceText(lines, occurrence);
+      if (!name) {
+        continue;
+      }
+      const line = occurrence.range.startLine + 1;
+      const column = occurrence.range.startColumn;
+      const role = occurrenceRole(occurrence.roles, heuristicRoles.get(`${line}:${column}`));
+      const enclosing = innermostFirst.find(symbol => symbol.lineStart <= line && symbol.lineEnd >= line);
+      insertOccurrence.run(fileId, enclosing?.id ?? null, name, role, line, column);
+      result.occurrences++;
+
+      const target = definitions.get(occurrence.symbol);
+      if (role !== 'call' || !target) {
+        continue;
+      }
+      const call = calls.find(candidate => candidate.line === line && candidate.calleeName.split('.').pop() === name);
+      if (call) {
+        resolveCall.run(target.id, target.fileId, call.id);
+      } else {
+        const isMethod = descriptors?.[descriptors.length - 1].suffix === 'method' && descriptors[descriptors.length - 2]?.suffix === 'type';
+        insertCall.run(enclosing?.id ?? null, fileId, name, target.id, target.fileId, isMethod ? 'method' : 'function', line, column);
+      }
+      result.callsResolved++;
+    }
+  }
+
+  private fileSymbols(fileId: number): StoredSymbol[] {
+    return this.db.getDatabase().prepare(`
+      SELECT id, name, line_start as lineStart, line_end as lineEnd, signature, documentation FROM symbols WHERE file_id = ?
+    `).all(fileId) as StoredSymbol[];
+  }
+}
+
+function occurrenceRole(roles: number, heuristic: OccurrenceRole | undefined): OccurrenceRole {
+  if (roles & SYMBOL_ROLES.definition) {
+    return 'definition';
+  }
+  if (roles & SYMBOL_ROLES.import) {
+    return 'import';
+  }
+  if (roles & SYMBOL_ROLES.write) {
+    return 'write';
+  }
+  return heuristic === 'call' || heuristic === 'type' || heuristic === 'import' ? heuristic : 'read';
+}
+
+function descriptorType(descriptors: ScipDescriptor[] | null): Symbol['type'] | null {
+  const descriptor = descriptors?.[descriptors.length - 1];
+  const owner = descriptors?.[descriptors.length - 2];
+  switch (descriptor?.suffix) {
+    case 'type':
+      return 'class';
+    case 'method':
+      return owner?.suffix === 'type' ? 'method' : 'function';
+    case 'term':
+      return owner?.suffix === 'type' ? 'property' : 'variable';
+    case 'namespace':
+      return 'namespace';
+    case 'macro':
+      return 'function';
+    default:
+      return null;
+  }
+}
+
+// The identifier under a single-line range; local and LSIF symbols have no name of their own
+function sourceText(lines: string[], occurrence: ScipOccurrence): string | null {
+  const { startLine, startColumn, endLine, endColumn } = occurrence.range;
+  if (startLine !== endLine) {
+    return null;
+  }
+  const text = lines[startLine]?.slice(startColumn, endColumn).trim();
+  return text && /^[\w$]+$/.test(text) ? text : null;
+}
+
+// Signature and docs from SymbolInformation; indexers put the signature in a leading code block
+function describe(info: ScipSymbolInformation | undefined): { signature: string | null; documentation: string | null } {
+  let signature = info?.signature ?? null;
+  const prose: string[] = [];
+  for (const part of info?.documentation ?? []) {
+    const code = part.match(/^\s*```[\w-]*\n([\s\S]*?)\n```\s*$/);
+    if (code && !signature) {
+      signature = code[1].trim();
+    } else if (!code) {
+      prose.push(part.trim());
+    }
+  }
+  return { signature, documentation: prose.filter(Boolean).join('\n\n') || null };
+}
\ No newline at end of file
@@ -0,0 +1,182 @@
+import { fileURLToPath } from 'url';
+import { relative } from 'path';
+import { ScipError } from './decode.js';
+import type { ScipDocument, ScipIndex, ScipRange, ScipSymbolInformation } from '../types/index.js';
+
+interface LsifPosition {
+  line: number;
+  character: number;
+}
+
+interface LsifElement {
+  id: number | string;
+  type: 'vertex' | 'edge';
+  label: string;
+  // Vertices
+  uri?: string;
+  languageId?: string;
+  projectRoot?: string;
+  start?: LsifPosition;
+  end?: LsifPosition;
+  tag?: { type: string; text?: string; kind?: number; fullRange?: { start: LsifPosition; end: LsifPosition } };
+  result?: { contents?: unknown };
+  toolInfo?: { name?: string };
+  // Edges
+  outV?: number | string;
+  inV?: number | string;
+  inVs?: Array<number | string>;
+}
+
+// LSP SymbolKind to SCIP SymbolInformation.Kind
+const SYMBOL_KINDS: Record<number, number> = {
+  2: 29, 3: 30, 4: 35, 5: 7, 6: 26, 7: 41, 8: 15, 9: 9, 10: 11, 11: 21,
+  12: 17, 13: 61, 14: 8, 22: 12, 23: 49, 26: 58
+};
+
+/**
+ * Read an LSIF dump (JSON lines or a JSON array) into the SCIP shape. Each
+ * result set becomes a symbol named `lsif <id>`; ranges listed in its
+ * definition result are definitions and hover contents are its documentation.
+ */
+export function readLsif(text: string): ScipIndex {
+  const elements = parseElements(text);
+  const vertices = new Map<string, LsifElement>();
+  const next = new Map<string, string>();
+  const definitionResults = new Map<string, string>();
+  const hoverResults = new Map<string, string>();
+  const items = new Map<string, string[]>();
+  const contains = new Map<string, string[]>();
+  let projectRoot = '';
+  let toolName = '';
+
+  for (const element of elements) {
+    const id = String(element.id);
+    if (element.type === 'vertex') {
+      vertices.set(id, element);
+      if (element.label === 'metaData') {
+        projectRoot = element.projectRoot ?? '';
+        toolName = element.toolInfo?.name ?? '';
+      }
+      continue;
+    }
+    const outV = String(element.outV);
+    const inVs = (element.inVs ?? (element.inV !== undefined ? [element.inV] : [])).map(String);
+    switch (element.label) {
+      case 'next':
+        next.set(outV, inVs[0]);
+        break;
+      case 'textDocument/definition':
+        definitionResults.set(outV, inVs[0]);
+        break;
+      case 'textDocument/hover':
+        hoverResults.set(outV, inVs[0]);
+        break;
+      case 'item':
+        items.set(outV, [...(items.get(outV) ?? []), ...inVs]);
+        break;
+      case 'contains':
+        contains.set(outV, [...(contains.get(outV) ?? []), ...inVs]);
+        break;
+    }
+  }
+
+  // Ranges point at result sets, which may chain to shared ones
+  const resolve = (id: string): string => {
+    const seen = new Set<string>();
+    let current = id;
+    while (next.has(current) && !seen.has(current)) {
+      seen.add(current);
+      current = next.get(current)!;
+    }
+    return current;
+  };
+  // Hover and definition results can hang off any step of the chain
+  const lookup = (results: Map<string, string>, id: string): string | undefined => {
+    const seen = new Set<string>();
+    for (let current: string | undefined = id; current !== undefined && !seen.has(current); current = next.get(current)) {
+      seen.add(current);
+      if (results.has(current)) {
+        return results.get(current);
+      }
+    }
+    return undefined;
+  };
+
+  const rootPath = projectRoot ? toPath(projectRoot) : '';
+  const documents: ScipDocument[] = [];
+  for (const [id, vertex] of vertices) {
+    if (vertex.label !== 'document' || !vertex.uri) {
+      continue;
+    }
+    const path = toPath(vertex.uri);
+    const document: ScipDocument = {
+      relativePath: rootPath ? relative(rootPath, path).split('\\').join('/') : path,
+      language: (vertex.languageId ?? '').toLowerCase(),
+      occurrences: [],
+      symbols: []
+    };
+
+    for (const rangeId of contains.get(id) ?? []) {
+      const range = vertices.get(rangeId);
+      if (!range || range.label !== 'range' || !range.start || !range.end) {
+        continue;
+      }
+      const symbol = resolve(rangeId);
+      const definitionResult = lookup(definitionResults, rangeId);
+      const isDefinition = range.tag?.type === 'definition' ||
+        (definitionResult !== undefined && (items.get(definitionResult) ?? []).includes(rangeId));
+
+      document.occurrences.push({
+        symbol: `lsif ${symbol}`,
+        range: toRange(range.start, range.end),
+        roles: isDefinition ? 1 : 0,
+        enclosingRange: range.tag?.fullRange ? toRange(range.tag.fullRange.start, range.tag.fullRange.end) : undefined
+      });
+
+      if (isDefinition) {
+        const hover = lookup(hoverResults, rangeId);
+        const information: ScipSymbolInformation = {
+          symbol: `lsif ${symbol}`,
+          documentation: hover ? hoverDocumentation(vertices.get(hover)?.result?.contents) : [],
+          kind: SYMBOL_KINDS[range.tag?.kind ?? 0] ?? 0,
+          displayName: range.tag?.text
+        };
+        document.symbols.push(information);
+      }
+    }
+    documents.push(document);
+  }
+
+  return { toolName, documents, externalSymbols: [] };
+}
+
+function parseElements(text: string): LsifElement[] {
+  try {
+    if (text.trimStart().startsWith('[')) {
+      return JSON.parse(text) as LsifElement[];
+    }
+    return text.split('\n').filter(line => line.trim()).map(line => JSON.parse(line) as LsifElement);
+  } catch (error) {
+    throw new ScipError(`Not an LSIF dump: ${error instanceof Error ? error.message : error}`);
+  }
+}
+
+function toPath(uri: string): string {
+  return uri.startsWith('file:') ? fileURLToPath(uri) : uri;
+}
+
+function toRange(start: LsifPosition, end: LsifPosition): ScipRange {
+  return { startLine: start.line, startColumn: start.character, endLine: end.line, endColumn: end.character };
+}
+
+// Hover contents as SCIP-style documentation: code as fenced blocks, prose as markdown
+function hoverDocumentation(contents: unknown): string[] {
+  const parts = Array.isArray(contents) ? contents : contents ? [contents] : [];
+  return parts.map(part => {
+    if (typeof part === 'string') {
+      return part;
+    }
+    const { language, value } = part as { language?: string; value?: string };
+    return language ? `\`\`\`${language}\n${value ?? ''}\n\`\`\`` : value ?? '';
+  }).filter(Boolean);
+}
\ No newline at end of file
@@ -0,0 +1,60 @@
+import { PrimordynDB } from '../../database/index.js';
+import { SemanticIndex } from '../semantic-index.js';
+import { semanticTerms, stem } from '../semantic-terms.js';
+import { createTestDb, removeTestDir, addFile, addSymbol } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('semanticTerms', () => {
+  test('stems inflections to a shared root', () => {
+    expect(stem('retries')).toBe(stem('retrying'));
+    expect(stem('requests')).toBe('request');
+    expect(stem('validation')).toBe(stem('validate'));
+  });
+
+  test('splits identifiers and drops stop words and keywords', () => {
+    expect(semanticTerms('where do we retry failed HTTP requests')).toEqual(['retry', 'fail', 'http', 'request']);
+    expect(semanticTerms('function doWithRetry(req)')).toEqual(['retry', 'request']);
+  });
+});
+
+describe('SemanticIndex', () => {
+  const testDir = join(process.cwd(), '.test-semantic');
+  let db: PrimordynDB;
+  let index: SemanticIndex;
+
+  const indexSymbol = (fileId: number, name: string, body: string, documentation: string | null = null) => {
+    index.addSymbol(addSymbol(db, fileId, name, { documentation }), { name, documentation, body });
+  };
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+    index = new SemanticIndex(db);
+
+    const file = addFile(db, 'src/client.go', { hash: 'abc', language: 'go' });
+    indexSymbol(file, 'doWithBackoff', 'for attempt := 0; attempt < maxRetries; attempt++ { resp, err := c.http.Do(req) }', 'Retries failed requests with exponential backoff');
+    indexSymbol(file, 'parseConfig', 'yaml.Unmarshal(data, &cfg)', 'Reads the configuration file');
+    indexSymbol(file, 'renderTemplate', 'tmpl.Execute(w, data)');
+    index.refresh();
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  test('finds code by what it does rather than its name', () => {
+    const matches = index.search('where do we retry failed HTTP requests');
+    expect(matches[0].name).toBe('doWithBackoff');
+    expect(matches[0].score).toBeGreaterThan(0);
+  });
+
+  test('matches abbreviations against their spelled-out form', () => {
+    expect(index.search('load cfg')[0].name).toBe('parseConfig');
+  });
+
+  test('drops vectors with their symbols', () => {
+    db.getDatabase().prepare("DELETE FROM symbols WHERE name = 'doWithBackoff'").run();
+    index.refresh();
+    expect(index.search('retry failed requests').map(match => match.name)).not.toContain('doWithBackoff');
+  });
+});
\ No newline at end of file
@@ -0,0 +1,66 @@
+import { PrimordynDB } from '../../database/index.js';
+import { SymbolSearch } from '../symbol-search.js';
+import { splitIdentifier, subwordText } from '../identifiers.js';
+import { createTestDb, removeTestDir, addFile, addSymbol } from '../../__tests__/helpers/db-fixtures.js';
+import { join } from 'path';
+
+describe('splitIdentifier', () => {
+  test('splits camelCase, snake_case, kebab-case and digits', () => {
+    expect(splitIdentifier('getUserById')).toEqual(['get', 'user', 'by', 'id']);
+    expect(splitIdentifier('HTTPServer2')).toEqual(['http', 'server', '2']);
+    expect(splitIdentifier('parse_json_body')).toEqual(['parse', 'json', 'body']);
+    expect(splitIdentifier('x-request-id')).toEqual(['x', 'request', 'id']);
+  });
+
+  test('keeps the whole word alongside its subwords', () => {
+    expect(subwordText('getUser')).toBe('get user getuser');
+    expect(subwordText('main')).toBe('main');
+  });
+});
+
+describe('SymbolSearch', () => {
+  const testDir = join(process.cwd(), '.test-symbol-search');
+  let db: PrimordynDB;
+  let search: SymbolSearch;
+
+  beforeEach(() => {
+    db = createTestDb(testDir);
+    search = new SymbolSearch(db);
+
+    const file = addFile(db, 'src/users.ts', { hash: 'abc' });
+    for (const name of ['getUserById', 'get_user_by_email', 'UserService', 'User', 'fetchOrders']) {
+      addSymbol(db, file, name);
+    }
+  });
+
+  afterEach(() => {
+    db.close();
+    removeTestDir(testDir);
+  });
+
+  const names = (query: string) => search.search(query).map(row => row.name);
+
+  test('matches subwords across naming styles', () => {
+    expect(names('user id')).toContain('getUserById');
+    expect(names('userBy')).toContain('getUserById');
+    expect(names('userBy')).toContain('get_user_by_email');
+  });
+
+  test('ranks exact names first', () => {
+    expect(names('User')[0]).toBe('User');
+    expect(names('UserService.getUserById')[0]).toBe('getUserById');
+  });
+
+  test('finds substrings inside subwords', () => {
+    expect(names('serBy')).toContain('getUserById');
+  });
+
+  test('tolerates typos', () => {
+    expect(names('fetchOrdets')).toContain('fetchOrders');
+  });
+
+  test('keeps the index in sync when symbols are deleted', () => {
+    db.getDatabase().prepare("DELETE FROM symbols WHERE name = 'fetchOrders'").run();
+    expect(names('fetch orders')).toEqual([]);
+  });
+});
\ No newline at end of file
@@ -0,0 +1,46 @@
+/**
+ * Split an identifier into lowercase subwords:
+ * `getUserById` -> get, user, by, id; `HTTPServer2` -> http, server, 2;
+ * `snake_case` / `kebab-case` split on the separator.
+ */
+export function splitIdentifier(identifier: string): string[] {
+  return identifier
+    .replace(/([a-z])([A-Z])/g, '$1 $2')
+    .replace(/([A-Z]+)([A-Z][a-z])/g, '$1 $2')
+    .replace(/([A-Za-z])([0-9])/g, '$1 $2')
+    .replace(/([0-9])([A-Za-z])/g, '$1 $2')
+    .split(/[^A-Za-z0-9]+/)
+    .filter(Boolean)
+    .map(word => word.toLowerCase());
+}
+
+/**
+ * Text for the subword search index: every identifier-like word becomes its
+ * subwords, followed by the whole word so exact names still match as one token.
+ */
+export function subwordText(text: string | null | undefined): string {
+  if (!text) {
+    return '';
+  }
+
+  const terms: string[] = [];
+  for (const word of text.match(/[A-Za-z0-9_$-]+/g) || []) {
+    const parts = splitIdentifier(word);
+    terms.push(...parts);
+    const whole = word.replace(/[^A-Za-z0-9]/g, '').toLowerCase();
+    if (parts.length > 1 && whole) {
+      terms.push(whole);
+    }
+  }
+  return terms.join(' ');
+}
+
+/** Subwords of a free-form search such as `user id`, `UserService.getUser` or `pkg::Type`. */
+export function querySubwords(query: string): string[] {
+  return [...new Set((query.match(/[A-Za-z0-9_$-]+/g) || []).flatMap(word => splitIdentifier(word)))];
+}
+
+/** Quote a term for an FTS5 MATCH expression. */
+export function ftsTerm(term: string, prefix: boolean = false): string {
+  return `"${term.replace(/"/g, '""')}"${prefix ? '*' : ''}`;
+}
\ No newline at end of file
@@ -0,0 +1,229 @@
+import { PrimordynDB } from '../database/index.js';
+import { semanticTerms } from './semantic-terms.js';
+import { querySubwords, ftsTerm } from './identifiers.js';
+import type { SymbolQueryRow } from '../types/index.js';
+
+export interface SemanticSymbolText {
+  name: string;
+  signature?: string | null;
+  documentation?: string | null;
+  body?: string | null;
+}
+
+export interface SemanticSearchOptions {
+  fileTypes?: string[];
+  limit?: number;
+}
+
+export type SemanticMatch = SymbolQueryRow & {
+  fileId: number;
+  score: number;
+  similarity: number;
+};
+
+// How much each part of a symbol counts toward its vector
+const FIELD_WEIGHTS = { name: 3, signature: 2, documentation: 2, body: 1 };
+
+// Bodies beyond this many lines add noise more than meaning
+const MAX_BODY_LINES = 200;
+
+// Share of the final score taken by vector similarity; the rest is BM25
+const VECTOR_WEIGHT = 0.6;
+
+/**
+ * Local semantic search: each symbol is a sparse TF-IDF vector over stemmed
+ * identifier subwords from its name, signature, docs and body, stored as
+ * postings in SQLite. Queries are ranked by cosine similarity blended with
+ * BM25 from the subword index, so natural-language questions find code that
+ * shares vocabulary without needing a model or network access.
+ */
+export class SemanticIndex {
+  private db: PrimordynDB;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+  }
+
+  /** Store the term frequencies of a newly inserted symbol; call refresh() once the run is done. */
+  public addSymbol(symbolId: number, text: SemanticSymbolText): void {
+    const counts = new Map<string, number>();
+    const add = (value: string | null | undefined, weight: number) => {
+      for (const term of semanticTerms(value)) {
+        counts.set(term, (counts.get(term) || 0) + weight);
+      }
+    };
+
+    add(text.name, FIELD_WEIGHTS.name);
+    add(text.signature, FIELD_WEIGHTS.signature);
+    add(text.documentation, FIELD_WEIGHTS.documentation);
+    add(text.body?.split('\n').slice(0, MAX_BODY_LINES).join('\n'), FIELD_WEIGHTS.body);
+
+    const insert = this.db.getDatabase().prepare(
+      'INSERT OR REPLACE INTO semantic_postings (term, symbol_id, weight) VALUES (?, ?, ?)'
+    );
+    for (const [term, count] of counts) {
+      // Sublinear tf so a term repeated through a long body doesn't dominate
+      insert.run(term, symbolId, 1 + Math.log(count));
+    }
+  }
+
+  /**
+   * Recompute inverse document frequencies and vector norms from the current
+   * postings. IDF depends on the whole corpus, so this runs after every index
+   * update rather than per symbol.
+   */
+  public refresh(): void {
+    const database = this.db.getDatabase();
+    const symbolCount = (database.prepare(
+      'SELECT COUNT(DISTINCT symbol_id) as count FROM semantic_postings'
+    ).get() as { count: number }).count;
+
+    database.prepare('DELETE FROM semantic_terms').run();
+    database.prepare('DELETE FROM semantic_norms').run();
+
+    const insertTerm = database.prepare('INSERT INTO semantic_terms (term, idf) VALUES (?, ?)');
+    const frequencies = database.prepare(
+      'SELECT term, COUNT(*) as df FROM semantic_postings GROUP BY term'
+    ).all() as { term: string; df: number }[];
+    for (const { term, df } of frequencies) {
+      insertTerm.run(term, Math.log((symbolCount + 1) / (df + 1)) + 1);
+    }
+
+    const insertNorm = database.prepare('INSERT INTO semantic_norms (symbol_id, norm) VALUES (?, ?)');
+    const sums = database.prepare(`
+      SELECT p.symbol_id as symbolId, SUM(p.weight * p.weight * t.idf * t.idf) as sum
+      FROM semantic_postings p
+      JOIN semantic_terms t ON t.term = p.term
+      GROUP BY p.symbol_id
+    `).all() as { symbolId: number; sum: number }[];
+    for (const { symbolId, sum } of sums) {
+      insertNorm.run(symbolId, Math.sqrt(sum));
+    }
+  }
+
+  /** Hybrid search: cosine similarity over term vectors blended with BM25 on subwords. */
+  public search(query: string, options: SemanticSearchOptions = {}): SemanticMatch[] {
+    const limit = options.limit || 20;
+    const similarities = this.cosineSimilarities(query);
+    const bm25 = this.bm25Scores(query, options, limit * 5);
+
+    // Only the best vector matches are worth fetching; BM25 hits are already filtered
+    const vectorCandidates = [...similarities.entries()]
+      .sort((a, b) => b[1] - a[1])
+      .slice(0, limit * 5)
+      .map(([symbolId]) => symbolId);
+    const ids = [...new Set([...vectorCandidates, ...bm25.keys()])];
+    if (ids.length === 0) {
+      return [];
+    }
+
+    const maxBm25 = Math.max(0, ...bm25.values());
+    return this.loadSymbols(ids, options)
+      .map(row => {
+        const similarity = similarities.get(row.id) || 0;
+        const lexical = maxBm25 > 0 ? (bm25.get(row.id) || 0) / maxBm25 : 0;
+        return { ...row, similarity, score: VECTOR_WEIGHT * similarity + (1 - VECTOR_WEIGHT) * lexical };
+      })
+      .sort((a, b) => b.score - a.score)
+      .slice(0, limit);
+  }
+
+  private cosineSimilarities(query: string): Map<number, number> {
+    const database = this.db.getDatabase();
+    const counts = new Map<string, number>();
+    for (const term of semanticTerms(query)) {
+      counts.set(term, (counts.get(term) || 0) + 1);
+    }
+    if (counts.size === 0) {
+      return new Map();
+    }
+
+    const terms = [...counts.keys()];
+    const idfs = new Map(
+      (database.prepare(`SELECT term, idf FROM semantic_terms WHERE term IN (${terms.map(() => '?').join(',')})`)
+        .all(...terms) as { term: string; idf: number }[])
+        .map(row => [row.term, row.idf])
+    );
+
+    // Terms nobody uses can't contribute, but still count toward the query's norm
+    const queryWeights = new Map<string, number>();
+    let queryNorm = 0;
+    for (const [term, count] of counts) {
+      const weight = (1 + Math.log(count)) * (idfs.get(term) || 1);
+      queryWeights.set(term, weight);
+      queryNorm += weight * weight;
+    }
+    queryNorm = Math.sqrt(queryNorm);
+
+    const known = terms.filter(term => idfs.has(term));
+    if (known.length === 0) {
+      return new Map();
+    }
+
+    const rows = database.prepare(`
+      SELECT p.symbol_id as symbolId, p.term, p.weight * t.idf as weight, n.norm
+      FROM semantic_postings p
+      JOIN semantic_terms t ON t.term = p.term
+      JOIN semantic_norms n ON n.symbol_id = p.symbol_id
+      WHERE p.term IN (${known.map(() => '?').join(',')})
+    `).all(...known) as { symbolId: number; term: string; weight: number; norm: number }[];
+
+    const similarities = new Map<number, number>();
+    for (const row of rows) {
+      const contribution = (row.weight * (queryWeights.get(row.term) || 0)) / (row.norm * queryNorm || 1);
+      similarities.set(row.symbolId, (similarities.get(row.symbolId) || 0) + contribution);
+    }
+    return similarities;
+  }
+
+  private bm25Scores(query: string, options: SemanticSearchOptions, limit: number): Map<number, number> {
+    // Any word may match; BM25 rewards symbols that match more of them
+    const words = querySubwords(query).filter(word => semanticTerms(word).length > 0);
+    if (words.length === 0) {
+      return new Map();
+    }
+
+    const params: unknown[] = [words.map(word => ftsTerm(word, true)).join(' OR ')];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+
+    const rows = this.db.getDatabase().prepare(`
+      SELECT sw.rowid as symbolId, -bm25(symbol_subwords, 10.0, 2.0, 1.0) as score
+      FROM symbol_subwords sw
+      JOIN symbols s ON s.id = sw.rowid
+      JOIN files f ON f.id = s.file_id
+      WHERE symbol_subwords MATCH ?
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY score DESC
+      LIMIT ${limit}
+    `).all(...params) as { symbolId: number; score: number }[];
+
+    return new Map(rows.map(row => [row.symbolId, Math.max(0, row.score)]));
+  }
+
+  private loadSymbols(ids: number[], options: SemanticSearchOptions): (SymbolQueryRow & { fileId: number })[] {
+    const params: unknown[] = [...ids];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+
+    return this.db.getDatabase().prepare(`
+      SELECT
+        s.id,
+        s.name,
+        s.type,
+        s.line_start as lineStart,
+        s.line_end as lineEnd,
+        s.signature,
+        s.documentation,
+        s.metadata,
+        s.file_id as fileId,
+        f.relative_path as filePath
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      WHERE s.id IN (${ids.map(() => '?').join(',')})
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+    `).all(...params) as (SymbolQueryRow & { fileId: number })[];
+  }
+}
\ No newline at end of file
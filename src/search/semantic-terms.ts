@@ -0,0 +1,106 @@
+import { splitIdentifier } from './identifiers.js';
+
+// English filler and language keywords that say nothing about what code does
+const STOP_WORDS = new Set([
+  'a', 'an', 'and', 'are', 'as', 'at', 'be', 'by', 'can', 'do', 'does', 'for', 'from', 'how', 'i', 'if',
+  'in', 'into', 'is', 'it', 'its', 'of', 'on', 'or', 'our', 'so', 'that', 'the', 'their', 'then', 'there',
+  'these', 'this', 'to', 'was', 'we', 'were', 'what', 'when', 'where', 'which', 'who', 'why', 'will', 'with',
+  'any', 'all', 'not', 'no', 'use', 'used', 'using', 'code', 'place', 'handled', 'happen', 'happens',
+  'async', 'await', 'bool', 'boolean', 'break', 'case', 'catch', 'class', 'const', 'continue', 'def', 'else',
+  'elif', 'end', 'export', 'false', 'fn', 'func', 'function', 'impl', 'import', 'int', 'interface', 'let',
+  'mut', 'new', 'nil', 'none', 'null', 'package', 'private', 'protected', 'pub', 'public', 'return', 'self',
+  'static', 'str', 'string', 'struct', 'super', 'true', 'try', 'type', 'undefined', 'var', 'void', 'while'
+]);
+
+// Common abbreviations in identifiers, expanded so they meet their spelled-out form
+const ABBREVIATIONS: Record<string, string> = {
+  arg: 'argument',
+  args: 'arguments',
+  auth: 'authentication',
+  btn: 'button',
+  cfg: 'configuration',
+  config: 'configuration',
+  conn: 'connection',
+  ctx: 'context',
+  db: 'database',
+  dir: 'directory',
+  doc: 'document',
+  env: 'environment',
+  err: 'error',
+  idx: 'index',
+  init: 'initialize',
+  msg: 'message',
+  num: 'number',
+  param: 'parameter',
+  params: 'parameters',
+  pwd: 'password',
+  repo: 'repository',
+  req: 'request',
+  res: 'response',
+  resp: 'response',
+  tmp: 'temporary',
+  usr: 'user',
+  util: 'utility'
+};
+
+/**
+ * Reduce a lowercase word to a crude stem so inflections meet:
+ * retries/retried/retrying -> retry, requests -> request, parser/parsing -> pars.
+ * Deliberately small; it only has to be consistent between index and query.
+ */
+export function stem(word: string): string {
+  if (word.length <= 3) {
+    return word;
+  }
+
+  let result = word;
+  if (/ies$|ied$/.test(result)) {
+    result = result.slice(0, -3) + 'y';
+  } else if (result.endsWith('sses')) {
+    result = result.slice(0, -2);
+  } else if (/[^su]s$/.test(result) && !result.endsWith('ss') && !result.endsWith('is')) {
+    result = result.slice(0, -1);
+  }
+
+  for (const suffix of ['ational', 'ation', 'ing', 'ment', 'ion', 'ed', 'er', 'or', 'ly']) {
+    const base = result.slice(0, -suffix.length);
+    if (result.endsWith(suffix) && base.length >= (suffix === 'ion' || suffix === 'or' ? 4 : 3)) {
+      if (suffix === 'ion' && !/[st]$/.test(base)) {
+        break;
+      }
+      result = result.slice(0, -suffix.length);
+      // running -> run, but keep fill/pass/buzz intact
+      if (/([^aeioulsz])\1$/.test(result)) {
+        result = result.slice(0, -1);
+      }
+      break;
+    }
+  }
+
+  if (result.length > 4 && result.endsWith('e')) {
+    result = result.slice(0, -1);
+  }
+  if (result.endsWith('at') && result.length > 5) {
+    // validate/validation/validator all end up at "valid"
+    result = result.slice(0, -2);
+  }
+  return result;
+}
+
+/** Stemmed content terms of free text or code, with identifiers split into subwords. */
+export function semanticTerms(text: string | null | undefined): string[] {
+  if (!text) {
+    return [];
+  }
+
+  const terms: string[] = [];
+  for (const word of text.match(/[A-Za-z][A-Za-z0-9_$]*/g) || []) {
+    for (const part of splitIdentifier(word)) {
+      if (part.length < 2 || /^[0-9]+$/.test(part) || STOP_WORDS.has(part)) {
+        continue;
+      }
+      terms.push(stem(ABBREVIATIONS[part] || part));
+    }
+  }
+  return terms;
+}
\ No newline at end of file
@@ -0,0 +1,184 @@
+import { PrimordynDB } from '../database/index.js';
+import { fuzzyDistance } from '../query/match.js';
+import { querySubwords, ftsTerm } from './identifiers.js';
+import type { SymbolQueryRow } from '../types/index.js';
+
+export interface SymbolSearchOptions {
+  fileTypes?: string[];
+  limit?: number;
+}
+
+interface Candidate {
+  row: SymbolQueryRow;
+  stage: number;
+  score: number;
+}
+
+// Stages in the order they're tried; later stages only fill remaining slots
+const STAGE_SUBWORD = 0;
+const STAGE_SUBSTRING = 1;
+const STAGE_FUZZY = 2;
+
+/**
+ * Identifier-aware symbol search. Names, signatures and docs are indexed as
+ * subwords (camelCase, snake_case, kebab-case and digits split apart), with a
+ * trigram index for substrings and a typo-tolerant pass over the subword
+ * vocabulary. Exact name matches always rank first.
+ */
+export class SymbolSearch {
+  private db: PrimordynDB;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+  }
+
+  public search(query: string, options: SymbolSearchOptions = {}): SymbolQueryRow[] {
+    const limit = options.limit || 20;
+    const subwords = querySubwords(query);
+    if (subwords.length === 0) {
+      return [];
+    }
+
+    const candidates = new Map<number, Candidate>();
+    const collect = (stage: number, rows: (SymbolQueryRow & { score: number })[]) => {
+      for (const { score, ...row } of rows) {
+        if (!candidates.has(row.id)) {
+          candidates.set(row.id, { row, stage, score });
+        }
+      }
+    };
+
+    collect(STAGE_SUBWORD, this.matchSubwords(subwords.map(word => ftsTerm(word, true)).join(' AND '), options, limit));
+
+    // Qualified names like `UserService.getUser` also match on their last segment
+    const segments = query.split(/[.:#/\\]+/).filter(Boolean);
+    const lastSubwords = segments.length > 1 ? querySubwords(segments[segments.length - 1]) : [];
+    if (candidates.size < limit && lastSubwords.length > 0) {
+      collect(STAGE_SUBWORD, this.matchSubwords(lastSubwords.map(word => ftsTerm(word, true)).join(' AND '), options, limit));
+    }
+
+    // Substrings that don't start at a subword boundary, e.g. "serBy" in getUserById
+    const substring = query.replace(/[^A-Za-z0-9_$]/g, '');
+    if (candidates.size < limit && substring.length >= 3) {
+      collect(STAGE_SUBSTRING, this.matchSubstring(substring, options, limit));
+    }
+
+    if (candidates.size < limit) {
+      const fuzzy = this.buildFuzzyQuery(subwords);
+      if (fuzzy) {
+        collect(STAGE_FUZZY, this.matchSubwords(fuzzy, options, limit));
+      }
+    }
+
+    return [...candidates.values()]
+      .sort((a, b) =>
+        nameRank(a.row.name, query) - nameRank(b.row.name, query) ||
+        a.stage - b.stage ||
+        a.score - b.score ||
+        a.row.name.length - b.row.name.length
+      )
+      .slice(0, limit)
+      .map(candidate => candidate.row);
+  }
+
+  private matchSubwords(match: string, options: SymbolSearchOptions, limit: number): (SymbolQueryRow & { score: number })[] {
+    const params: unknown[] = [match];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+
+    // Names weigh far more than signatures, and signatures more than docs
+    return this.db.getDatabase().prepare(`
+      SELECT
+        s.id,
+        s.name,
+        s.type,
+        s.line_start as lineStart,
+        s.line_end as lineEnd,
+        s.signature,
+        s.documentation,
+        s.metadata,
+        f.relative_path as filePath,
+        bm25(symbol_subwords, 10.0, 2.0, 1.0) as score
+      FROM symbol_subwords sw
+      JOIN symbols s ON sw.rowid = s.id
+      JOIN files f ON s.file_id = f.id
+      WHERE symbol_subwords MATCH ?
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY score
+      LIMIT ${limit * 3}
+    `).all(...params) as (SymbolQueryRow & { score: number })[];
+  }
+
+  private matchSubstring(substring: string, options: SymbolSearchOptions, limit: number): (SymbolQueryRow & { score: number })[] {
+    const params: unknown[] = [ftsTerm(substring)];
+    if (options.fileTypes?.length) {
+      params.push(...options.fileTypes);
+    }
+
+    return this.db.getDatabase().prepare(`
+      SELECT
+        s.id,
+        s.name,
+        s.type,
+        s.line_start as lineStart,
+        s.line_end as lineEnd,
+        s.signature,
+        s.documentation,
+        s.metadata,
+        f.relative_path as filePath,
+        LENGTH(s.name) as score
+      FROM symbol_trigrams tg
+      JOIN symbols s ON tg.rowid = s.id
+      JOIN files f ON s.file_id = f.id
+      WHERE symbol_trigrams MATCH ?
+      ${options.fileTypes?.length ? `AND f.language IN (${options.fileTypes.map(() => '?').join(',')})` : ''}
+      ORDER BY score
+      LIMIT ${limit * 3}
+    `).all(...params) as (SymbolQueryRow & { score: number })[];
+  }
+
+  /**
+   * Replace each query subword with the indexed subwords within a small edit
+   * distance, e.g. "usr" -> ("usr" OR "user"). Null when nothing is close.
+   */
+  private buildFuzzyQuery(subwords: string[]): string | null {
+    const findSimilar = this.db.getDatabase().prepare(`
+      SELECT term FROM symbol_subwords_vocab
+      WHERE term != ? AND length(term) BETWEEN ? AND ? AND levenshtein(term, ?) <= ?
+      ORDER BY levenshtein(term, ?), doc DESC
+      LIMIT 5
+    `);
+
+    let expanded = false;
+    const groups = subwords.map(word => {
+      const distance = fuzzyDistance(word);
+      if (distance === 0) {
+        return ftsTerm(word, true);
+      }
+      const similar = (findSimilar.all(word, word.length - distance, word.length + distance, word, distance, word) as { term: string }[])
+        .map(row => row.term);
+      if (similar.length === 0) {
+        return ftsTerm(word, true);
+      }
+      expanded = true;
+      return `(${[ftsTerm(word, true), ...similar.map(term => ftsTerm(term))].join(' OR ')})`;
+    });
+
+    return expanded ? groups.join(' AND ') : null;
+  }
+}
+
+// 0: exact, 1: exact ignoring case, 2: prefix, 3: anything else
+function nameRank(name: string, query: string): number {
+  const term = query.trim();
+  const last = term.split(/[.:#/\\]+/).filter(Boolean).pop() || term;
+  if (name === term || name === last) {
+    return 0;
+  }
+  const lower = name.toLowerCase();
+  if (lower === term.toLowerCase() || lower === last.toLowerCase()) {
+    return 1;
+  }
+  return lower.startsWith(last.toLowerCase()) ? 2 : 3;
+}
\ No newline at end of file
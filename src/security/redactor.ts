@@ -0,0 +1,210 @@
+import type { RedactionConfig } from '../config/index.js';
+
+export interface SecretFinding {
+  rule: string;
+  line: number;
+  column: number;
+  length: number;
+  preview: string;
+}
+
+interface SecretRule {
+  name: string;
+  pattern: RegExp;
+  // Capture group holding the secret itself; 0 redacts the whole match
+  group: number;
+  validate?: (value: string) => boolean;
+}
+
+const REDACTED_PREFIX = '[REDACTED:';
+
+// Lower bound (bits per character) for flagging quoted literals as random-looking
+const ENTROPY_THRESHOLD = 4.2;
+const ENTROPY_MIN_LENGTH = 20;
+
+const BUILTIN_RULES: SecretRule[] = [
+  { name: 'private-key', pattern: /-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY( BLOCK)?-----/g, group: 0 },
+  { name: 'aws-access-key', pattern: /\b(?:AKIA|ASIA)[0-9A-Z]{16}\b/g, group: 0 },
+  { name: 'github-token', pattern: /\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b/g, group: 0 },
+  { name: 'slack-token', pattern: /\bxox[abposr]-[A-Za-z0-9-]{10,}\b/g, group: 0 },
+  { name: 'stripe-key', pattern: /\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b/g, group: 0 },
+  { name: 'google-api-key', pattern: /\bAIza[0-9A-Za-z_-]{35}\b/g, group: 0 },
+  { name: 'api-key', pattern: /\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}\b/g, group: 0 },
+  { name: 'jwt', pattern: /\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}/g, group: 0 },
+  { name: 'connection-string', pattern: /\b[a-z][a-z0-9+.-]*:\/\/[^\s:@/'"]+:([^\s@/'"]+)@/gi, group: 1 },
+  // `.env` style: KEY=value, unquoted values allowed
+  {
+    name: 'env-secret',
+    pattern: /^[ \t]*(?:export[ \t]+)?[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_KEY|PRIVATE_KEY|CREDENTIALS?)[A-Z0-9_]*[ \t]*=[ \t]*["']?([^\s"'#]{6,})/gm,
+    group: 1,
+    validate: value => !isPlaceholder(value)
+  },
+  // Source style: password: "...", apiKey = '...'
+  {
+    name: 'generic-secret',
+    pattern: /[A-Za-z0-9_.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret)[A-Za-z0-9_.-]*["']?\s*[:=]\s*(["'`])([^"'`\s]{8,})\1/gi,
+    group: 2,
+    validate: value => !isPlaceholder(value)
+  },
+  { name: 'email', pattern: /\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b/g, group: 0 }
+];
+
+const ENTROPY_RULE: SecretRule = {
+  name: 'high-entropy-string',
+  pattern: new RegExp(`(["'\`])([A-Za-z0-9+/=_-]{${ENTROPY_MIN_LENGTH},})\\1`, 'g'),
+  group: 2,
+  validate: value => /[0-9]/.test(value) && /[a-z]/.test(value) && /[A-Z]/.test(value) &&
+    !/^sha(1|256|384|512)-/.test(value) && shannonEntropy(value) >= ENTROPY_THRESHOLD
+};
+
+function isPlaceholder(value: string): boolean {
+  return value.startsWith(REDACTED_PREFIX) ||
+    /^(\$\{?|<|%|\{\{)/.test(value) ||
+    /^(process\.env|os\.environ|env\.|ENV\[|System\.getenv)/.test(value) ||
+    /^(x+|\*+|changeme|placeholder|example|your[_-].*|dummy|test|password|secret|token|null|none|undefined|true|false)$/i.test(value);
+}
+
+export function shannonEntropy(value: string): number {
+  const counts = new Map<string, number>();
+  for (const char of value) {
+    counts.set(char, (counts.get(char) || 0) + 1);
+  }
+  let entropy = 0;
+  for (const count of counts.values()) {
+    const p = count / value.length;
+    entropy -= p * Math.log2(p);
+  }
+  return entropy;
+}
+
+interface Match {
+  rule: string;
+  start: number;
+  end: number;
+}
+
+/**
+ * Detects secrets and PII in text and replaces them with `[REDACTED:<rule>]`
+ * markers. Newlines inside a redacted span are kept so line numbers in
+ * snippets stay accurate.
+ */
+export class SecretRedactor {
+  private rules: SecretRule[];
+  private allowlist: RegExp[];
+  private enabled: boolean;
+
+  constructor(config: RedactionConfig) {
+    this.enabled = config.enabled;
+    this.rules = [
+      ...config.patterns.map(p => ({ name: p.name, pattern: new RegExp(p.pattern, 'g'), group: 0 })),
+      ...BUILTIN_RULES,
+      ...(config.entropy ? [ENTROPY_RULE] : [])
+    ];
+    this.allowlist = config.allowlist.map(entry => new RegExp(entry));
+  }
+
+  public isEnabled(): boolean {
+    return this.enabled;
+  }
+
+  public scan(text: string): SecretFinding[] {
+    const lineStarts = [0];
+    for (let i = 0; i < text.length; i++) {
+      if (text[i] === '\n') {
+        lineStarts.push(i + 1);
+      }
+    }
+
+    return this.findMatches(text).map(match => {
+      let line = 0;
+      while (line + 1 < lineStarts.length && lineStarts[line + 1] <= match.start) {
+        line++;
+      }
+      return {
+        rule: match.rule,
+        line: line + 1,
+        column: match.start - lineStarts[line] + 1,
+        length: match.end - match.start,
+        preview: mask(text.slice(match.start, match.end))
+      };
+    });
+  }
+
+  public redact(text: string): string {
+    if (!this.enabled || !text) {
+      return text;
+    }
+
+    let result = text;
+    // Replace back to front so earlier offsets stay valid
+    for (const match of this.findMatches(text).reverse()) {
+      const newlines = (text.slice(match.start, match.end).match(/\n/g) || []).length;
+      result = result.slice(0, match.start) + `${REDACTED_PREFIX}${match.rule}]` + '\n'.repeat(newlines) + result.slice(match.end);
+    }
+    return result;
+  }
+
+  /**
+   * Redact every string inside a result object, e.g. before it is printed in
+   * any output format.
+   */
+  public redactDeep<T>(value: T): T {
+    if (!this.enabled) {
+      return value;
+    }
+    if (typeof value === 'string') {
+      return this.redact(value) as T;
+    }
+    if (Array.isArray(value)) {
+      return value.map(item => this.redactDeep(item)) as T;
+    }
+    if (value && typeof value === 'object' && !(value instanceof Date)) {
+      const result: Record<string, unknown> = {};
+      for (const [key, item] of Object.entries(value)) {
+        result[key] = this.redactDeep(item);
+      }
+      return result as T;
+    }
+    return value;
+  }
+
+  private findMatches(text: string): Match[] {
+    const matches: Match[] = [];
+
+    for (const rule of this.rules) {
+      const pattern = new RegExp(rule.pattern.source, rule.pattern.flags.includes('d') ? rule.pattern.flags : rule.pattern.flags + 'd');
+      for (const match of text.matchAll(pattern)) {
+        const span = match.indices?.[rule.group];
+        const value = match[rule.group];
+        if (!span || !value || value.startsWith(REDACTED_PREFIX)) {
+          continue;
+        }
+        if (rule.validate && !rule.validate(value)) {
+          continue;
+        }
+        if (this.allowlist.some(allowed => allowed.test(value))) {
+          continue;
+        }
+        matches.push({ rule: rule.name, start: span[0], end: span[1] });
+      }
+    }
+
+    // Keep the leftmost, longest match where spans overlap
+    matches.sort((a, b) => a.start - b.start || (b.end - b.start) - (a.end - a.start));
+    const result: Match[] = [];
+    for (const match of matches) {
+      const previous = result[result.length - 1];
+      if (previous && match.start < previous.end) {
+        continue;
+      }
+      result.push(match);
+    }
+    return result;
+  }
+}
+
+function mask(value: string): string {
+  const firstLine = value.split('\n')[0];
+  const visible = Math.min(4, Math.floor(firstLine.length / 4));
+  return firstLine.slice(0, visible) + '*'.repeat(Math.min(12, Math.max(4, firstLine.length - visible)));
+}
\ No newline at end of file
@@ -0,0 +1,392 @@
+import { pathToFileURL } from 'url';
+import { PrimordynDB } from '../database/index.js';
+import { loadConfig } from '../config/index.js';
+import { SecretRedactor } from '../security/redactor.js';
+import { SYMBOL_ROLES } from '../scip/decode.js';
+import { CONTAINER_TYPES } from '../utils/symbols.js';
+import { VERSION } from '../version.js';
+import type { ScipDocument, ScipIndex, ScipOccurrence, ScipSymbolInformation, Symbol } from '../types/index.js';
+
+// Language names as Universal Ctags and SCIP write them
+const LANGUAGE_NAMES: Record<string, string> = {
+  typescript: 'TypeScript', javascript: 'JavaScript', python: 'Python', go: 'Go', rust: 'Rust', java: 'Java',
+  kotlin: 'Kotlin', scala: 'Scala', c: 'C', cpp: 'C++', csharp: 'C#', ruby: 'Ruby', php: 'PHP', swift: 'Swift',
+  shell: 'Sh', sql: 'SQL', lua: 'Lua'
+};
+
+// Symbol types to SCIP SymbolInformation.Kind
+const SCIP_KINDS: Partial<Record<Symbol['type'], number>> = {
+  class: 7, constant: 8, enum: 11, function: 17, interface: 21, method: 26, module: 29, namespace: 30,
+  property: 41, struct: 49, trait: 53, type: 54, variable: 61
+};
+
+// Symbols that name a type
+const TYPES = new Set<Symbol['type']>(['class', 'interface', 'struct', 'trait', 'enum', 'type']);
+
+// Universal Ctags' default --pattern-length-limit
+const PATTERN_LENGTH_LIMIT = 96;
+
+interface TagSymbol {
+  id: number;
+  fileId: number;
+  name: string;
+  type: Symbol['type'];
+  lineStart: number;
+  lineEnd: number;
+  signature: string | null;
+  documentation: string | null;
+  filePath: string;
+  language: string | null;
+}
+
+// A symbol with its tag name, the container it belongs to and its kind there
+interface ScopedSymbol extends TagSymbol {
+  tagName: string;
+  scope: { name: string; type: Symbol['type'] } | null;
+  kind: Symbol['type'];
+}
+
+/**
+ * Exports the index for editors and code navigation tools: a Universal
+ * Ctags `tags` file, or a SCIP index with definitions, references and
+ * supertype relationships. Both are read from the same symbols, hierarchy
+ * and occurrence tables the queries use.
+ */
+export class TagExporter {
+  private db: PrimordynDB;
+  private symbols: ScopedSymbol[] | null = null;
+  private redactor: SecretRedactor;
+
+  constructor(db: PrimordynDB) {
+    this.db = db;
+    this.redactor = new SecretRedactor(loadConfig(db.getProjectRoot()).redaction);
+  }
+
+  /** A sorted tags file in Universal Ctags' extended format. */
+  public toCtags(): { content: string; count: number } {
+    const symbols = this.scopedSymbols();
+    const supertypes = this.supertypes();
+    const files = this.fileLines(symbols);
+    // Whole files are redacted so a secret spanning lines marks each of them
+    const redacted = new Map([...files].map(([fileId, lines]) => [fileId, this.redactor.redact(lines.join('\n')).split('\n')]));
+
+    const tags = symbols
+      .slice()
+      .sort((a, b) => compare(a.tagName, b.tagName) || compare(a.filePath, b.filePath) || a.lineStart - b.lineStart)
+      .map(symbol => {
+        const fields = [symbol.kind, `line:${symbol.lineStart}`];
+        if (symbol.language) {
+          fields.push(`language:${languageName(symbol.language)}`);
+        }
+        if (symbol.scope) {
+          fields.push(`${symbol.scope.type}:${symbol.scope.name}`);
+        }
+        const inherits = supertypes.get(symbol.id);
+        if (inherits) {
+          fields.push(`inherits:${inherits.join(',')}`);
+        }
+        const parameters = parameterList(symbol);
+        if (parameters) {
+          fields.push(`signature:${parameters}`);
+        }
+        fields.push(`end:${symbol.lineEnd}`);
+        // A line holding a secret is addressed by its number rather than quoted
+        const line = files.get(symbol.fileId)?.[symbol.lineStart - 1];
+        const quotable = line !== undefined && redacted.get(symbol.fileId)![symbol.lineStart - 1] === line;
+        const address = (quotable ? searchPattern(line) : null) ?? String(symbol.lineStart);
+        return `${symbol.tagName}\t${symbol.filePath}\t${address};"\t${fields.join('\t')}`;
+      });
+
+    const header = [
+      '!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;" to lines/',
+      '!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/',
+      '!_TAG_PROGRAM_NAME\tprimordyn\t//',
+      `!_TAG_PROGRAM_VERSION\t${VERSION}\t//`
+    ];
+    return { content: [...header, ...tags].join('\n') + '\n', count: tags.length };
+  }
+
+  /**
+   * A SCIP index: one document per file with symbols, occurrences that
+   * resolve to a known definition, and supertypes as relationships.
+   */
+  public toScip(): ScipIndex {
+    const database = this.db.getDatabase();
+    const symbols = this.scopedSymbols();
+    const files = this.fileLines(symbols);
+
+    const symbolStrings = new Map<number, string>();
+    const used = new Set<string>();
+    for (const symbol of symbols) {
+      let candidate = scipSymbol(symbol, 0);
+      for (let disambiguator = 1; used.has(candidate) && symbol.kind === 'method'; disambiguator++) {
+        candidate = scipSymbol(symbol, disambiguator);
+      }
+      used.add(candidate);
+      symbolStrings.set(symbol.id, candidate);
+    }
+
+    const byName = new Map<string, ScopedSymbol[]>();
+    for (const symbol of symbols) {
+      byName.set(symbol.tagName, [...(byName.get(symbol.tagName) ?? []), symbol]);
+    }
+    const relations = database.prepare(`
+      SELECT symbol_id as symbolId, related_name as relatedName FROM type_relations WHERE symbol_id IS NOT NULL
+    `).all() as { symbolId: number; relatedName: string }[];
+    const relationships = new Map<number, string[]>();
+    for (const relation of relations) {
+      // Only supertypes that name exactly one indexed type
+      const targets = (byName.get(relation.relatedName) ?? []).filter(symbol => TYPES.has(symbol.type));
+      if (targets.length === 1) {
+        relationships.set(relation.symbolId, [...(relationships.get(relation.symbolId) ?? []), symbolStrings.get(targets[0].id)!]);
+      }
+    }
+
+    const occurrences = database.prepare(`
+      SELECT name, role, line_number as line, column_number as column FROM occurrences WHERE file_id = ? ORDER BY line_number, column_number
+    `);
+    const resolvedCalls = database.prepare(`
+      SELECT callee_name as calleeName, callee_symbol_id as calleeId FROM call_graph
+      WHERE caller_file_id = ? AND line_number = ? AND callee_symbol_id IS NOT NULL AND call_type != 'interface'
+    `);
+
+    const documents: ScipDocument[] = [];
+    for (const [fileId, fileSymbols] of groupBy(symbols, symbol => symbol.fileId)) {
+      const lines = files.get(fileId) ?? [];
+      const document: ScipDocument = {
+        relativePath: fileSymbols[0].filePath,
+        language: fileSymbols[0].language ? languageName(fileSymbols[0].language) : '',
+        occurrences: [],
+        symbols: []
+      };
+      const defined = new Set<number>();
+
+      for (const row of occurrences.all(fileId) as { name: string; role: string; line: number; column: number }[]) {
+        let target: ScopedSymbol | undefined;
+        if (row.role === 'definition') {
+          target = fileSymbols
+            .filter(symbol => symbol.tagName === row.name && symbol.lineStart <= row.line && symbol.lineEnd >= row.line)
+            .sort((a, b) => (a.lineEnd - a.lineStart) - (b.lineEnd - b.lineStart))[0];
+        } else {
+          // The call graph's resolution first, then a name that means one symbol in this file or the project
+          const call = (resolvedCalls.all(fileId, row.line) as { calleeName: string; calleeId: number }[])
+            .find(candidate => candidate.calleeName.split('.').pop() === row.name);
+          const local = fileSymbols.filter(symbol => symbol.tagName === row.name);
+          const global = byName.get(row.name) ?? [];
+          target = symbols.find(symbol => symbol.id === call?.calleeId) ??
+            (local.length === 1 ? local[0] : global.length === 1 ? global[0] : undefined);
+        }
+        if (!target) {
+          continue;
+        }
+
+        const occurrence: ScipOccurrence = {
+          symbol: symbolStrings.get(target.id)!,
+          range: { startLine: row.line - 1, startColumn: row.column, endLine: row.line - 1, endColumn: row.column + row.name.length },
+          roles: SYMBOL_ROLES[row.role as keyof typeof SYMBOL_ROLES] ?? 0
+        };
+        if (row.role === 'definition') {
+          if (defined.has(target.id)) {
+            continue;
+          }
+          defined.add(target.id);
+          occurrence.enclosingRange = enclosingRange(target, lines);
+        }
+        document.occurrences.push(occurrence);
+      }
+
+      for (const symbol of fileSymbols) {
+        // Symbols without a recorded definition occurrence still get one at their name
+        if (!defined.has(symbol.id)) {
+          const column = Math.max(0, lines[symbol.lineStart - 1]?.indexOf(symbol.tagName) ?? 0);
+          document.occurrences.push({
+            symbol: symbolStrings.get(symbol.id)!,
+            range: { startLine: symbol.lineStart - 1, startColumn: column, endLine: symbol.lineStart - 1, endColumn: column + symbol.tagName.length },
+            roles: SYMBOL_ROLES.definition,
+            enclosingRange: enclosingRange(symbol, lines)
+          });
+        }
+        document.symbols.push(this.symbolInformation(symbol, symbolStrings.get(symbol.id)!, relationships.get(symbol.id) ?? []));
+      }
+      document.occurrences.sort((a, b) => a.range.startLine - b.range.startLine || a.range.startColumn - b.range.startColumn);
+      documents.push(document);
+    }
+
+    return {
+      toolName: 'primordyn',
+      toolVersion: VERSION,
+      projectRoot: pathToFileURL(this.db.getProjectRoot()).href,
+      documents,
+      externalSymbols: []
+    };
+  }
+
+  private symbolInformation(symbol: ScopedSymbol, scipSymbol: string, supertypes: string[]): ScipSymbolInformation {
+    const documentation: string[] = [];
+    if (symbol.signature) {
+      documentation.push(`\`\`\`${symbol.language ?? ''}\n${symbol.signature}\n\`\`\``);
+    }
+    if (symbol.documentation) {
+      documentation.push(symbol.documentation);
+    }
+    return {
+      symbol: scipSymbol,
+      documentation,
+      kind: SCIP_KINDS[symbol.kind] ?? 0,
+      displayName: symbol.tagName,
+      relationships: supertypes.map(supertype => ({ symbol: supertype, isImplementation: true }))
+    };
+  }
+
+  // Every symbol with its container: the owner in a dotted name, else the innermost enclosing type
+  private scopedSymbols(): ScopedSymbol[] {
+    if (this.symbols) {
+      return this.symbols;
+    }
+    const rows = this.db.getDatabase().prepare(`
+      SELECT
+        s.id, s.file_id as fileId, s.name, s.type, s.line_start as lineStart, s.line_end as lineEnd,
+        s.signature, s.documentation, f.relative_path as filePath, f.language
+      FROM symbols s
+      JOIN files f ON s.file_id = f.id
+      ORDER BY f.relative_path, s.line_start
+    `).all() as TagSymbol[];
+
+    this.symbols = [];
+    for (const [, fileSymbols] of groupBy(rows, row => row.fileId)) {
+      for (const symbol of fileSymbols) {
+        const dot = symbol.name.lastIndexOf('.');
+        const tagName = dot > 0 ? symbol.name.slice(dot + 1) : symbol.name;
+        let scope: ScopedSymbol['scope'] = null;
+        if (dot > 0) {
+          const ownerName = symbol.name.slice(0, dot);
+          scope = { name: ownerName, type: fileSymbols.find(candidate => candidate.name === ownerName)?.type ?? 'class' };
+        } else {
+          const container = fileSymbols
+            .filter(candidate => candidate !== symbol && CONTAINER_TYPES.has(candidate.type) &&
+              candidate.lineStart <= symbol.lineStart && candidate.lineEnd >= symbol.lineEnd &&
+              (candidate.lineStart < symbol.lineStart || candidate.lineEnd > symbol.lineEnd))
+            .sort((a, b) => (a.lineEnd - a.lineStart) - (b.lineEnd - b.lineStart))[0];
+          scope = container ? { name: container.name, type: container.type } : null;
+        }
+        // Functions inside a type are its methods
+        const kind = symbol.type === 'function' && scope && TYPES.has(scope.type) ? 'method' : symbol.type;
+        this.symbols.push({
+          ...symbol,
+          signature: symbol.signature && this.redactor.redact(symbol.signature),
+          documentation: symbol.documentation && this.redactor.redact(symbol.documentation),
+          tagName,
+          scope,
+          kind
+        });
+      }
+    }
+    return this.symbols;
+  }
+
+  // Declared supertype names per symbol, for the ctags `inherits` field
+  private supertypes(): Map<number, string[]> {
+    const rows = this.db.getDatabase().prepare(`
+      SELECT symbol_id as symbolId, related_name as relatedName FROM type_relations
+      WHERE symbol_id IS NOT NULL AND source = 'declared'
+      ORDER BY id
+    `).all() as { symbolId: number; relatedName: string }[];
+    const supertypes = new Map<number, string[]>();
+    for (const row of rows) {
+      const names = supertypes.get(row.symbolId) ?? [];
+      if (!names.includes(row.relatedName)) {
+        names.push(row.relatedName);
+      }
+      supertypes.set(row.symbolId, names);
+    }
+    return supertypes;
+  }
+
+  private fileLines(symbols: TagSymbol[]): Map<number, string[]> {
+    const select = this.db.getDatabase().prepare('SELECT content FROM files_with_content WHERE id = ?');
+    const lines = new Map<number, string[]>();
+    for (const fileId of new Set(symbols.map(symbol => symbol.fileId))) {
+      const row = select.get(fileId) as { content: string } | undefined;
+      lines.set(fileId, (row?.content ?? '').split('\n'));
+    }
+    return lines;
+  }
+}
+
+function groupBy<T>(items: T[], key: (item: T) => number): Map<number, T[]> {
+  const groups = new Map<number, T[]>();
+  for (const item of items) {
+    groups.set(key(item), [...(groups.get(key(item)) ?? []), item]);
+  }
+  return groups;
+}
+
+// Byte order for ASCII names, which is what sorted tags files use
+function compare(a: string, b: string): number {
+  return a < b ? -1 : a > b ? 1 : 0;
+}
+
+function languageName(language: string): string {
+  return LANGUAGE_NAMES[language] ?? language.charAt(0).toUpperCase() + language.slice(1);
+}
+
+// `/^line$/`, escaped and cut at the pattern length limit like ctags does
+function searchPattern(line: string | undefined): string | null {
+  if (line === undefined) {
+    return null;
+  }
+  const text = line.replace(/\r$/, '');
+  const truncated = text.length > PATTERN_LENGTH_LIMIT;
+  const escaped = (truncated ? text.slice(0, PATTERN_LENGTH_LIMIT) : text).replace(/[\\/]/g, match => `\\${match}`);
+  return `/^${escaped}${truncated ? '' : '$'}/`;
+}
+
+// The parameter list from a function or method signature
+function parameterList(symbol: ScopedSymbol): string | null {
+  if (!symbol.signature || !['function', 'method'].includes(symbol.kind)) {
+    return null;
+  }
+  const signature = symbol.signature.replace(/\s+/g, ' ');
+  const open = signature.indexOf('(', Math.max(0, signature.indexOf(symbol.tagName)));
+  if (open < 0) {
+    return null;
+  }
+  let depth = 0;
+  for (let index = open; index < signature.length; index++) {
+    if (signature[index] === '(') {
+      depth++;
+    } else if (signature[index] === ')' && --depth === 0) {
+      return signature.slice(open, index + 1);
+    }
+  }
+  return null;
+}
+
+// `primordyn . . . src/`user.ts`/UserStore#find().`; the file path is the namespace, so names are unique per file
+function scipSymbol(symbol: ScopedSymbol, disambiguator: number): string {
+  const owner = symbol.scope && TYPES.has(symbol.scope.type) ? symbol.scope.name.split('.').map(part => `${escapeName(part)}#`).join('') : '';
+  const name = escapeName(symbol.tagName);
+  let descriptor: string;
+  switch (symbol.kind) {
+    case 'function':
+    case 'method':
+      descriptor = `${name}(${disambiguator ? `+${disambiguator}` : ''}).`;
+      break;
+    case 'namespace':
+    case 'module':
+      descriptor = `${name}/`;
+      break;
+    default:
+      descriptor = TYPES.has(symbol.kind) ? `${name}#` : `${name}.`;
+  }
+  const namespace = symbol.filePath.split('/').map(escapeName).join('/');
+  return `primordyn . . . ${namespace}/${owner}${descriptor}`;
+}
+
+function escapeName(name: string): string {
+  return /^[\w+$-]+$/.test(name) ? name : `\`${name.replace(/`/g, '``')}\``;
+}
+
+function enclosingRange(symbol: TagSymbol, lines: string[]): ScipOccurrence['range'] {
+  return { startLine: symbol.lineStart - 1, startColumn: 0, endLine: symbol.lineEnd - 1, endColumn: lines[symbol.lineEnd - 1]?.length ?? 0 };
+}
\ No newline at end of file
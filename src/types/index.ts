@@ -26,6 +26,21 @@ export interface Symbol {
   metadata?: Record<string, unknown>;
 }
 
+export interface DocParam {
+  name: string;
+  type?: string;
+  description: string;
+}
+
+// Tags parsed from a symbol's doc comment, stored as metadata.docTags
+export interface DocTags {
+  params?: DocParam[];
+  returns?: string;
+  throws?: string[];
+  // Empty when deprecated without a reason
+  deprecated?: string;
+}
+
 export interface CallReference {
   calleeName: string;
   callType: 'function' | 'method' | 'constructor' | 'import';
@@ -34,6 +49,37 @@ export interface CallReference {
   isExternal?: boolean;
 }
 
+export type TypeRelationKind = 'extends' | 'implements' | 'embeds';
+
+// A supertype edge as declared in source: `typeName` extends/implements/embeds `relatedName`
+export interface TypeRelation {
+  typeName: string;
+  relatedName: string;
+  kind: TypeRelationKind;
+  line: number;
+}
+
+// How a type is named outside a call: in an annotation, as a generic argument,
+// as a parameter or return type, or in a supertype clause
+export type TypeReferenceKind = 'annotation' | 'generic' | 'parameter' | 'return' | TypeRelationKind;
+
+export interface TypeReference {
+  name: string;
+  kind: TypeReferenceKind;
+  line: number;
+}
+
+// What an identifier occurrence does with the name
+export type OccurrenceRole = 'definition' | 'call' | 'type' | 'import' | 'write' | 'read';
+
+export interface Occurrence {
+  name: string;
+  role: OccurrenceRole;
+  line: number;
+  // 0-based, like Babel and tree-sitter positions
+  column: number;
+}
+
 export interface ExtractedContext {
   symbols: Symbol[];
   imports: string[];
@@ -41,14 +87,24 @@ export interface ExtractedContext {
   dependencies: string[];
   comments: string[];
   calls: CallReference[];
+  typeRelations: TypeRelation[];
+  typeReferences: TypeReference[];
+  occurrences: Occurrence[];
   structure: CodeStructure;
 }
 
+export type StorageMode = 'full' | 'compressed' | 'none';
+
 export interface IndexOptions extends Partial<ScanOptions> {
   projectRoot?: string;
   verbose?: boolean;
   languages?: string[];
   updateExisting?: boolean;
+  storage?: StorageMode;
+  // Index just these files (absolute or relative to the project root) instead of scanning
+  paths?: string[];
+  // False skips the embedding step, leaving new code for the next full index
+  embeddings?: boolean;
 }
 
 export interface IndexStats {
@@ -57,6 +113,9 @@ export interface IndexStats {
   totalTokens: number;
   timeElapsed: number;
   errors: number;
+  // Chunks sent to the embedding provider, when one is configured
+  chunksEmbedded?: number;
+  embeddingError?: string;
 }
 
 export interface QueryOptions {
@@ -66,6 +125,18 @@ export interface QueryOptions {
   includeImports?: boolean;
   fileTypes?: string[];
   sortBy?: 'relevance' | 'path' | 'size' | 'modified';
+  // Lines shown around each usage in findUsages snippets
+  contextLines?: number;
+}
+
+// How much of an item made it into the budget, from richest to cheapest
+export type Representation = 'full' | 'excerpt' | 'signature' | 'path';
+
+export interface DegradedItem {
+  kind: 'file' | 'symbol';
+  name: string;
+  from: Representation;
+  to: Representation | 'omitted';
 }
 
 export interface QueryResult {
@@ -73,10 +144,24 @@ export interface QueryResult {
   symbols: SymbolResult[];
   totalTokens: number;
   truncated: boolean;
+  // Items shortened or left out to fit the token budget
+  degraded?: DegradedItem[];
   query_type?: 'search' | 'related';
   source_file?: string;
 }
 
+export interface UsageSnippet {
+  lineStart: number;
+  lineEnd: number;
+  // The usages inside this window
+  usageLines: number[];
+  // Innermost symbol containing the first usage, e.g. "UserService.login"
+  enclosingSymbol?: string;
+  // How a type is named inside this window, e.g. ["parameter", "return"]
+  referenceKinds?: string[];
+  content: string;
+}
+
 export interface FileResult {
   id: number;
   path: string;
@@ -85,6 +170,10 @@ export interface FileResult {
   content?: string;
   preview?: string;
   tokens: number;
+  // Set when the file was cut down to fit the token budget
+  representation?: Representation;
+  // Windows around usages, returned instead of content by findUsages
+  snippets?: UsageSnippet[];
   symbols?: SymbolResult[];
   imports?: string[];
   exports?: string[];
@@ -99,7 +188,11 @@ export interface SymbolResult {
   lineStart: number;
   lineEnd: number;
   signature?: string;
+  documentation?: string;
+  docTags?: DocTags;
   content?: string;
+  // Relevance from semantic search (0-1), when that's how the symbol was found
+  score?: number;
 }
 
 export interface DatabaseInfo {
@@ -109,6 +202,15 @@ export interface DatabaseInfo {
   lastIndexed: Date | null;
 }
 
+export interface CacheStats {
+  entries: number;
+  sizeBytes: number;
+  hits: number;
+  misses: number;
+  hitRate: number;
+  generation: number;
+}
+
 export interface CallGraphNode {
   symbolId?: number;
   fileId: number;
@@ -140,6 +242,8 @@ export interface ImpactAnalysis {
   directReferences: number;
   filesAffected: number;
   symbolsAffected: number;
+  // Type references by how the type is named, e.g. { parameter: 3, implements: 1 }
+  referenceKinds: Record<string, number>;
   
   // Test impact
   testsAffected: number;
@@ -240,6 +344,9 @@ export interface QueryCommandOptions {
   recent?: string;
   blame?: boolean;
   languages?: string;
+  cache?: boolean;
+  semantic?: boolean;
+  contextLines: string;
 }
 
 export interface FindCommandOptions {
@@ -276,6 +383,11 @@ export interface QueryCommandResult {
   recentChanges: RecentFileChanges[] | null;
   totalTokens: number;
   truncated: boolean;
+  degraded?: DegradedItem[];
+  indexStatus?: {
+    rebuilding: boolean;
+    progress: number;
+  };
 }
 
 // Database row types
@@ -402,11 +514,10 @@ export interface SymbolLocationRow {
   line_end?: number;
 }
 
-export interface FileReferenceRow extends Partial<FileRow> {
-  fileId: number;
-  filePath?: string;
-  content: string;
-  language: string | null;
+// Lines where a name occurs in one file
+export interface FileReferences {
+  filePath: string;
+  lines: number[];
 }
 
 // More specific query result types
@@ -465,4 +576,397 @@ export interface SymbolLookupResult {
 
 export interface FilePathResult {
   filePath: string;
+}
+
+export interface BundleManifest {
+  formatVersion: number;
+  toolVersion: string;
+  schemaVersion: number;
+  gitHead: string | null;
+  storageMode: StorageMode;
+  createdAt: string;
+  fileCount: number;
+  symbolCount: number;
+}
+
+export interface BundleImportResult {
+  manifest: BundleManifest;
+  filesImported: number;
+  filesPruned: number;
+}
+
+// SCIP and LSIF index types; LSIF is read into the SCIP shape. Lines and
+// columns are 0-based as in both formats
+export interface ScipRange {
+  startLine: number;
+  startColumn: number;
+  endLine: number;
+  endColumn: number;
+}
+
+export interface ScipOccurrence {
+  symbol: string;
+  range: ScipRange;
+  // Bit set of SymbolRole: 1 definition, 2 import, 4 write, 8 read
+  roles: number;
+  enclosingRange?: ScipRange;
+}
+
+// Supertypes and implemented interfaces
+export interface ScipRelationship {
+  symbol: string;
+  isImplementation: boolean;
+}
+
+export interface ScipSymbolInformation {
+  symbol: string;
+  documentation: string[];
+  // SCIP SymbolInformation.Kind; 0 when the indexer doesn't say
+  kind: number;
+  displayName?: string;
+  signature?: string;
+  relationships?: ScipRelationship[];
+}
+
+export interface ScipDocument {
+  relativePath: string;
+  language: string;
+  occurrences: ScipOccurrence[];
+  symbols: ScipSymbolInformation[];
+}
+
+export interface ScipIndex {
+  toolName: string;
+  toolVersion?: string;
+  // file:// URI
+  projectRoot?: string;
+  documents: ScipDocument[];
+  externalSymbols: ScipSymbolInformation[];
+}
+
+export interface ScipImportResult {
+  toolName: string;
+  documentsImported: number;
+  // Documents whose file isn't in the local index
+  documentsSkipped: number;
+  symbolsUpdated: number;
+  symbolsAdded: number;
+  occurrences: number;
+  callsResolved: number;
+}
+
+export interface ImportScipCommandOptions {
+  prefix?: string;
+  quiet?: boolean;
+}
+
+export interface ExportTagsCommandOptions {
+  format: 'ctags' | 'scip';
+  output?: string;
+}
+
+// Language server types, the LSP subset `primordyn lsp` speaks. Lines and
+// characters are 0-based; characters count UTF-16 code units
+export interface LspMessage {
+  jsonrpc: '2.0';
+  id?: number | string | null;
+  method?: string;
+  params?: unknown;
+  result?: unknown;
+  error?: { code: number; message: string };
+}
+
+export interface LspPosition {
+  line: number;
+  character: number;
+}
+
+export interface LspRange {
+  start: LspPosition;
+  end: LspPosition;
+}
+
+export interface LspLocation {
+  uri: string;
+  range: LspRange;
+}
+
+export interface LspSymbolInformation {
+  name: string;
+  kind: number;
+  location: LspLocation;
+  containerName?: string;
+}
+
+export interface LspDocumentSymbol {
+  name: string;
+  detail?: string;
+  kind: number;
+  range: LspRange;
+  selectionRange: LspRange;
+  children: LspDocumentSymbol[];
+}
+
+export interface LspCallHierarchyItem {
+  name: string;
+  kind: number;
+  detail?: string;
+  uri: string;
+  range: LspRange;
+  selectionRange: LspRange;
+  data: { symbolId: number };
+}
+
+export interface LspHover {
+  contents: { kind: 'markdown'; value: string };
+  range?: LspRange;
+}
+
+// Task-to-context types
+export interface ContextItem {
+  kind: 'symbol' | 'file';
+  id: number;
+  filePath: string;
+  name?: string;
+  type?: string;
+  lineStart?: number;
+  lineEnd?: number;
+  language?: string | null;
+  score: number;
+  relevance: number;
+  centrality: number;
+  recency: number;
+  // Why the item was picked, e.g. "matches `login` from the task", "called by handleRequest"
+  reasons: string[];
+  content?: string;
+  tokens: number;
+  // Set when the item was cut down to fit the budget
+  representation?: Representation;
+}
+
+export interface TaskContext {
+  task: string;
+  identifiers: string[];
+  paths: string[];
+  keywords: string[];
+  items: ContextItem[];
+  totalTokens: number;
+  maxTokens: number;
+  // Candidates that were found but didn't fit the budget
+  omitted: number;
+  degraded: DegradedItem[];
+}
+
+// Repository map types
+export interface DirectorySummary {
+  path: string;
+  depth: number;
+  files: number;
+  size: number;
+  tokens: number;
+  // Most common first
+  languages: string[];
+}
+
+export interface EntryPoint {
+  filePath: string;
+  // e.g. "package.json bin", "defines main() (go)"
+  reason: string;
+}
+
+export interface CentralSymbol {
+  name: string;
+  type: string;
+  filePath: string;
+  line: number;
+  callers: number;
+  callerFiles: number;
+}
+
+export interface ModuleExports {
+  filePath: string;
+  exports: string[];
+}
+
+export interface RepoMap {
+  files: number;
+  symbols: number;
+  languages: { language: string; count: number }[];
+  entryPoints: EntryPoint[];
+  directories: DirectorySummary[];
+  centralSymbols: CentralSymbol[];
+  modules: ModuleExports[];
+  totalTokens: number;
+  maxTokens: number;
+  // Some entries were left out to stay within maxTokens
+  truncated: boolean;
+}
+
+export interface MapCommandOptions {
+  tokens: string;
+  format: 'ai' | 'json' | 'human';
+  depth: string;
+}
+
+// Type hierarchy types
+export interface TypeDefinition {
+  filePath: string;
+  line: number;
+  type: string;
+}
+
+export interface HierarchyNode {
+  name: string;
+  // How this type relates to the one above it in the tree
+  kind: TypeRelationKind;
+  filePath?: string;
+  line?: number;
+  children: HierarchyNode[];
+  // Already expanded elsewhere in the tree (diamonds and cycles)
+  repeated?: boolean;
+}
+
+export interface Implementor {
+  name: string;
+  filePath?: string;
+  line?: number;
+  // Types between the queried one and the implementor, nearest first
+  via: string[];
+  // Satisfies the interface's method set without declaring it (Go)
+  structural?: boolean;
+}
+
+export interface MethodLocation {
+  name: string;
+  filePath: string;
+  line: number;
+}
+
+export interface Implementation extends Implementor {
+  // Where each of the interface's methods is implemented, including inherited and promoted ones
+  methods: MethodLocation[];
+}
+
+export interface ImplementationsReport {
+  interface: string;
+  definitions: TypeDefinition[];
+  // Method names the interface requires, including those of interfaces it extends
+  methods: string[];
+  implementations: Implementation[];
+}
+
+export interface TypeHierarchy {
+  type: string;
+  definitions: TypeDefinition[];
+  supertypes: HierarchyNode[];
+  subtypes: HierarchyNode[];
+  implementors: Implementor[];
+}
+
+export interface HierarchyCommandOptions {
+  format: 'ai' | 'json' | 'human';
+  depth: string;
+}
+
+export interface ImplementationsCommandOptions {
+  format: 'ai' | 'json' | 'human';
+  depth: string;
+}
+
+// Project documentation types
+export interface DetectedCommand {
+  // e.g. "build", "test", "lint"
+  purpose: string;
+  command: string;
+  // Manifest it came from, e.g. "package.json"
+  source: string;
+}
+
+export interface DocsGenerateOptions {
+  output: string;
+  check?: boolean;
+}
+
+export interface ApiReferenceStats {
+  outDir: string;
+  pages: number;
+  written: number;
+  // Pages whose module and linked modules hadn't changed
+  unchanged: number;
+  // Pages of modules no longer in the index
+  removed: number;
+}
+
+export interface DocsApiOptions {
+  out: string;
+  format: string;
+  sourceUrl?: string;
+  languages?: string;
+  json?: boolean;
+}
+
+export interface CoverageBucket {
+  // File path, directory path or language
+  name: string;
+  total: number;
+  documented: number;
+  // Percentage, 100 when there is nothing public to document
+  coverage: number;
+}
+
+export interface UndocumentedSymbol {
+  name: string;
+  type: string;
+  filePath: string;
+  line: number;
+  lines: number;
+}
+
+export interface DocCoverageReport {
+  total: number;
+  documented: number;
+  coverage: number;
+  languages: CoverageBucket[];
+  directories: CoverageBucket[];
+  files: CoverageBucket[];
+  // Largest undocumented public symbols first
+  undocumented: UndocumentedSymbol[];
+}
+
+export interface DocCoverageOptions {
+  format: string;
+  min?: string;
+  languages?: string;
+  limit: string;
+}
+
+// Outline types
+export interface FileOutline {
+  relativePath: string;
+  language: string | null;
+  // The file with function bodies elided
+  skeleton: string;
+  tokens: number;
+  fullTokens: number;
+}
+
+export interface OutlineResult {
+  target: string;
+  files: FileOutline[];
+  totalTokens: number;
+  truncated: boolean;
+  // Files left out once the budget ran out
+  omitted: number;
+}
+
+export interface OutlineCommandOptions {
+  tokens: string;
+  format: 'ai' | 'json' | 'human';
+  languages?: string;
+}
+
+export interface ContextCommandOptions {
+  tokens: string;
+  format: 'ai' | 'json' | 'human';
+  languages?: string;
 }
\ No newline at end of file
@@ -0,0 +1,7 @@
+// Symbol types that hold other symbols: members are scoped to them and nest under them
+export const CONTAINER_TYPES: ReadonlySet<string> = new Set(['class', 'interface', 'struct', 'trait', 'enum', 'namespace', 'module']);
+
+/** The unqualified part of a name such as `UserStore.find` or `store::Cache`. */
+export function lastSegment(name: string): string {
+  return name.split(/\.|::/).pop()!;
+}
\ No newline at end of file
@@ -71,6 +71,22 @@ export function validateDepth(value: string): number {
   return num;
 }
 
+export function validateContextLines(value: string): number {
+  const num = parseInt(value, 10);
+  if (isNaN(num) || num < 0 || num > 50) {
+    throw new ValidationError(`Invalid value for --context-lines: "${value}". Must be between 0 and 50.`);
+  }
+  return num;
+}
+
+export function validatePercentage(value: string, optionName: string): number {
+  const num = Number(value);
+  if (value.trim() === '' || isNaN(num) || num < 0 || num > 100) {
+    throw new ValidationError(`Invalid value for ${optionName}: "${value}". Must be a percentage between 0 and 100.`);
+  }
+  return num;
+}
+
 export function validatePath(path: string): string {
   // Basic path validation - just ensure it's not empty
   if (!path || path.trim().length === 0) {
@@ -0,0 +1,2 @@
+// Keep in sync with package.json; recorded in exported index bundles
+export const VERSION = '0.1.0';
\ No newline at end of file